@@ -1,6 +1,7 @@
 package db_conn
 
 import (
+	"fmt"
 	"gorm.io/gorm"
 	"log"
 	"mini-crypto-wallet-api/internal/config"
@@ -27,15 +28,121 @@ func InitDatabase() {
 }
 
 func autoMigrate() {
+	if err := dedupeWallets(Conn_DB.MasterDB); err != nil {
+		log.Fatal("❌ Failed to migrate database:", err)
+	}
+	if err := dropNonUniqueBalanceHistoryIndex(Conn_DB.MasterDB); err != nil {
+		log.Fatal("❌ Failed to migrate database:", err)
+	}
+
 	err := Conn_DB.MasterDB.AutoMigrate(
 		&models.User{},
 		&models.Currency{},
 		&models.Wallet{},
 		&models.Transaction{},
 		&models.BalanceHistory{},
+		&models.OutboxEvent{},
+		&models.IdempotencyKey{},
+		&models.LedgerAccount{},
+		&models.JournalEntry{},
+		&models.LedgerPosting{},
+		&models.ReconciliationRun{},
+		&models.ReconciliationFinding{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.AdminAuditLog{},
+		&models.SwapQuote{},
+		&models.FeeSchedule{},
+		&models.FeeTier{},
+		&models.TransferLimit{},
+		&models.ScheduledTransfer{},
+		&models.ScheduledTransferRun{},
+		&models.AuditCheckpoint{},
+		&models.AuditCheckpointLeaf{},
+		&models.Notification{},
+		&models.WebhookEndpoint{},
+		&models.WebhookDelivery{},
 	)
 	if err != nil {
 		log.Fatal("❌ Failed to migrate database:", err)
 	}
 	log.Println("✅ Database migrated")
 }
+
+// dedupeWallets 在 AutoMigrate 建立 idx_wallet_user_currency 之前清理重複的 (user_id, currency_id) 錢包
+// 保留最早開立的錢包，刪除沒有餘額、沒有異動紀錄也沒有帳本科目的重複錢包；
+// 仍有資金或紀錄的重複錢包需人工合併帳務，這時停止啟動並列出，而不是讓建立索引失敗或自動搬動資金
+func dedupeWallets(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.Wallet{}) || migrator.HasIndex(&models.Wallet{}, "idx_wallet_user_currency") {
+		return nil
+	}
+
+	type duplicate struct {
+		UserID     uint
+		CurrencyID uint
+		KeepID     uint
+	}
+	var duplicates []duplicate
+	if err := db.Model(&models.Wallet{}).
+		Select("user_id, currency_id, MIN(id) AS keep_id").
+		Group("user_id, currency_id").
+		Having("COUNT(*) > 1").
+		Scan(&duplicates).Error; err != nil {
+		return err
+	}
+
+	for _, d := range duplicates {
+		query := db.Where("user_id = ? AND currency_id = ? AND id <> ?", d.UserID, d.CurrencyID, d.KeepID).
+			Where("balance = 0")
+		if migrator.HasColumn(&models.Wallet{}, "held_balance") {
+			query = query.Where("held_balance = 0")
+		}
+		if migrator.HasTable(&models.BalanceHistory{}) {
+			query = query.Where("NOT EXISTS (SELECT 1 FROM balance_histories WHERE balance_histories.wallet_id = wallets.id)")
+		}
+		if migrator.HasTable(&models.LedgerAccount{}) {
+			query = query.Where("NOT EXISTS (SELECT 1 FROM ledger_accounts WHERE ledger_accounts.wallet_id = wallets.id)")
+		}
+		result := query.Delete(&models.Wallet{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("🧹 Removed %d unused duplicate wallet(s) of user %d, currency %d", result.RowsAffected, d.UserID, d.CurrencyID)
+		}
+
+		var remaining int64
+		if err := db.Model(&models.Wallet{}).Where("user_id = ? AND currency_id = ?", d.UserID, d.CurrencyID).Count(&remaining).Error; err != nil {
+			return err
+		}
+		if remaining > 1 {
+			return fmt.Errorf("user %d has %d wallets in currency %d with funds or history; merge them before starting", d.UserID, remaining, d.CurrencyID)
+		}
+	}
+	return nil
+}
+
+// dropNonUniqueBalanceHistoryIndex 移除舊版非唯一的 idx_balance_history_wallet_seq，讓 AutoMigrate 以唯一索引重建
+// AutoMigrate 只看索引名稱，不會把既有的同名索引改成唯一
+func dropNonUniqueBalanceHistoryIndex(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.BalanceHistory{}) {
+		return nil
+	}
+
+	indexes, err := migrator.GetIndexes(&models.BalanceHistory{})
+	if err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		if index.Name() != "idx_balance_history_wallet_seq" {
+			continue
+		}
+		if unique, ok := index.Unique(); ok && unique {
+			return nil
+		}
+		return migrator.DropIndex(&models.BalanceHistory{}, "idx_balance_history_wallet_seq")
+	}
+	return nil
+}
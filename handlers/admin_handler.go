@@ -0,0 +1,829 @@
+package handlers
+
+import (
+	"errors"
+	apperrors "mini-crypto-wallet-api/internal/errors"
+	"mini-crypto-wallet-api/middleware"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler 客服與管理員使用的 /admin API
+type AdminHandler struct {
+	adminService    *services.AdminService
+	currencyService *services.CurrencyService
+	fundingService  *services.FundingService
+	auditService    *services.AuditService
+	feeService      *services.FeeService
+	limitService    *services.LimitService
+}
+
+func NewAdminHandler(adminService *services.AdminService, currencyService *services.CurrencyService, fundingService *services.FundingService, auditService *services.AuditService, feeService *services.FeeService, limitService *services.LimitService) *AdminHandler {
+	return &AdminHandler{
+		adminService:    adminService,
+		currencyService: currencyService,
+		fundingService:  fundingService,
+		auditService:    auditService,
+		feeService:      feeService,
+		limitService:    limitService,
+	}
+}
+
+// GetUser 查詢任一用戶
+//
+// @Summary Get user (staff)
+// @Description Look up any user including role and freeze state
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.UserResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/users/{id} [get]
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.GetUser(userID)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToUserResponse(user))
+}
+
+// GetUserWallets 查詢任一用戶的所有錢包
+//
+// @Summary Get user wallets (staff)
+// @Description List every wallet of any user
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} models.WalletWithCurrencyResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/users/{id}/wallets [get]
+func (h *AdminHandler) GetUserWallets(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	wallets, err := h.adminService.GetUserWallets(userID)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToWalletWithCurrencyResponses(wallets))
+}
+
+// GetUserTransactions 分頁查詢任一用戶的交易紀錄
+//
+// @Summary Get user transactions (staff)
+// @Description List the transactions of any user with pagination
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/users/{id}/transactions [get]
+func (h *AdminHandler) GetUserTransactions(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	pagination := bindPagination(c)
+	txs, total, err := h.adminService.GetUserTransactions(userID, pagination.GetOffset(), pagination.GetLimit())
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       models.ToTransactionResponses(txs),
+		"pagination": newPaginationResponse(pagination, total),
+	})
+}
+
+// FreezeUser 凍結帳戶
+//
+// @Summary Freeze account (staff)
+// @Description Freeze an account: the user is logged out, cannot log in and cannot transfer or withdraw
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param freeze body models.FreezeUserRequest true "Freeze reason"
+// @Success 200 {object} models.UserResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/users/{id}/freeze [post]
+func (h *AdminHandler) FreezeUser(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+	actorID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.FreezeUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	middleware.AddAuditDetail(c, "reason", req.Reason)
+
+	user, err := h.adminService.FreezeUser(actorID, userID, req.Reason)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToUserResponse(user))
+}
+
+// UnfreezeUser 解除帳戶凍結
+//
+// @Summary Unfreeze account (staff)
+// @Description Lift the freeze on an account
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.UserResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/users/{id}/unfreeze [post]
+func (h *AdminHandler) UnfreezeUser(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.UnfreezeUser(userID)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToUserResponse(user))
+}
+
+// UpdateUserRole 變更用戶角色
+//
+// @Summary Change user role (admin)
+// @Description Set a user's role to user, support or admin. The user's sessions are revoked so the new role applies immediately
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param role body models.UpdateRoleRequest true "New role"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /admin/users/{id}/role [put]
+func (h *AdminHandler) UpdateUserRole(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+	actorID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidRole})
+		return
+	}
+	middleware.AddAuditDetail(c, "role", req.Role)
+
+	user, err := h.adminService.UpdateRole(actorID, userID, req.Role)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToUserResponse(user))
+}
+
+// ListCurrencies 列出所有幣種（包含已停用的幣種）
+//
+// @Summary List currencies (admin)
+// @Description List every currency including inactive ones
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.CurrencyResponse
+// @Failure 403 {object} map[string]string
+// @Router /admin/currencies [get]
+func (h *AdminHandler) ListCurrencies(c *gin.Context) {
+	currencies, err := h.currencyService.ListCurrencies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch currencies"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToCurrencyResponses(currencies))
+}
+
+// CreateCurrency 新增幣種
+//
+// @Summary Create currency (admin)
+// @Description Create a currency; the code is upper-cased and must be unique
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.CreateCurrencyRequest true "Currency"
+// @Success 201 {object} models.CurrencyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/currencies [post]
+func (h *AdminHandler) CreateCurrency(c *gin.Context) {
+	var req models.CreateCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidRequest})
+		return
+	}
+	middleware.AddAuditDetail(c, "code", req.Code)
+
+	currency, err := h.currencyService.CreateCurrency(req)
+	if err != nil {
+		respondCurrencyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.ToCurrencyResponse(currency))
+}
+
+// UpdateCurrency 修改幣種
+//
+// @Summary Update currency (admin)
+// @Description Edit name, symbol, decimals or active state. Lowering decimals is refused while any wallet balance has more decimal places.
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Currency ID"
+// @Param request body models.UpdateCurrencyRequest true "Fields to change"
+// @Success 200 {object} models.CurrencyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/currencies/{id} [patch]
+func (h *AdminHandler) UpdateCurrency(c *gin.Context) {
+	id, ok := parseCurrencyIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidRequest})
+		return
+	}
+	if req.Decimals != nil {
+		middleware.AddAuditDetail(c, "decimals", strconv.Itoa(*req.Decimals))
+	}
+	if req.IsActive != nil {
+		middleware.AddAuditDetail(c, "is_active", strconv.FormatBool(*req.IsActive))
+	}
+
+	currency, err := h.currencyService.UpdateCurrency(id, req)
+	if err != nil {
+		respondCurrencyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToCurrencyResponse(currency))
+}
+
+// ActivateCurrency 重新啟用幣種
+//
+// @Summary Activate currency (admin)
+// @Description Re-enable a deactivated currency
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Currency ID"
+// @Success 200 {object} models.CurrencyResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/currencies/{id}/activate [post]
+func (h *AdminHandler) ActivateCurrency(c *gin.Context) {
+	h.setCurrencyActive(c, true)
+}
+
+// DeactivateCurrency 停用幣種
+//
+// @Summary Deactivate currency (admin)
+// @Description Hide a currency from the public list and refuse new wallets and transfers in it
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Currency ID"
+// @Success 200 {object} models.CurrencyResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/currencies/{id}/deactivate [post]
+func (h *AdminHandler) DeactivateCurrency(c *gin.Context) {
+	h.setCurrencyActive(c, false)
+}
+
+func (h *AdminHandler) setCurrencyActive(c *gin.Context, active bool) {
+	id, ok := parseCurrencyIDParam(c)
+	if !ok {
+		return
+	}
+
+	currency, err := h.currencyService.SetActive(id, active)
+	if err != nil {
+		respondCurrencyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToCurrencyResponse(currency))
+}
+
+// GetFeeSchedule 取得幣種的轉帳手續費設定
+//
+// @Summary Get fee schedule (admin)
+// @Description Get the transfer fee schedule of a currency
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Currency ID"
+// @Success 200 {object} models.FeeScheduleResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/currencies/{id}/fee-schedule [get]
+func (h *AdminHandler) GetFeeSchedule(c *gin.Context) {
+	id, ok := parseCurrencyIDParam(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.feeService.GetSchedule(id)
+	if err != nil {
+		respondFeeScheduleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToFeeScheduleResponse(schedule))
+}
+
+// SetFeeSchedule 設定（取代）幣種的轉帳手續費
+//
+// @Summary Set fee schedule (admin)
+// @Description Replace the transfer fee schedule of a currency: flat, percentage (with optional min/max) or tiered by amount
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Currency ID"
+// @Param request body models.FeeScheduleRequest true "Fee schedule"
+// @Success 200 {object} models.FeeScheduleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/currencies/{id}/fee-schedule [put]
+func (h *AdminHandler) SetFeeSchedule(c *gin.Context) {
+	id, ok := parseCurrencyIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.FeeScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidRequest})
+		return
+	}
+
+	schedule, err := h.feeService.SetSchedule(id, req)
+	if err != nil {
+		respondFeeScheduleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToFeeScheduleResponse(schedule))
+}
+
+// DeleteFeeSchedule 移除幣種的轉帳手續費設定
+//
+// @Summary Delete fee schedule (admin)
+// @Description Remove the fee schedule; transfers in the currency become free
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path int true "Currency ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/currencies/{id}/fee-schedule [delete]
+func (h *AdminHandler) DeleteFeeSchedule(c *gin.Context) {
+	id, ok := parseCurrencyIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.feeService.DeleteSchedule(id); err != nil {
+		respondFeeScheduleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetCurrencyLimit 取得幣種的預設轉帳限額
+//
+// @Summary Get currency transfer limits (admin)
+// @Description Get the default transfer limits of a currency; they apply to every user without an override
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Currency ID"
+// @Success 200 {object} models.TransferLimitResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/currencies/{id}/limits [get]
+func (h *AdminHandler) GetCurrencyLimit(c *gin.Context) {
+	id, ok := parseCurrencyIDParam(c)
+	if !ok {
+		return
+	}
+
+	limit, err := h.limitService.GetCurrencyLimit(id)
+	if err != nil {
+		respondLimitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToTransferLimitResponse(limit))
+}
+
+// SetCurrencyLimit 設定幣種的預設轉帳限額
+//
+// @Summary Set currency transfer limits (admin)
+// @Description Set the default single, daily, monthly and hourly-count limits of a currency. 0 means no limit.
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Currency ID"
+// @Param request body models.TransferLimitRequest true "Limits"
+// @Success 200 {object} models.TransferLimitResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/currencies/{id}/limits [put]
+func (h *AdminHandler) SetCurrencyLimit(c *gin.Context) {
+	id, ok := parseCurrencyIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.TransferLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidRequest})
+		return
+	}
+
+	limit, err := h.limitService.SetCurrencyLimit(id, req)
+	if err != nil {
+		respondLimitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToTransferLimitResponse(limit))
+}
+
+// DeleteCurrencyLimit 移除幣種的預設轉帳限額
+//
+// @Summary Delete currency transfer limits (admin)
+// @Description Remove the default limits of a currency; users without an override become unlimited
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path int true "Currency ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/currencies/{id}/limits [delete]
+func (h *AdminHandler) DeleteCurrencyLimit(c *gin.Context) {
+	id, ok := parseCurrencyIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.limitService.DeleteCurrencyLimit(id); err != nil {
+		respondLimitError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetUserLimits 查詢用戶的專屬轉帳限額
+//
+// @Summary Get user transfer limit overrides (staff)
+// @Description List the per-currency limit overrides of a user
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} models.TransferLimitResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/users/{id}/limits [get]
+func (h *AdminHandler) GetUserLimits(c *gin.Context) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	limits, err := h.limitService.GetUserLimits(userID)
+	if err != nil {
+		respondLimitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToTransferLimitResponses(limits))
+}
+
+// SetUserLimit 設定用戶在某幣種的專屬轉帳限額
+//
+// @Summary Override user transfer limits (admin)
+// @Description Set limits for one user and currency; they replace the currency defaults entirely. 0 means no limit.
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param currency_id path int true "Currency ID"
+// @Param request body models.TransferLimitRequest true "Limits"
+// @Success 200 {object} models.TransferLimitResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/users/{id}/limits/{currency_id} [put]
+func (h *AdminHandler) SetUserLimit(c *gin.Context) {
+	userID, currencyID, ok := parseUserLimitParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.TransferLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidRequest})
+		return
+	}
+
+	limit, err := h.limitService.SetUserLimit(userID, currencyID, req)
+	if err != nil {
+		respondLimitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToTransferLimitResponse(limit))
+}
+
+// DeleteUserLimit 移除用戶的專屬轉帳限額
+//
+// @Summary Delete user transfer limit override (admin)
+// @Description Remove a user's override; the currency defaults apply again
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param currency_id path int true "Currency ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/users/{id}/limits/{currency_id} [delete]
+func (h *AdminHandler) DeleteUserLimit(c *gin.Context) {
+	userID, currencyID, ok := parseUserLimitParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.limitService.DeleteUserLimit(userID, currencyID); err != nil {
+		respondLimitError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MarkFundingProcessing 將入金 / 出金標記為處理中
+//
+// @Summary Mark deposit/withdrawal processing (admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param hash path string true "Transaction Hash"
+// @Success 200 {object} models.TransactionResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/funding/{hash}/processing [post]
+func (h *AdminHandler) MarkFundingProcessing(c *gin.Context) {
+	tx, err := h.fundingService.MarkProcessing(c.Param("hash"))
+	if err != nil {
+		respondFundingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToTransactionResponse(tx))
+}
+
+// CompleteFunding 完成入金（入帳）或出金（結算凍結金額）
+//
+// @Summary Complete deposit/withdrawal (admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param hash path string true "Transaction Hash"
+// @Success 200 {object} models.TransactionResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/funding/{hash}/complete [post]
+func (h *AdminHandler) CompleteFunding(c *gin.Context) {
+	tx, err := h.fundingService.Complete(c.Param("hash"))
+	if err != nil {
+		respondFundingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToTransactionResponse(tx))
+}
+
+// FailFunding 將入金 / 出金標記為失敗，出金的凍結金額退回可用餘額
+//
+// @Summary Fail deposit/withdrawal (admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param hash path string true "Transaction Hash"
+// @Param fail body models.FailFundingRequest true "Failure reason"
+// @Success 200 {object} models.TransactionResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/funding/{hash}/fail [post]
+func (h *AdminHandler) FailFunding(c *gin.Context) {
+	var req models.FailFundingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	middleware.AddAuditDetail(c, "reason", req.Reason)
+
+	tx, err := h.fundingService.Fail(c.Param("hash"), req.Reason)
+	if err != nil {
+		respondFundingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToTransactionResponse(tx))
+}
+
+// GetAuditLogs 分頁查詢管理操作稽核紀錄
+//
+// @Summary List admin audit logs (admin)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param actor_id query int false "Only actions of this staff member"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Router /admin/audit-logs [get]
+func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
+	var actorID uint64
+	if raw := c.Query("actor_id"); raw != "" {
+		parsed, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid actor_id"})
+			return
+		}
+		actorID = parsed
+	}
+
+	pagination := bindPagination(c)
+	logs, total, err := h.auditService.GetLogs(uint(actorID), pagination.GetOffset(), pagination.GetLimit())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch audit logs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       models.ToAdminAuditLogResponses(logs),
+		"pagination": newPaginationResponse(pagination, total),
+	})
+}
+
+func parseUserIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondAdminError 將管理操作的錯誤轉成 HTTP 回應
+func respondAdminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeUserNotFound})
+	case errors.Is(err, services.ErrInvalidRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidRole})
+	case errors.Is(err, services.ErrCannotModifySelf):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": apperrors.ErrCodeForbidden})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func parseCurrencyIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid currency id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func respondCurrencyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrCurrencyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeCurrencyNotFound})
+	case errors.Is(err, services.ErrCurrencyAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": apperrors.ErrCodeCurrencyAlreadyExists})
+	case errors.Is(err, services.ErrPrecisionConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": apperrors.ErrCodePrecisionConflict})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func respondFeeScheduleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrFeeScheduleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeFeeScheduleNotFound})
+	case errors.Is(err, services.ErrCurrencyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeCurrencyNotFound})
+	case errors.Is(err, services.ErrInvalidFeeSchedule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidFeeSchedule})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func parseUserLimitParams(c *gin.Context) (uint, uint, bool) {
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return 0, 0, false
+	}
+	currencyID, err := strconv.ParseUint(c.Param("currency_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid currency id"})
+		return 0, 0, false
+	}
+	return userID, uint(currencyID), true
+}
+
+func respondLimitError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTransferLimitNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeTransferLimitNotFound})
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeUserNotFound})
+	case errors.Is(err, services.ErrCurrencyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeCurrencyNotFound})
+	case errors.Is(err, services.ErrInvalidTransferLimit):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidTransferLimit})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	apperrors "mini-crypto-wallet-api/internal/errors"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditCheckpointsLimit = 20
+	maxAuditCheckpointsLimit     = 100
+)
+
+type AuditChainHandler struct {
+	service *services.AuditChainService
+}
+
+func NewAuditChainHandler(service *services.AuditChainService) *AuditChainHandler {
+	return &AuditChainHandler{service}
+}
+
+// Verify 驗證餘額歷史的 hash chain，指出每個錢包第一個斷點
+//
+// @Summary Verify balance history chains
+// @Description Walk every wallet's hash-chained balance history from the first entry, compare it with the latest checkpoint and report the first broken link per wallet
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param wallet_id query int false "Only verify this wallet"
+// @Success 200 {object} models.AuditChainReport
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /admin/audit/chain/verify [get]
+func (h *AuditChainHandler) Verify(c *gin.Context) {
+	var walletID uint64
+	if raw := c.Query("wallet_id"); raw != "" {
+		var err error
+		if walletID, err = strconv.ParseUint(raw, 10, 64); err != nil || walletID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet_id"})
+			return
+		}
+	}
+
+	report, err := h.service.Verify(uint(walletID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "chain verification failed: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// CreateCheckpoint 立即建立一個 Merkle checkpoint
+//
+// @Summary Create audit checkpoint
+// @Description Take a Merkle root over the head of every wallet's balance history chain now
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 201 {object} models.AuditCheckpointResponse
+// @Failure 403 {object} map[string]string
+// @Router /admin/audit/checkpoints [post]
+func (h *AuditChainHandler) CreateCheckpoint(c *gin.Context) {
+	checkpoint, err := h.service.CreateCheckpoint()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create checkpoint: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, models.ToAuditCheckpointResponse(checkpoint, nil))
+}
+
+// GetCheckpoints 取得最近的 checkpoint
+//
+// @Summary List audit checkpoints
+// @Description List the most recent Merkle checkpoints, newest first
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Number of checkpoints (default 20, max 100)"
+// @Success 200 {array} models.AuditCheckpointResponse
+// @Failure 403 {object} map[string]string
+// @Router /admin/audit/checkpoints [get]
+func (h *AuditChainHandler) GetCheckpoints(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultAuditCheckpointsLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultAuditCheckpointsLimit
+	}
+	if limit > maxAuditCheckpointsLimit {
+		limit = maxAuditCheckpointsLimit
+	}
+
+	checkpoints, err := h.service.GetCheckpoints(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch checkpoints"})
+		return
+	}
+	c.JSON(http.StatusOK, models.ToAuditCheckpointResponses(checkpoints))
+}
+
+// GetCheckpoint 匯出 checkpoint 與所有葉節點，支援 JSON 與 CSV
+//
+// @Summary Export audit checkpoint
+// @Description Export a checkpoint with every leaf (wallet_id, sequence, entry_hash) so the Merkle root can be recomputed independently. JSON (default) or CSV (format=csv or Accept: text/csv)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Produce text/csv
+// @Param id path int true "Checkpoint ID"
+// @Param format query string false "json or csv"
+// @Success 200 {object} models.AuditCheckpointResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/audit/checkpoints/{id} [get]
+func (h *AuditChainHandler) GetCheckpoint(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid checkpoint id"})
+		return
+	}
+
+	checkpoint, leaves, err := h.service.GetCheckpoint(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrAuditCheckpointNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeAuditCheckpointNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch checkpoint"})
+		return
+	}
+
+	if wantsCSV(c) {
+		writeCheckpointCSV(c, checkpoint, leaves)
+		return
+	}
+	c.JSON(http.StatusOK, models.ToAuditCheckpointResponse(checkpoint, leaves))
+}
+
+// writeCheckpointCSV 以 CSV 輸出葉節點，依 Merkle tree 的順序排列，Merkle root 放在 header
+func writeCheckpointCSV(c *gin.Context, checkpoint *models.AuditCheckpoint, leaves []models.AuditCheckpointLeaf) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=audit-checkpoint-%d.csv", checkpoint.ID))
+	c.Header("X-Merkle-Root", checkpoint.MerkleRoot)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"position", "wallet_id", "sequence", "entry_hash"})
+	for _, leaf := range leaves {
+		_ = w.Write([]string{
+			strconv.Itoa(leaf.Position),
+			strconv.FormatUint(uint64(leaf.WalletID), 10),
+			strconv.FormatUint(leaf.Sequence, 10),
+			leaf.EntryHash,
+		})
+	}
+	w.Flush()
+}
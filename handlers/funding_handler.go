@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	apperrors "mini-crypto-wallet-api/internal/errors"
+	"mini-crypto-wallet-api/middleware"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FundingHandler struct {
+	service *services.FundingService
+}
+
+func NewFundingHandler(service *services.FundingService) *FundingHandler {
+	return &FundingHandler{service}
+}
+
+// CreateDeposit 建立待入帳的入金
+//
+// @Summary Create deposit
+// @Description Announce an incoming deposit. The balance is credited once an admin completes it via /admin/funding/{hash}/complete
+// @Tags Funding
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param deposit body models.DepositRequest true "Deposit info"
+// @Success 201 {object} models.TransactionResponse
+// @Failure 400 {object} map[string]string
+// @Router /wallet/deposits [post]
+func (h *FundingHandler) CreateDeposit(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.DepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := h.service.CreateDeposit(userID, req.CurrencyID, req.Amount, req.Reference)
+	if err != nil {
+		respondFundingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.ToTransactionResponse(tx))
+}
+
+// CreateWithdrawal 建立出金並凍結資金
+//
+// @Summary Create withdrawal
+// @Description Request a withdrawal. The amount is put on hold until an admin completes or fails it via /admin/funding/{hash}/complete or /fail
+// @Tags Funding
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param withdrawal body models.WithdrawalRequest true "Withdrawal info"
+// @Success 201 {object} models.TransactionResponse
+// @Failure 400 {object} map[string]string
+// @Router /wallet/withdrawals [post]
+func (h *FundingHandler) CreateWithdrawal(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.WithdrawalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := h.service.CreateWithdrawal(userID, req.CurrencyID, req.Amount, req.Address)
+	if err != nil {
+		respondFundingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.ToTransactionResponse(tx))
+}
+
+// CancelDeposit 取消尚在 pending 的入金
+//
+// @Summary Cancel deposit
+// @Description Cancel one of your own pending deposits
+// @Tags Funding
+// @Security BearerAuth
+// @Produce json
+// @Param hash path string true "Transaction Hash"
+// @Success 200 {object} models.TransactionResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /wallet/deposits/{hash}/cancel [post]
+func (h *FundingHandler) CancelDeposit(c *gin.Context) {
+	h.cancel(c, models.TxTypeDeposit)
+}
+
+// CancelWithdrawal 取消尚在 pending 的出金，凍結金額退回可用餘額
+//
+// @Summary Cancel withdrawal
+// @Description Cancel one of your own pending withdrawals and release the held funds
+// @Tags Funding
+// @Security BearerAuth
+// @Produce json
+// @Param hash path string true "Transaction Hash"
+// @Success 200 {object} models.TransactionResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /wallet/withdrawals/{hash}/cancel [post]
+func (h *FundingHandler) CancelWithdrawal(c *gin.Context) {
+	h.cancel(c, models.TxTypeWithdrawal)
+}
+
+func (h *FundingHandler) cancel(c *gin.Context, txType string) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	tx, err := h.service.Cancel(userID, txType, c.Param("hash"))
+	if err != nil {
+		respondFundingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToTransactionResponse(tx))
+}
+
+// respondFundingError 將入金 / 出金的錯誤轉成 HTTP 回應
+func respondFundingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTransactionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeTransactionNotFound})
+	case errors.Is(err, services.ErrInvalidStatusTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidStatusTransition})
+	case errors.Is(err, services.ErrAccountFrozen):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": apperrors.ErrCodeAccountFrozen})
+	case errors.Is(err, services.ErrCurrencyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeCurrencyNotFound})
+	case errors.Is(err, services.ErrCurrencyInactive):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": apperrors.ErrCodeCurrencyInactive})
+	case errors.Is(err, services.ErrAmountPrecision):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidAmount})
+	case errors.Is(err, services.ErrInsufficientBalance):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInsufficientBalance})
+	case errors.Is(err, services.ErrWalletNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeWalletNotFound})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	apperrors "mini-crypto-wallet-api/internal/errors"
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/repositories"
+	"mini-crypto-wallet-api/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCreateWithdrawal_ErrorCodes verifies withdrawal failures carry the shared error codes
+func TestCreateWithdrawal_ErrorCodes(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	btc := test.CreateTestCurrency(db, "BTC")
+	alice := test.CreateTestUser(db, "alice")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", alice.ID) })
+	handler := NewFundingHandler(services.NewFundingService(repositories.NewWalletRepository(), repositories.NewTransactionRepository()))
+	router.POST("/wallet/withdrawals", handler.CreateWithdrawal)
+
+	cases := []struct {
+		name       string
+		currencyID uint
+		amount     string
+		status     int
+		code       string
+	}{
+		{name: "insufficient balance", currencyID: usdt.ID, amount: "150", status: http.StatusBadRequest, code: apperrors.ErrCodeInsufficientBalance},
+		{name: "no wallet", currencyID: btc.ID, amount: "1", status: http.StatusNotFound, code: apperrors.ErrCodeWalletNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"currency_id": %d, "amount": %s, "address": "0xabc"}`, tc.currencyID, tc.amount)
+			req := httptest.NewRequest(http.MethodPost, "/wallet/withdrawals", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			var resp map[string]string
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tc.status, rec.Code)
+			assert.Equal(t, tc.code, resp["code"])
+		})
+	}
+}
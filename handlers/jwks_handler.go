@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"mini-crypto-wallet-api/internal/auth"
+)
+
+type JWKSHandler struct {
+	jwtManager *auth.JWTManager
+}
+
+func NewJWKSHandler(jwtManager *auth.JWTManager) *JWKSHandler {
+	return &JWKSHandler{jwtManager}
+}
+
+// GetJWKS 公開驗證 access token 用的公鑰
+//
+// @Summary JSON Web Key Set
+// @Description Public keys (by kid) for verifying access tokens signed with RS256 or EdDSA
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} auth.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtManager.JWKS())
+}
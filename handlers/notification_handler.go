@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	apperrors "mini-crypto-wallet-api/internal/errors"
+	"mini-crypto-wallet-api/middleware"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	service *services.NotificationService
+}
+
+func NewNotificationHandler(service *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service}
+}
+
+// List 取得目前用戶的通知
+//
+// @Summary List notifications
+// @Description List the authenticated user's notifications, newest first. unread_count is the total number of unread notifications regardless of the filter.
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Param unread query bool false "Only return unread notifications"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /notifications [get]
+func (h *NotificationHandler) List(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.NotificationListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidRequest})
+		return
+	}
+	pagination := &req.PaginationRequest
+
+	notifications, total, unread, err := h.service.List(userID, req.Unread, pagination.GetOffset(), pagination.GetLimit())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":         models.ToNotificationResponses(notifications),
+		"unread_count": unread,
+		"pagination":   newPaginationResponse(pagination, total),
+	})
+}
+
+// MarkRead 將一則通知標記為已讀
+//
+// @Summary Mark notification as read
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} models.NotificationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /notifications/{id}/read [post]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification id"})
+		return
+	}
+
+	notification, err := h.service.MarkRead(userID, uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrNotificationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeNotificationNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update notification"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToNotificationResponse(notification))
+}
+
+// MarkAllRead 將目前用戶所有未讀通知標記為已讀
+//
+// @Summary Mark all notifications as read
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.service.MarkAllRead(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
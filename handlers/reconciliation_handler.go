@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	apperrors "mini-crypto-wallet-api/internal/errors"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/services"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultReconciliationRunsLimit = 20
+	maxReconciliationRunsLimit     = 100
+)
+
+type ReconciliationHandler struct {
+	service *services.ReconciliationService
+}
+
+func NewReconciliationHandler(service *services.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{service}
+}
+
+// RunReconciliation 手動觸發一次對帳
+//
+// @Summary Run reconciliation
+// @Description Reconcile every wallet and transaction now and store the findings
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 201 {object} models.ReconciliationRunResponse
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/reconciliation/runs [post]
+func (h *ReconciliationHandler) RunReconciliation(c *gin.Context) {
+	run, err := h.service.Run(models.ReconciliationTriggerManual)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "reconciliation failed: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.ToReconciliationRunResponse(run))
+}
+
+// GetRuns 取得最近的對帳紀錄
+//
+// @Summary List reconciliation runs
+// @Description List the most recent reconciliation runs, newest first
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Number of runs (default 20, max 100)"
+// @Success 200 {array} models.ReconciliationRunResponse
+// @Failure 403 {object} map[string]string
+// @Router /admin/reconciliation/runs [get]
+func (h *ReconciliationHandler) GetRuns(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultReconciliationRunsLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultReconciliationRunsLimit
+	}
+	if limit > maxReconciliationRunsLimit {
+		limit = maxReconciliationRunsLimit
+	}
+
+	runs, err := h.service.GetRuns(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch reconciliation runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToReconciliationRunResponses(runs))
+}
+
+// GetFindings 取得某次對帳的異常，支援 JSON 與 CSV
+//
+// @Summary Get reconciliation findings
+// @Description Get the findings of a reconciliation run as JSON (default) or CSV (format=csv or Accept: text/csv)
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Produce text/csv
+// @Param id path int true "Run ID"
+// @Param type query string false "Finding type (balance_drift, ledger_drift, orphaned_history, one_sided_transfer, hash_mismatch)"
+// @Param format query string false "json or csv"
+// @Success 200 {array} models.ReconciliationFindingResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/reconciliation/runs/{id}/findings [get]
+func (h *ReconciliationHandler) GetFindings(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid run id"})
+		return
+	}
+
+	run, findings, err := h.service.GetFindings(uint(id), c.Query("type"))
+	if err != nil {
+		if errors.Is(err, services.ErrReconciliationRunNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeReconciliationRunNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch reconciliation findings"})
+		return
+	}
+
+	if wantsCSV(c) {
+		writeFindingsCSV(c, run, findings)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToReconciliationFindingResponses(findings))
+}
+
+func wantsCSV(c *gin.Context) bool {
+	if format := c.Query("format"); format != "" {
+		return strings.EqualFold(format, "csv")
+	}
+	return strings.Contains(c.GetHeader("Accept"), "text/csv")
+}
+
+// writeFindingsCSV 以 CSV 輸出異常，第一列為欄位名稱
+func writeFindingsCSV(c *gin.Context, run *models.ReconciliationRun, findings []models.ReconciliationFinding) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=reconciliation-%d.csv", run.ID))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"id", "run_id", "type", "wallet_id", "transaction_id", "history_id", "expected", "actual", "detail", "created_at"})
+	for _, f := range findings {
+		_ = w.Write([]string{
+			strconv.FormatUint(uint64(f.ID), 10),
+			strconv.FormatUint(uint64(f.RunID), 10),
+			f.Type,
+			strconv.FormatUint(uint64(f.WalletID), 10),
+			strconv.FormatUint(uint64(f.TransactionID), 10),
+			strconv.FormatUint(uint64(f.HistoryID), 10),
+			f.Expected.String(),
+			f.Actual.String(),
+			f.Detail,
+			f.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	w.Flush()
+}
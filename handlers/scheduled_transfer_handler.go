@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	apperrors "mini-crypto-wallet-api/internal/errors"
+	"mini-crypto-wallet-api/middleware"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduledTransferHandler struct {
+	service *services.ScheduledTransferService
+}
+
+func NewScheduledTransferHandler(service *services.ScheduledTransferService) *ScheduledTransferHandler {
+	return &ScheduledTransferHandler{service}
+}
+
+// Create 建立預約 / 週期轉帳
+//
+// @Summary Schedule transfer
+// @Description Schedule a transfer from the authenticated user once at start_at, or repeating daily, weekly or monthly until end_at
+// @Tags Scheduled Transfers
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.CreateScheduledTransferRequest true "Schedule"
+// @Success 201 {object} models.ScheduledTransferResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /wallet/scheduled-transfers [post]
+func (h *ScheduledTransferHandler) Create(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateScheduledTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule, err := h.service.Create(userID, req)
+	if err != nil {
+		respondScheduledTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.ToScheduledTransferResponse(schedule))
+}
+
+// List 取得目前用戶的排程
+//
+// @Summary List scheduled transfers
+// @Description List the authenticated user's scheduled transfers (cancelled ones are omitted)
+// @Tags Scheduled Transfers
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.ScheduledTransferResponse
+// @Router /wallet/scheduled-transfers [get]
+func (h *ScheduledTransferHandler) List(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	schedules, err := h.service.List(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch scheduled transfers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToScheduledTransferResponses(schedules))
+}
+
+// Get 取得單一排程
+//
+// @Summary Get scheduled transfer
+// @Tags Scheduled Transfers
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Scheduled transfer ID"
+// @Success 200 {object} models.ScheduledTransferResponse
+// @Failure 404 {object} map[string]string
+// @Router /wallet/scheduled-transfers/{id} [get]
+func (h *ScheduledTransferHandler) Get(c *gin.Context) {
+	userID, id, ok := scheduledTransferParams(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.service.Get(userID, id)
+	if err != nil {
+		respondScheduledTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToScheduledTransferResponse(schedule))
+}
+
+// Update 修改、暫停或恢復排程
+//
+// @Summary Update scheduled transfer
+// @Description Change the amount or end_at, or pause (active=false) / resume (active=true). Resuming skips occurrences missed while paused and re-enables schedules disabled after failures.
+// @Tags Scheduled Transfers
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Scheduled transfer ID"
+// @Param request body models.UpdateScheduledTransferRequest true "Fields to change"
+// @Success 200 {object} models.ScheduledTransferResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /wallet/scheduled-transfers/{id} [patch]
+func (h *ScheduledTransferHandler) Update(c *gin.Context) {
+	userID, id, ok := scheduledTransferParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateScheduledTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule, err := h.service.Update(userID, id, req)
+	if err != nil {
+		respondScheduledTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToScheduledTransferResponse(schedule))
+}
+
+// Cancel 取消排程
+//
+// @Summary Cancel scheduled transfer
+// @Description Cancel a schedule; runs that already happened are kept
+// @Tags Scheduled Transfers
+// @Security BearerAuth
+// @Param id path int true "Scheduled transfer ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /wallet/scheduled-transfers/{id} [delete]
+func (h *ScheduledTransferHandler) Cancel(c *gin.Context) {
+	userID, id, ok := scheduledTransferParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Cancel(userID, id); err != nil {
+		respondScheduledTransferError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetRuns 取得排程每一期的執行紀錄
+//
+// @Summary Get scheduled transfer runs
+// @Description One entry per occurrence with its status (completed, failed, skipped), attempts and last error
+// @Tags Scheduled Transfers
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Scheduled transfer ID"
+// @Success 200 {array} models.ScheduledTransferRunResponse
+// @Failure 404 {object} map[string]string
+// @Router /wallet/scheduled-transfers/{id}/runs [get]
+func (h *ScheduledTransferHandler) GetRuns(c *gin.Context) {
+	userID, id, ok := scheduledTransferParams(c)
+	if !ok {
+		return
+	}
+
+	runs, err := h.service.Runs(userID, id)
+	if err != nil {
+		respondScheduledTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToScheduledTransferRunResponses(runs))
+}
+
+func scheduledTransferParams(c *gin.Context) (uint, uint, bool) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scheduled transfer id"})
+		return 0, 0, false
+	}
+	return userID, uint(id), true
+}
+
+// respondScheduledTransferError 將排程錯誤轉成 HTTP 回應，其餘錯誤沿用轉帳的對應
+func respondScheduledTransferError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrScheduledTransferNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeScheduledTransferNotFound})
+	case errors.Is(err, services.ErrScheduledTransferClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": apperrors.ErrCodeScheduledTransferClosed})
+	case errors.Is(err, services.ErrInvalidScheduledTransfer):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidRequest})
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeUserNotFound})
+	default:
+		respondTransferError(c, err)
+	}
+}
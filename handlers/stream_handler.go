@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"mini-crypto-wallet-api/internal/stream"
+	"mini-crypto-wallet-api/middleware"
+	"mini-crypto-wallet-api/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultStreamHeartbeat 沒有事件時送出 heartbeat 的間隔，需短於代理與負載平衡器的閒置逾時
+	DefaultStreamHeartbeat = 15 * time.Second
+	streamRetryMillis      = 3000
+)
+
+type StreamHandler struct {
+	broker    *stream.Broker
+	heartbeat time.Duration
+}
+
+func NewStreamHandler(broker *stream.Broker, heartbeat time.Duration) *StreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = DefaultStreamHeartbeat
+	}
+	return &StreamHandler{broker: broker, heartbeat: heartbeat}
+}
+
+// Stream 以 Server-Sent Events 推送目前用戶已 commit 的交易與餘額變動
+//
+// @Summary Stream wallet events
+// @Description Server-Sent Events stream of the authenticated user's new or updated transactions (event: transaction) and wallet balances after each change (event: balance, keep the highest sequence per wallet). A comment line is sent as a heartbeat when idle. Reconnect with the Last-Event-ID header (or last_event_id query) to replay missed events; when they are no longer available a reset event is sent first and the client should refetch balances and transactions.
+// @Tags Stream
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Param last_event_id query string false "Same as the Last-Event-ID header, for clients that cannot set it"
+// @Success 200 {string} string "text/event-stream"
+// @Router /stream [get]
+func (h *StreamHandler) Stream(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	// 先訂閱再補送，補送期間發生的事件會留在訂閱的緩衝中，不會遺漏
+	sub, replay, gap := h.broker.Subscribe(userID, lastEventID)
+	defer h.broker.Unsubscribe(sub)
+
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	stream.WriteRetry(w, streamRetryMillis)
+	if gap {
+		data, _ := json.Marshal(models.StreamResetData{Reason: "events since the last event id are no longer available"})
+		stream.WriteEvent(w, "", models.StreamEventReset, data)
+	}
+	for _, event := range replay {
+		stream.WriteEvent(w, event.ID, event.Type, event.Data)
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				// 消費太慢被 broker 中斷，用戶端會帶 Last-Event-ID 重新連線並補送
+				return
+			}
+			err = stream.WriteEvent(w, event.ID, event.Type, event.Data)
+		case now := <-heartbeat.C:
+			err = stream.WriteComment(w, "heartbeat "+now.UTC().Format(time.RFC3339))
+		}
+		if err != nil {
+			return
+		}
+		w.Flush()
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	apperrors "mini-crypto-wallet-api/internal/errors"
+	"mini-crypto-wallet-api/internal/rates"
+	"mini-crypto-wallet-api/middleware"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SwapHandler struct {
+	service *services.SwapService
+}
+
+func NewSwapHandler(service *services.SwapService) *SwapHandler {
+	return &SwapHandler{service}
+}
+
+// Quote 取得兌換報價
+//
+// @Summary Quote swap
+// @Description Price a conversion between two currencies. The quote includes the spread fee and expires after a short time
+// @Tags Swap
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param quote body models.SwapQuoteRequest true "Swap to price"
+// @Success 201 {object} models.SwapQuoteResponse
+// @Failure 400 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /wallet/swaps/quote [post]
+func (h *SwapHandler) Quote(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SwapQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, err := h.service.Quote(userID, req)
+	if err != nil {
+		respondSwapError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.ToSwapQuoteResponse(quote))
+}
+
+// Swap 執行兌換報價
+//
+// @Summary Execute swap
+// @Description Execute a quote at the live rate. Refused when the quote expired or the live amount is worse than the quote by more than max_slippage_bps (default 50)
+// @Tags Swap
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param swap body models.SwapRequest true "Quote to execute"
+// @Success 200 {object} models.SwapResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /wallet/swaps [post]
+func (h *SwapHandler) Swap(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, tx, err := h.service.Swap(userID, req.QuoteID, req.MaxSlippageBps)
+	if err != nil {
+		respondSwapError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToSwapResponse(quote, tx))
+}
+
+// respondSwapError 將兌換錯誤轉成 HTTP 回應
+func respondSwapError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, rates.ErrRateUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error(), "code": apperrors.ErrCodeRateUnavailable})
+	case errors.Is(err, services.ErrQuoteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeQuoteNotFound})
+	case errors.Is(err, services.ErrQuoteExpired):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": apperrors.ErrCodeQuoteExpired})
+	case errors.Is(err, services.ErrQuoteUsed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": apperrors.ErrCodeQuoteUsed})
+	case errors.Is(err, services.ErrSlippageExceeded):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": apperrors.ErrCodeSlippageExceeded})
+	case errors.Is(err, services.ErrAccountFrozen):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": apperrors.ErrCodeAccountFrozen})
+	case errors.Is(err, services.ErrCurrencyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeCurrencyNotFound})
+	case errors.Is(err, services.ErrCurrencyInactive):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": apperrors.ErrCodeCurrencyInactive})
+	case errors.Is(err, services.ErrAmountPrecision):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidAmount})
+	case errors.Is(err, services.ErrInsufficientBalance):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInsufficientBalance})
+	case errors.Is(err, services.ErrWalletNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeWalletNotFound})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	apperrors "mini-crypto-wallet-api/internal/errors"
 	"mini-crypto-wallet-api/middleware"
 	"mini-crypto-wallet-api/models"
 	"mini-crypto-wallet-api/services"
@@ -10,6 +12,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// IdempotencyKeyHeader 轉帳請求可帶入的冪等鍵 header
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength Idempotency-Key 的最大長度
+const maxIdempotencyKeyLength = 255
+
 type TransactionHandler struct {
 	service *services.TransactionService
 }
@@ -21,14 +29,19 @@ func NewTransactionHandler(service *services.TransactionService) *TransactionHan
 // Transfer 執行兩個使用者之間的轉帳動作
 //
 // @Summary Transfer funds
-// @Description Transfer funds between two users
+// @Description Transfer funds between two users.
+// @Description Send an Idempotency-Key header to make retries safe: replays with the same body return the original result
 // @Tags Wallet
 // @Security BearerAuth
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Client generated unique key (max 255 chars)"
 // @Param transfer body models.TransferRequest true "Transfer info"
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {object} models.TransferResponse
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Router /wallet/transfer [post]
 func (h *TransactionHandler) Transfer(c *gin.Context) {
 	var req models.TransferRequest
@@ -42,11 +55,165 @@ func (h *TransactionHandler) Transfer(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.Transfer(req.FromUserID, req.ToUserID, req.CurrencyID, req.Amount); err != nil {
+	idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+	if idempotencyKey == "" {
+		tx, err := h.service.TransferWithResult(req.FromUserID, req.ToUserID, req.CurrencyID, req.Amount)
+		if err != nil {
+			respondTransferError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, models.ToTransferResponse(tx))
+		return
+	}
+
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "idempotency key too long", "code": apperrors.ErrCodeInvalidIdempotencyKey})
+		return
+	}
+
+	response, replayed, err := h.service.TransferIdempotent(idempotencyKey, &req)
+	if err != nil {
+		respondTransferError(c, err)
+		return
+	}
+
+	if replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// QuoteTransfer 試算轉帳手續費
+//
+// @Summary Quote transfer fee
+// @Description Preview the fee for a transfer. The recipient receives amount; the sender pays total = amount + fee.
+// @Tags Wallet
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.TransferQuoteRequest true "Currency and amount"
+// @Success 200 {object} models.TransferQuoteResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /wallet/transfer/quote [post]
+func (h *TransactionHandler) QuoteTransfer(c *gin.Context) {
+	var req models.TransferQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, err := h.service.QuoteTransfer(req.CurrencyID, req.Amount)
+	if err != nil {
+		respondTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+// respondTransferError 將轉帳錯誤轉成 HTTP 回應
+func respondTransferError(c *gin.Context, err error) {
+	status, code := transferErrorStatus(err)
+	if code == "" {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error(), "code": code})
+}
+
+// transferErrorStatus 回傳轉帳錯誤對應的 HTTP 狀態碼與錯誤代碼（沒有專屬代碼時為空字串）
+func transferErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, services.ErrIdempotencyKeyMismatch):
+		return http.StatusUnprocessableEntity, apperrors.ErrCodeIdempotencyKeyMismatch
+	case errors.Is(err, services.ErrAccountFrozen):
+		return http.StatusForbidden, apperrors.ErrCodeAccountFrozen
+	case errors.Is(err, services.ErrAmountPrecision):
+		return http.StatusBadRequest, apperrors.ErrCodeInvalidAmount
+	case errors.Is(err, services.ErrCurrencyNotFound):
+		return http.StatusNotFound, apperrors.ErrCodeCurrencyNotFound
+	case errors.Is(err, services.ErrCurrencyInactive):
+		return http.StatusUnprocessableEntity, apperrors.ErrCodeCurrencyInactive
+	case errors.Is(err, services.ErrSingleTransferLimitExceeded):
+		return http.StatusUnprocessableEntity, apperrors.ErrCodeSingleTransferLimitExceeded
+	case errors.Is(err, services.ErrDailyLimitExceeded):
+		return http.StatusUnprocessableEntity, apperrors.ErrCodeDailyLimitExceeded
+	case errors.Is(err, services.ErrMonthlyLimitExceeded):
+		return http.StatusUnprocessableEntity, apperrors.ErrCodeMonthlyLimitExceeded
+	case errors.Is(err, services.ErrInsufficientBalance):
+		return http.StatusBadRequest, apperrors.ErrCodeInsufficientBalance
+	case errors.Is(err, services.ErrVelocityLimitExceeded):
+		return http.StatusTooManyRequests, apperrors.ErrCodeVelocityLimitExceeded
+	default:
+		return http.StatusBadRequest, ""
+	}
+}
+
+// TransferBatch 由目前用戶一次轉帳給多位收款人
+//
+// @Summary Batch transfer
+// @Description Pay up to 500 recipients in one request. The sender wallet is locked once and every item still gets its own transaction.
+// @Description mode=all_or_nothing rolls back every item if any fails (422); mode=best_effort commits the items that succeed (200).
+// @Tags Wallet
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.BatchTransferRequest true "Recipients and amounts"
+// @Success 200 {object} models.BatchTransferResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 422 {object} models.BatchTransferResponse
+// @Router /wallet/transfers/batch [post]
+func (h *TransactionHandler) TransferBatch(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.BatchTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "transfer successful"})
+
+	results, committed, err := h.service.TransferBatch(userID, req.CurrencyID, req.Mode, req.Items)
+	if err != nil {
+		respondTransferError(c, err)
+		return
+	}
+
+	response := models.BatchTransferResponse{
+		Mode:      req.Mode,
+		Committed: committed,
+		Items:     make([]models.BatchTransferItemResult, len(results)),
+	}
+	for i, result := range results {
+		item := models.BatchTransferItemResult{
+			Index:    result.Index,
+			ToUserID: result.Item.ToUserID,
+			Amount:   result.Item.Amount,
+			Status:   result.Status,
+		}
+		if result.Transaction != nil {
+			item.Transaction = models.ToTransactionResponse(result.Transaction)
+		}
+		if result.Err != nil {
+			item.Error = result.Err.Error()
+			_, item.Code = transferErrorStatus(result.Err)
+			response.Failed++
+		} else if result.Status == models.BatchItemCompleted {
+			response.Succeeded++
+		}
+		response.Items[i] = item
+	}
+
+	status := http.StatusOK
+	if !committed {
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, response)
 }
 
 // GetTransactions 根據使用者 ID 取得交易紀錄清單
@@ -75,37 +242,20 @@ func (h *TransactionHandler) GetTransactions(c *gin.Context) {
 	}
 
 	// 解析分頁參數
-	var pagination models.PaginationRequest
-	if err := c.ShouldBindQuery(&pagination); err != nil {
-		pagination.Page = 1
-		pagination.PageSize = 20
-	}
-
-	offset := pagination.GetOffset()
-	limit := pagination.GetLimit()
+	pagination := bindPagination(c)
 
-	txs, total, err := h.service.GetTransactionsWithPagination(uint(userID), offset, limit)
+	txs, total, err := h.service.GetTransactionsWithPagination(uint(userID), pagination.GetOffset(), pagination.GetLimit())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch transactions"})
 		return
 	}
 
-	totalPages := int(total) / limit
-	if int(total)%limit > 0 {
-		totalPages++
-	}
-
 	// Convert models to DTOs (excludes database relationships)
 	txResponses := models.ToTransactionResponses(txs)
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": txResponses,
-		"pagination": models.PaginationResponse{
-			Page:       pagination.Page,
-			PageSize:   limit,
-			Total:      total,
-			TotalPages: totalPages,
-		},
+		"data":       txResponses,
+		"pagination": newPaginationResponse(pagination, total),
 	})
 }
 
@@ -131,3 +281,159 @@ func (h *TransactionHandler) GetTxByHash(c *gin.Context) {
 	response := models.ToTransactionResponse(tx)
 	c.JSON(http.StatusOK, response)
 }
+
+// VerifyTx 驗證交易簽章，確認交易記錄未被竄改
+//
+// @Summary Verify transaction signature
+// @Description Re-check the server's Ed25519 signature over the transaction's canonical serialization.
+// @Description The response includes the signed payload and the public key of its kid so the check can be repeated offline.
+// @Tags Transactions
+// @Produce json
+// @Param hash path string true "Transaction Hash"
+// @Success 200 {object} models.TransactionVerificationResponse
+// @Failure 404 {object} map[string]string
+// @Router /tx/{hash}/verify [get]
+func (h *TransactionHandler) VerifyTx(c *gin.Context) {
+	result, err := h.service.VerifyTransaction(c.Param("hash"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeTransactionNotFound})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// MigrateHashes 將舊的隨機交易 hash 遷移為 content-addressed hash
+//
+// @Summary Migrate legacy transaction hashes (admin)
+// @Description Recompute every legacy transaction hash from its row (adding a nonce). Rows are not re-signed, so verification keeps reporting them as legacy. The old hash is kept and still resolves in lookups. Safe to run again.
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]int
+// @Failure 403 {object} map[string]string
+// @Router /admin/transactions/migrate-hashes [post]
+func (h *TransactionHandler) MigrateHashes(c *gin.Context) {
+	migrated, err := h.service.MigrateLegacyHashes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "hash migration failed", "migrated": migrated})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"migrated": migrated})
+}
+
+// Reverse 由客服沖正一筆已完成的轉帳
+//
+// @Summary Reverse transfer (staff)
+// @Description Create a compensating transaction from the recipient back to the sender and mark the original as reversed.
+// @Description Only the part that has not been refunded is reversed; the fee is not returned. Fails if the recipient's current balance does not cover it.
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param hash path string true "Transaction Hash"
+// @Param request body models.ReverseTransactionRequest true "Reason"
+// @Success 200 {object} models.CompensationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /admin/transactions/{hash}/reverse [post]
+func (h *TransactionHandler) Reverse(c *gin.Context) {
+	var req models.ReverseTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	middleware.AddAuditDetail(c, "reason", req.Reason)
+
+	original, reversal, err := h.service.Reverse(c.Param("hash"), req.Reason)
+	if err != nil {
+		respondCompensationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToCompensationResponse(original, reversal))
+}
+
+// Refund 由收款方退還收到的轉帳
+//
+// @Summary Refund received transfer
+// @Description Send all or part of a received transfer back to its sender. Omit amount to refund everything not refunded yet.
+// @Tags Wallet
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param hash path string true "Transaction Hash"
+// @Param request body models.RefundRequest false "Amount"
+// @Success 200 {object} models.CompensationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /wallet/transactions/{hash}/refund [post]
+func (h *TransactionHandler) Refund(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RefundRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	original, refund, err := h.service.Refund(userID, c.Param("hash"), req.Amount)
+	if err != nil {
+		respondCompensationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToCompensationResponse(original, refund))
+}
+
+// respondCompensationError 將沖正 / 退款錯誤轉成 HTTP 回應
+func respondCompensationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTransactionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeTransactionNotFound})
+	case errors.Is(err, services.ErrNotTransferRecipient):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": apperrors.ErrCodeNotTransferRecipient})
+	case errors.Is(err, services.ErrTransactionAlreadyReversed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": apperrors.ErrCodeTransactionAlreadyReversed})
+	case errors.Is(err, services.ErrTransactionNotReversible):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": apperrors.ErrCodeTransactionNotReversible})
+	case errors.Is(err, services.ErrRefundExceedsRemaining):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "code": apperrors.ErrCodeRefundExceedsRemaining})
+	default:
+		respondTransferError(c, err)
+	}
+}
+
+// bindPagination 解析分頁參數，格式錯誤時使用預設值
+func bindPagination(c *gin.Context) *models.PaginationRequest {
+	var pagination models.PaginationRequest
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		pagination.Page = 1
+		pagination.PageSize = 20
+	}
+	return &pagination
+}
+
+func newPaginationResponse(pagination *models.PaginationRequest, total int64) models.PaginationResponse {
+	limit := pagination.GetLimit()
+	totalPages := int(total) / limit
+	if int(total)%limit > 0 {
+		totalPages++
+	}
+
+	return models.PaginationResponse{
+		Page:       pagination.Page,
+		PageSize:   limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
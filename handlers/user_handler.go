@@ -1,7 +1,9 @@
 package handlers
 
 import (
-	"mini-crypto-wallet-api/internal/auth"
+	"errors"
+	apperrors "mini-crypto-wallet-api/internal/errors"
+	"mini-crypto-wallet-api/middleware"
 	"mini-crypto-wallet-api/models"
 	"mini-crypto-wallet-api/services"
 	"time"
@@ -12,14 +14,14 @@ import (
 )
 
 type UserHandler struct {
-	service    *services.UserService
-	jwtManager *auth.JWTManager
+	service     *services.UserService
+	authService *services.AuthService
 }
 
-func NewUserHandler(service *services.UserService, jwtManager *auth.JWTManager) *UserHandler {
+func NewUserHandler(service *services.UserService, authService *services.AuthService) *UserHandler {
 	return &UserHandler{
-		service:    service,
-		jwtManager: jwtManager,
+		service:     service,
+		authService: authService,
 	}
 }
 
@@ -57,7 +59,7 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 // Login 用戶登入
 //
 // @Summary User login
-// @Description authenticate user and return JWT token
+// @Description authenticate user and return a short-lived JWT access token and a refresh token
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -74,22 +76,101 @@ func (h *UserHandler) Login(c *gin.Context) {
 
 	user, err := h.service.Login(req.Username, req.Password)
 	if err != nil {
+		if errors.Is(err, services.ErrAccountFrozen) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": apperrors.ErrCodeAccountFrozen})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
-	token, err := h.jwtManager.GenerateToken(user.ID, user.Username)
+	pair, err := h.authService.IssueTokens(user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
 		return
 	}
 
-	response := models.LoginResponse{
-		Token:     token,
-		UserID:    user.ID,
-		Username:  user.Username,
-		ExpiresIn: int(24 * time.Hour.Seconds()), // 24小時
+	c.JSON(http.StatusOK, toLoginResponse(user, pair))
+}
+
+// Refresh 以 refresh token 換發新的 token pair
+//
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and refresh token. The old refresh token stops working; reusing it revokes the whole session
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param refresh body models.RefreshRequest true "Refresh token"
+// @Success 200 {object} models.LoginResponse
+// @Failure 401 {object} map[string]string
+// @Router /auth/refresh [post]
+func (h *UserHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	pair, user, err := h.authService.Refresh(req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAccountFrozen):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": apperrors.ErrCodeAccountFrozen})
+		case errors.Is(err, services.ErrRefreshTokenReused):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "code": apperrors.ErrCodeRefreshTokenReused})
+		case errors.Is(err, services.ErrInvalidRefreshToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidRefreshToken})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to refresh token"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toLoginResponse(user, pair))
+}
+
+// Logout 登出，撤銷目前的 access token 與（選填的）refresh token
+//
+// @Summary Logout
+// @Description Revoke the current access token. When a refresh token is supplied its whole session is revoked as well
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Param logout body models.LogoutRequest false "Refresh token to revoke"
+// @Success 204
+// @Failure 401 {object} map[string]string
+// @Router /auth/logout [post]
+func (h *UserHandler) Logout(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	jti := c.GetString("jti")
+	expiresAt := c.GetTime("token_expires_at")
+	if err := h.authService.Logout(userID, jti, expiresAt, req.RefreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to logout"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func toLoginResponse(user *models.User, pair *services.TokenPair) models.LoginResponse {
+	return models.LoginResponse{
+		Token:            pair.Access.Token,
+		RefreshToken:     pair.RefreshToken,
+		UserID:           user.ID,
+		Username:         user.Username,
+		ExpiresIn:        int(time.Until(pair.Access.ExpiresAt).Round(time.Second).Seconds()),
+		RefreshExpiresIn: int(time.Until(pair.RefreshExpiresAt).Round(time.Second).Seconds()),
+	}
 }
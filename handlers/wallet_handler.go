@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	_ "mini-crypto-wallet-api/docs"
+	apperrors "mini-crypto-wallet-api/internal/errors"
 	"mini-crypto-wallet-api/middleware"
 	"mini-crypto-wallet-api/models"
 	"mini-crypto-wallet-api/services"
@@ -22,11 +24,12 @@ func NewWalletHandler(service *services.WalletService) *WalletHandler {
 // GetWallet 根據使用者 ID 查詢錢包餘額
 //
 // @Summary Get wallet balance
-// @Description Retrieve wallet balance by user ID
+// @Description Retrieve wallet balance by user ID. Without currency_id the user's first opened wallet is returned
 // @Tags Wallet
 // @Security BearerAuth
 // @Produce json
 // @Param user_id path int true "User ID"
+// @Param currency_id query int false "Currency ID"
 // @Success 200 {object} models.WalletResponse
 // @Failure 404 {object} map[string]string
 // @Router /wallet/{user_id} [get]
@@ -42,7 +45,17 @@ func (h *WalletHandler) GetWallet(c *gin.Context) {
 		return
 	}
 
-	wallet, err := h.service.GetWallet(uint(userID))
+	var wallet *models.Wallet
+	if currencyParam := c.Query("currency_id"); currencyParam != "" {
+		currencyID, err := strconv.ParseUint(currencyParam, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid currency_id"})
+			return
+		}
+		wallet, err = h.service.GetWalletByCurrency(uint(userID), uint(currencyID))
+	} else {
+		wallet, err = h.service.GetWallet(uint(userID))
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "wallet not found"})
 		return
@@ -52,3 +65,69 @@ func (h *WalletHandler) GetWallet(c *gin.Context) {
 	response := models.ToWalletResponse(wallet)
 	c.JSON(http.StatusOK, response)
 }
+
+// GetWallets 查詢目前登入用戶所有幣種的錢包
+//
+// @Summary List wallets
+// @Description List every wallet (one per currency) of the authenticated user
+// @Tags Wallet
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.WalletWithCurrencyResponse
+// @Failure 401 {object} map[string]string
+// @Router /wallets [get]
+func (h *WalletHandler) GetWallets(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	wallets, err := h.service.GetWallets(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch wallets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToWalletWithCurrencyResponses(wallets))
+}
+
+// OpenWallet 為目前登入用戶開立新幣種的錢包
+//
+// @Summary Open wallet
+// @Description Open a wallet in a new active currency for the authenticated user
+// @Tags Wallet
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param wallet body models.OpenWalletRequest true "Currency to open"
+// @Success 201 {object} models.WalletWithCurrencyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /wallets [post]
+func (h *WalletHandler) OpenWallet(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.OpenWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	wallet, err := h.service.OpenWallet(userID, req.CurrencyID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrWalletAlreadyExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": apperrors.ErrCodeWalletAlreadyExists})
+		case errors.Is(err, services.ErrCurrencyUnavailable):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeCurrencyNotFound})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open wallet"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.ToWalletWithCurrencyResponse(wallet))
+}
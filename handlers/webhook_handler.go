@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"errors"
+	apperrors "mini-crypto-wallet-api/internal/errors"
+	"mini-crypto-wallet-api/middleware"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	service *services.WebhookService
+}
+
+func NewWebhookHandler(service *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service}
+}
+
+// Create 註冊 webhook endpoint
+//
+// @Summary Create webhook
+// @Description Register a URL that receives signed POST callbacks for the chosen event types. The secret used to verify X-Webhook-Signature is only returned here.
+// @Tags Webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.CreateWebhookRequest true "Webhook endpoint"
+// @Success 201 {object} models.WebhookEndpointResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /webhooks [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidRequest})
+		return
+	}
+
+	endpoint, err := h.service.CreateEndpoint(userID, &req)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	resp := models.ToWebhookEndpointResponse(endpoint)
+	resp.Secret = endpoint.Secret
+	c.JSON(http.StatusCreated, resp)
+}
+
+// List 取得目前用戶的 webhook endpoint
+//
+// @Summary List webhooks
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.WebhookEndpointResponse
+// @Router /webhooks [get]
+func (h *WebhookHandler) List(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return
+	}
+
+	endpoints, err := h.service.ListEndpoints(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToWebhookEndpointResponses(endpoints))
+}
+
+// Get 取得一個 webhook endpoint
+//
+// @Summary Get webhook
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} models.WebhookEndpointResponse
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) Get(c *gin.Context) {
+	userID, id, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.service.GetEndpoint(userID, id)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToWebhookEndpointResponse(endpoint))
+}
+
+// Update 修改 webhook endpoint
+//
+// @Summary Update webhook
+// @Description Change the URL or subscribed event types, or set active to false to pause deliveries
+// @Tags Webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param request body models.UpdateWebhookRequest true "Changes"
+// @Success 200 {object} models.WebhookEndpointResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id} [patch]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	userID, id, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidRequest})
+		return
+	}
+
+	endpoint, err := h.service.UpdateEndpoint(userID, id, &req)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToWebhookEndpointResponse(endpoint))
+}
+
+// Delete 刪除 webhook endpoint 與它的投遞紀錄
+//
+// @Summary Delete webhook
+// @Tags Webhooks
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	userID, id, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteEndpoint(userID, id); err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries 取得 endpoint 的投遞紀錄
+//
+// @Summary List webhook deliveries
+// @Description Delivery log of an endpoint, newest first, with the payload, attempts and the last response status or error
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	userID, id, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+	pagination := bindPagination(c)
+
+	deliveries, total, err := h.service.ListDeliveries(userID, id, pagination.GetOffset(), pagination.GetLimit())
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       models.ToWebhookDeliveryResponses(deliveries),
+		"pagination": newPaginationResponse(pagination, total),
+	})
+}
+
+// Redeliver 將一筆紀錄重新排入投遞佇列
+//
+// @Summary Redeliver webhook
+// @Description Queue a delivery to be sent again with the same body and event id on the next dispatcher run. Attempts start over and failures are retried with backoff like a new delivery.
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param delivery_id path int true "Delivery ID"
+// @Success 202 {object} models.WebhookDeliveryResponse
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	userID, id, ok := webhookParams(c)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseUint(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery id"})
+		return
+	}
+
+	delivery, err := h.service.Redeliver(userID, id, uint(deliveryID))
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.ToWebhookDeliveryResponse(delivery))
+}
+
+func webhookParams(c *gin.Context) (uint, uint, bool) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return 0, 0, false
+	}
+	return userID, uint(id), true
+}
+
+// respondWebhookError 將 webhook 錯誤轉成 HTTP 回應
+func respondWebhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeWebhookNotFound})
+	case errors.Is(err, services.ErrWebhookDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": apperrors.ErrCodeWebhookDeliveryNotFound})
+	case errors.Is(err, services.ErrInvalidWebhookURL):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": apperrors.ErrCodeInvalidWebhookURL})
+	case errors.Is(err, services.ErrWebhookLimitReached):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": apperrors.ErrCodeWebhookLimitReached})
+	case errors.Is(err, services.ErrWebhookDeliveryInProgress):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": apperrors.ErrCodeWebhookDeliveryInProgress})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process webhook request"})
+	}
+}
@@ -1,7 +1,10 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,15 +15,34 @@ var (
 	ErrExpiredToken = errors.New("token expired")
 )
 
+const (
+	// DefaultAccessTokenDuration access token 有效期（短效，搭配 refresh token 使用）
+	DefaultAccessTokenDuration = 15 * time.Minute
+	// DefaultRefreshTokenDuration refresh token 有效期
+	DefaultRefreshTokenDuration = 30 * 24 * time.Hour
+)
+
 type JWTClaims struct {
 	UserID   uint   `json:"user_id"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
 	jwt.RegisteredClaims
 }
 
+// IssuedToken 簽發的 access token 與其 jti、到期時間
+type IssuedToken struct {
+	Token     string
+	JTI       string
+	ExpiresAt time.Time
+}
+
+// JWTManager 簽發與驗證 access token
+// 以共享密鑰建立時使用 HS256；以 SigningKey 建立時使用 RS256 / EdDSA，並在 header 帶上 kid
 type JWTManager struct {
 	secretKey     string
 	tokenDuration time.Duration
+	keys          map[string]*SigningKey
+	activeKID     string
 }
 
 func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
@@ -30,28 +52,76 @@ func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
 	}
 }
 
-func (m *JWTManager) GenerateToken(userID uint, username string) (string, error) {
+// NewKeyedJWTManager 以多把非對稱金鑰建立 JWTManager
+// 只有 activeKID 用於簽發；其他金鑰（可只有公鑰）用於驗證輪替前簽發、尚未過期的 token
+func NewKeyedJWTManager(keys []*SigningKey, activeKID string, tokenDuration time.Duration) (*JWTManager, error) {
+	keyMap := make(map[string]*SigningKey, len(keys))
+	for _, key := range keys {
+		if _, exists := keyMap[key.KID]; exists {
+			return nil, fmt.Errorf("duplicate signing key id %q", key.KID)
+		}
+		keyMap[key.KID] = key
+	}
+
+	active, ok := keyMap[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("active signing key %q not found", activeKID)
+	}
+	if active.PrivateKey == nil {
+		return nil, fmt.Errorf("active signing key %q has no private key", activeKID)
+	}
+
+	return &JWTManager{
+		tokenDuration: tokenDuration,
+		keys:          keyMap,
+		activeKID:     activeKID,
+	}, nil
+}
+
+// TokenDuration 回傳 access token 的有效期
+func (m *JWTManager) TokenDuration() time.Duration {
+	return m.tokenDuration
+}
+
+func (m *JWTManager) GenerateToken(userID uint, username string, role string) (string, error) {
+	issued, err := m.IssueToken(userID, username, role)
+	if err != nil {
+		return "", err
+	}
+	return issued.Token, nil
+}
+
+// IssueToken 簽發帶有唯一 jti 的 access token，jti 用於登出後的撤銷
+func (m *JWTManager) IssueToken(userID uint, username string, role string) (*IssuedToken, error) {
+	jti, err := NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(m.tokenDuration)
 	claims := JWTClaims{
 		UserID:   userID,
 		Username: username,
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.tokenDuration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(m.secretKey))
+	signed, err := m.sign(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IssuedToken{Token: signed, JTI: jti, ExpiresAt: expiresAt}, nil
 }
 
 func (m *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrInvalidToken
-		}
-		return []byte(m.secretKey), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, m.verificationKey)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -67,3 +137,40 @@ func (m *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
 
 	return claims, nil
 }
+
+func (m *JWTManager) sign(claims JWTClaims) (string, error) {
+	if len(m.keys) == 0 {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(m.secretKey))
+	}
+
+	active := m.keys[m.activeKID]
+	token := jwt.NewWithClaims(active.Method, claims)
+	token.Header["kid"] = active.KID
+	return token.SignedString(active.PrivateKey)
+}
+
+// verificationKey 依 token header 的 kid 找出驗證用的金鑰，演算法必須與金鑰相符
+func (m *JWTManager) verificationKey(token *jwt.Token) (interface{}, error) {
+	if len(m.keys) == 0 {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return []byte(m.secretKey), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := m.keys[kid]
+	if !ok || token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrInvalidToken
+	}
+	return key.PublicKey, nil
+}
+
+// NewTokenID 產生 128-bit 隨機識別碼（hex）
+func NewTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func newEd25519Key(t *testing.T, kid string) *SigningKey {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	return &SigningKey{KID: kid, Method: jwt.SigningMethodEdDSA, PrivateKey: priv, PublicKey: pub}
+}
+
+// TestKeyedJWTManager_RotationKeepsOldTokensValid verifies tokens signed by a retired key still validate
+func TestKeyedJWTManager_RotationKeepsOldTokensValid(t *testing.T) {
+	oldKey := newEd25519Key(t, "2024-01")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	newKey := &SigningKey{KID: "2024-06", Method: jwt.SigningMethodRS256, PrivateKey: rsaKey, PublicKey: &rsaKey.PublicKey}
+
+	before, err := NewKeyedJWTManager([]*SigningKey{oldKey}, "2024-01", time.Minute)
+	assert.NoError(t, err)
+	oldToken, err := before.IssueToken(1, "alice", "user")
+	assert.NoError(t, err)
+
+	// 輪替後舊金鑰只保留公鑰
+	retired := &SigningKey{KID: oldKey.KID, Method: oldKey.Method, PublicKey: oldKey.PublicKey}
+	after, err := NewKeyedJWTManager([]*SigningKey{retired, newKey}, "2024-06", time.Minute)
+	assert.NoError(t, err)
+
+	claims, err := after.ValidateToken(oldToken.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), claims.UserID)
+
+	newToken, err := after.IssueToken(2, "bob", "user")
+	assert.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken.Token, &JWTClaims{})
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-06", parsed.Header["kid"])
+	assert.Equal(t, "RS256", parsed.Header["alg"])
+
+	// 只認得舊金鑰的 manager 不接受未知的 kid
+	_, err = before.ValidateToken(newToken.Token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	jwks := after.JWKS()
+	if assert.Len(t, jwks.Keys, 2) {
+		assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+		assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+		assert.Equal(t, "AQAB", jwks.Keys[1].E)
+	}
+
+	_, err = NewKeyedJWTManager([]*SigningKey{retired}, "2024-01", time.Minute)
+	assert.Error(t, err, "active key must have a private key")
+}
+
+// TestKeyedJWTManager_RejectsAlgorithmConfusion verifies an HS256 token cannot pose as a keyed token
+func TestKeyedJWTManager_RejectsAlgorithmConfusion(t *testing.T) {
+	key := newEd25519Key(t, "k1")
+	manager, err := NewKeyedJWTManager([]*SigningKey{key}, "k1", time.Minute)
+	assert.NoError(t, err)
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, JWTClaims{UserID: 1})
+	forged.Header["kid"] = "k1"
+	signed, err := forged.SignedString([]byte(key.PublicKey.(ed25519.PublicKey)))
+	assert.NoError(t, err)
+
+	_, err = manager.ValidateToken(signed)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	assert.Empty(t, NewJWTManager("secret-secret-secret-secret-secret", time.Minute).JWKS().Keys)
+}
+
+// TestNewJWTManagerFromOptions_RefusesDefaultSecretOutsideDevelopment verifies the startup guard
+func TestNewJWTManagerFromOptions_RefusesDefaultSecretOutsideDevelopment(t *testing.T) {
+	_, err := NewJWTManagerFromOptions(Options{AppEnv: "production"})
+	assert.ErrorIs(t, err, ErrInsecureSecret)
+
+	_, err = NewJWTManagerFromOptions(Options{AppEnv: "production", Secret: developmentSecret})
+	assert.ErrorIs(t, err, ErrInsecureSecret)
+
+	_, err = NewJWTManagerFromOptions(Options{AppEnv: "production", Secret: "too-short"})
+	assert.ErrorIs(t, err, ErrInsecureSecret)
+
+	manager, err := NewJWTManagerFromOptions(Options{AppEnv: "development", TokenDuration: time.Minute})
+	assert.NoError(t, err)
+	token, err := manager.GenerateToken(1, "alice", "user")
+	assert.NoError(t, err)
+	_, err = manager.ValidateToken(token)
+	assert.NoError(t, err)
+}
+
+// TestLoadSigningKeys_FromDirectory verifies PEM keys are loaded with the file name as kid
+func TestLoadSigningKeys_FromDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "current.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "previous.pub.pem"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o644))
+
+	manager, err := NewJWTManagerFromOptions(Options{AppEnv: "production", KeysDir: dir, ActiveKID: "current", TokenDuration: time.Minute})
+	assert.NoError(t, err)
+
+	jwks := manager.JWKS()
+	if assert.Len(t, jwks.Keys, 2) {
+		assert.Equal(t, "current", jwks.Keys[0].Kid)
+		assert.Equal(t, "EdDSA", jwks.Keys[0].Alg)
+		assert.Equal(t, "previous", jwks.Keys[1].Kid)
+	}
+
+	_, err = NewJWTManagerFromOptions(Options{AppEnv: "production", KeysDir: dir, ActiveKID: "previous"})
+	assert.Error(t, err)
+}
+
+// TestLoadSigningKeys_RejectsDuplicateKID verifies a private key and a public key cannot share a kid
+func TestLoadSigningKeys_RejectsDuplicateKID(t *testing.T) {
+	dir := t.TempDir()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "k1.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "k1.pub.pem"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o644))
+
+	_, err = LoadSigningKeys(dir)
+	assert.ErrorContains(t, err, `signing key "k1"`)
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// developmentSecret 只允許在 development 環境使用的預設密鑰
+	developmentSecret = "default-secret-key-change-in-production-min-32-chars"
+	minSecretLength   = 32
+	minRSAKeyBits     = 2048
+)
+
+var ErrInsecureSecret = errors.New("jwt_secret is empty, a known default or shorter than 32 characters")
+
+// insecureSecrets 範例設定中出現過的密鑰，不允許在正式環境使用
+var insecureSecrets = map[string]bool{
+	developmentSecret: true,
+	"your-secret-key-change-in-production-min-32-chars": true,
+}
+
+// SigningKey 一把以 kid 識別的非對稱簽章金鑰
+// PrivateKey 為 nil 時只能用於驗證（已輪替下來的舊金鑰）
+type SigningKey struct {
+	KID        string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// Options 建立 JWTManager 所需的設定
+type Options struct {
+	AppEnv        string
+	Secret        string
+	KeysDir       string // 有設定時改用目錄中的 RS256 / EdDSA 金鑰
+	ActiveKID     string
+	TokenDuration time.Duration
+}
+
+// NewJWTManagerFromOptions 依設定建立 JWTManager
+// 非 development 環境拒絕使用空白、預設或過短的 HS256 密鑰
+func NewJWTManagerFromOptions(opts Options) (*JWTManager, error) {
+	if opts.KeysDir != "" {
+		keys, err := LoadSigningKeys(opts.KeysDir)
+		if err != nil {
+			return nil, err
+		}
+		activeKID := opts.ActiveKID
+		if activeKID == "" && len(keys) == 1 {
+			activeKID = keys[0].KID
+		}
+		return NewKeyedJWTManager(keys, activeKID, opts.TokenDuration)
+	}
+
+	secret := opts.Secret
+	if secret == "" || insecureSecrets[secret] || len(secret) < minSecretLength {
+		if opts.AppEnv != "development" {
+			return nil, ErrInsecureSecret
+		}
+		if secret == "" {
+			secret = developmentSecret
+		}
+		log.Println("⚠️ Using an insecure JWT secret, only acceptable in development")
+	}
+	return NewJWTManager(secret, opts.TokenDuration), nil
+}
+
+// LoadSigningKeys 讀取目錄中所有 PEM 金鑰，檔名（去掉 .pem / .pub.pem）即為 kid
+// 私鑰支援 PKCS#8 與 PKCS#1 (RSA)，公鑰為 PKIX
+// k1.pem 與 k1.pub.pem 會得到相同的 kid，這時回傳錯誤，避免只能驗證的舊金鑰蓋掉簽章金鑰
+func LoadSigningKeys(dir string) ([]*SigningKey, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no signing keys found in %s", dir)
+	}
+	sort.Strings(paths)
+
+	keys := make([]*SigningKey, 0, len(paths))
+	seen := make(map[string]string, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		kid := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".pem"), ".pub")
+		if previous, ok := seen[kid]; ok {
+			return nil, fmt.Errorf("%s and %s both define signing key %q", previous, path, kid)
+		}
+		seen[kid] = path
+		key, err := ParseSigningKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// ParseSigningKey 解析單一 PEM 金鑰，依金鑰型別決定演算法（RSA → RS256，Ed25519 → EdDSA）
+func ParseSigningKey(kid string, pemData []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{KID: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.PrivateKey, key.PublicKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.PublicKey = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.PrivateKey, key.PublicKey = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.PublicKey = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	if rsaKey, ok := key.PublicKey.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+	}
+	return key, nil
+}
+
+// JWK JSON Web Key（RFC 7517），只包含公鑰
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet /.well-known/jwks.json 的回應格式
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 回傳所有驗證用公鑰；HS256 模式下為空集合，避免洩漏共享密鑰
+func (m *JWTManager) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range m.keys {
+		jwk := JWK{Use: "sig", Alg: key.Method.Alg(), Kid: key.KID}
+		switch pub := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
@@ -1,12 +1,31 @@
 package config
 
 type AppConfig struct {
-	AppEnv      string `mapstructure:"app_env"`
-	DBDriver    string `mapstructure:"db_driver"`
-	PostgresDSN string `mapstructure:"postgres_dsn"`
-	KafkaBroker string `mapstructure:"kafka_broker"`
-	JWTSecret   string `mapstructure:"jwt_secret"`
-	RedisAddr   string `mapstructure:"redis_addr"`
+	AppEnv       string `mapstructure:"app_env"`
+	DBDriver     string `mapstructure:"db_driver"`
+	PostgresDSN  string `mapstructure:"postgres_dsn"`
+	KafkaBroker  string `mapstructure:"kafka_broker"`
+	EventBus     string `mapstructure:"event_bus"` // kafka (default), log or noop; log and noop drop events and are only allowed in development
+	JWTSecret    string `mapstructure:"jwt_secret"`
+	JWTKeysDir   string `mapstructure:"jwt_keys_dir"`   // Directory of <kid>.pem keys for RS256/EdDSA; overrides jwt_secret
+	JWTActiveKID string `mapstructure:"jwt_active_kid"` // kid used to sign new tokens
+	RedisAddr    string `mapstructure:"redis_addr"`
+
+	TxSigningKeysDir   string `mapstructure:"tx_signing_keys_dir"`   // Directory of <kid>.pem Ed25519 keys used to sign transactions
+	TxSigningActiveKID string `mapstructure:"tx_signing_active_kid"` // kid used to sign new transactions
+
+	BootstrapAdmin            string `mapstructure:"bootstrap_admin"`             // Username promoted to admin at startup
+	ReconciliationInterval    string `mapstructure:"reconciliation_interval"`     // e.g. 1h; empty disables the scheduled run
+	AmountPrecisionPolicy     string `mapstructure:"amount_precision_policy"`     // reject (default) or round amounts finer than the currency's decimals
+	RatesFile                 string `mapstructure:"rates_file"`                  // JSON file of exchange rates, e.g. {"BTC/USDT": "65000"}; empty disables swaps
+	SwapSpreadBps             int    `mapstructure:"swap_spread_bps"`             // Spread fee charged on swaps in basis points (default 30)
+	SwapQuoteTTL              string `mapstructure:"swap_quote_ttl"`              // How long a swap quote stays valid, e.g. 30s
+	ScheduledTransferInterval string `mapstructure:"scheduled_transfer_interval"` // How often due scheduled transfers are run, e.g. 1m; empty disables the worker
+	AuditCheckpointInterval   string `mapstructure:"audit_checkpoint_interval"`   // How often a Merkle checkpoint of the balance history chains is taken, e.g. 24h; empty disables it
+	NotificationConsumerGroup string `mapstructure:"notification_consumer_group"` // Kafka consumer group that turns tx.created events into notifications; empty disables it
+	WebhookConsumerGroup      string `mapstructure:"webhook_consumer_group"`      // Kafka consumer group that queues webhook deliveries from tx events; empty disables it
+	WebhookDeliveryInterval   string `mapstructure:"webhook_delivery_interval"`   // How often due webhook deliveries are sent, e.g. 5s; empty disables the worker
+	StreamHeartbeatInterval   string `mapstructure:"stream_heartbeat_interval"`   // Idle heartbeat on GET /stream, e.g. 15s (default)
 }
 
 var Config *AppConfig
@@ -13,14 +13,80 @@ const (
 	ErrCodeUserNotFound       = "USER_NOT_FOUND"
 	ErrCodeUserAlreadyExists  = "USER_ALREADY_EXISTS"
 	ErrCodeInvalidCredentials = "INVALID_CREDENTIALS"
+	ErrCodeAccountFrozen      = "ACCOUNT_FROZEN"
+	ErrCodeInvalidRole        = "INVALID_ROLE"
+
+	// 認證相關錯誤
+	ErrCodeInvalidRefreshToken = "INVALID_REFRESH_TOKEN"
+	ErrCodeRefreshTokenReused  = "REFRESH_TOKEN_REUSED"
+	ErrCodeTokenRevoked        = "TOKEN_REVOKED"
 
 	// 錢包相關錯誤
 	ErrCodeWalletNotFound      = "WALLET_NOT_FOUND"
 	ErrCodeInsufficientBalance = "INSUFFICIENT_BALANCE"
 	ErrCodeInvalidAmount       = "INVALID_AMOUNT"
+	ErrCodeWalletAlreadyExists = "WALLET_ALREADY_EXISTS"
+
+	// 幣種相關錯誤
+	ErrCodeCurrencyNotFound      = "CURRENCY_NOT_FOUND"
+	ErrCodeCurrencyAlreadyExists = "CURRENCY_ALREADY_EXISTS"
+	ErrCodeCurrencyInactive      = "CURRENCY_INACTIVE"
+	ErrCodePrecisionConflict     = "PRECISION_CONFLICT"
 
 	// 交易相關錯誤
 	ErrCodeTransactionNotFound = "TRANSACTION_NOT_FOUND"
 	ErrCodeSameAccountTransfer = "SAME_ACCOUNT_TRANSFER"
 	ErrCodeTransactionFailed   = "TRANSACTION_FAILED"
+
+	// 沖正 / 退款相關錯誤
+	ErrCodeTransactionNotReversible   = "TRANSACTION_NOT_REVERSIBLE"
+	ErrCodeTransactionAlreadyReversed = "TRANSACTION_ALREADY_REVERSED"
+	ErrCodeRefundExceedsRemaining     = "REFUND_EXCEEDS_REMAINING"
+	ErrCodeNotTransferRecipient       = "NOT_TRANSFER_RECIPIENT"
+
+	// 轉帳限額相關錯誤
+	ErrCodeSingleTransferLimitExceeded = "SINGLE_TRANSFER_LIMIT_EXCEEDED"
+	ErrCodeDailyLimitExceeded          = "DAILY_LIMIT_EXCEEDED"
+	ErrCodeMonthlyLimitExceeded        = "MONTHLY_LIMIT_EXCEEDED"
+	ErrCodeVelocityLimitExceeded       = "VELOCITY_LIMIT_EXCEEDED"
+	ErrCodeTransferLimitNotFound       = "TRANSFER_LIMIT_NOT_FOUND"
+	ErrCodeInvalidTransferLimit        = "INVALID_TRANSFER_LIMIT"
+
+	// 預約轉帳相關錯誤
+	ErrCodeScheduledTransferNotFound = "SCHEDULED_TRANSFER_NOT_FOUND"
+	ErrCodeScheduledTransferClosed   = "SCHEDULED_TRANSFER_CLOSED"
+
+	// 手續費相關錯誤
+	ErrCodeFeeScheduleNotFound = "FEE_SCHEDULE_NOT_FOUND"
+	ErrCodeInvalidFeeSchedule  = "INVALID_FEE_SCHEDULE"
+
+	// 兌換相關錯誤
+	ErrCodeRateUnavailable  = "RATE_UNAVAILABLE"
+	ErrCodeQuoteNotFound    = "QUOTE_NOT_FOUND"
+	ErrCodeQuoteExpired     = "QUOTE_EXPIRED"
+	ErrCodeQuoteUsed        = "QUOTE_ALREADY_EXECUTED"
+	ErrCodeSlippageExceeded = "SLIPPAGE_EXCEEDED"
+
+	// 入金 / 出金相關錯誤
+	ErrCodeInvalidStatusTransition = "INVALID_STATUS_TRANSITION"
+
+	// Idempotency 相關錯誤
+	ErrCodeInvalidIdempotencyKey  = "INVALID_IDEMPOTENCY_KEY"
+	ErrCodeIdempotencyKeyMismatch = "IDEMPOTENCY_KEY_MISMATCH"
+
+	// 對帳相關錯誤
+	ErrCodeReconciliationRunNotFound = "RECONCILIATION_RUN_NOT_FOUND"
+
+	// 稽核 hash chain 相關錯誤
+	ErrCodeAuditCheckpointNotFound = "AUDIT_CHECKPOINT_NOT_FOUND"
+
+	// 通知相關錯誤
+	ErrCodeNotificationNotFound = "NOTIFICATION_NOT_FOUND"
+
+	// Webhook 相關錯誤
+	ErrCodeWebhookNotFound           = "WEBHOOK_NOT_FOUND"
+	ErrCodeWebhookDeliveryNotFound   = "WEBHOOK_DELIVERY_NOT_FOUND"
+	ErrCodeInvalidWebhookURL         = "INVALID_WEBHOOK_URL"
+	ErrCodeWebhookLimitReached       = "WEBHOOK_LIMIT_REACHED"
+	ErrCodeWebhookDeliveryInProgress = "WEBHOOK_DELIVERY_IN_PROGRESS"
 )
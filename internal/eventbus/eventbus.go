@@ -0,0 +1,46 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"mini-crypto-wallet-api/kafka_client"
+)
+
+// Backends selectable with the event_bus config key
+const (
+	BackendKafka = "kafka"
+	BackendLog   = "log"
+	BackendNoop  = "noop"
+)
+
+// EventPublisher 將事件送到訊息系統
+// outbox relay 與 consumer 的死信 topic 透過此介面發送，backend 由設定決定
+type EventPublisher interface {
+	Publish(ctx context.Context, topic string, key string, value []byte) error
+	Close() error
+}
+
+// Config 建立 EventPublisher 所需的設定
+type Config struct {
+	Backend     string // kafka (default), log or noop
+	KafkaBroker string
+}
+
+// New 依 backend 建立 EventPublisher；in-memory backend 只供測試使用，請直接呼叫 NewMemoryPublisher
+func New(cfg Config) (EventPublisher, error) {
+	switch cfg.Backend {
+	case "", BackendKafka:
+		return kafka_client.NewKafkaProducer(cfg.KafkaBroker, kafka_client.TopicTxCreated), nil
+	case BackendLog:
+		return NewLogPublisher(), nil
+	case BackendNoop:
+		return NoopPublisher{}, nil
+	default:
+		return nil, fmt.Errorf("unknown event bus backend %q (expected kafka, log or noop)", cfg.Backend)
+	}
+}
+
+// IsDurable 回傳 backend 是否真的把事件送出；log 與 noop 會丟棄事件
+func IsDurable(backend string) bool {
+	return backend == "" || backend == BackendKafka
+}
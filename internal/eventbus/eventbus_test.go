@@ -0,0 +1,53 @@
+package eventbus
+
+import (
+	"context"
+	"mini-crypto-wallet-api/kafka_client"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_SelectsBackend(t *testing.T) {
+	// Creating the Kafka backend must not dial the broker
+	publisher, err := New(Config{KafkaBroker: "127.0.0.1:1"})
+	assert.NoError(t, err)
+	assert.IsType(t, &kafka_client.KafkaProducer{}, publisher)
+	assert.NoError(t, publisher.Close())
+
+	publisher, err = New(Config{Backend: BackendLog})
+	assert.NoError(t, err)
+	assert.IsType(t, &LogPublisher{}, publisher)
+
+	publisher, err = New(Config{Backend: BackendNoop})
+	assert.NoError(t, err)
+	assert.NoError(t, publisher.Publish(context.Background(), "tx.created", "k", []byte("{}")))
+
+	_, err = New(Config{Backend: "rabbitmq"})
+	assert.Error(t, err)
+}
+
+func TestIsDurable(t *testing.T) {
+	assert.True(t, IsDurable(""))
+	assert.True(t, IsDurable(BackendKafka))
+	assert.False(t, IsDurable(BackendLog))
+	assert.False(t, IsDurable(BackendNoop))
+}
+
+func TestMemoryPublisher_RecordsByTopic(t *testing.T) {
+	p := NewMemoryPublisher()
+	value := []byte(`{"a":1}`)
+	assert.NoError(t, p.Publish(context.Background(), "tx.created", "h1", value))
+	assert.NoError(t, p.Publish(context.Background(), "user.events", "1", []byte(`{}`)))
+	assert.NoError(t, p.Publish(context.Background(), "tx.created", "h2", []byte(`{}`)))
+	value[0] = 'x'
+
+	assert.Len(t, p.Messages(), 3)
+	txCreated := p.MessagesForTopic("tx.created")
+	assert.Len(t, txCreated, 2)
+	assert.Equal(t, "h1", txCreated[0].Key)
+	assert.Equal(t, `{"a":1}`, string(txCreated[0].Value), "published values are copied")
+
+	p.Reset()
+	assert.Empty(t, p.Messages())
+}
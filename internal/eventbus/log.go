@@ -0,0 +1,35 @@
+package eventbus
+
+import (
+	"context"
+	"log"
+)
+
+// LogPublisher 只把事件寫到 log，用於沒有 Kafka 的本機開發
+type LogPublisher struct {
+	logger *log.Logger
+}
+
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{logger: log.Default()}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	p.logger.Printf("📭 Event bus (log): %s key=%s %s", topic, key, value)
+	return nil
+}
+
+func (p *LogPublisher) Close() error {
+	return nil
+}
+
+// NoopPublisher 直接丟棄所有事件
+type NoopPublisher struct{}
+
+func (NoopPublisher) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	return nil
+}
+
+func (NoopPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,61 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+)
+
+// Message 一筆已發送的事件
+type Message struct {
+	Topic string
+	Key   string
+	Value []byte
+}
+
+// MemoryPublisher 將事件保存在記憶體中，供測試檢查發送了哪些事件
+type MemoryPublisher struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, Message{Topic: topic, Key: key, Value: append([]byte(nil), value...)})
+	return nil
+}
+
+func (p *MemoryPublisher) Close() error {
+	return nil
+}
+
+// Messages 回傳目前為止發送的所有事件（依發送順序）
+func (p *MemoryPublisher) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Message(nil), p.messages...)
+}
+
+// MessagesForTopic 回傳發送到 topic 的事件（依發送順序）
+func (p *MemoryPublisher) MessagesForTopic(topic string) []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var out []Message
+	for _, msg := range p.messages {
+		if msg.Topic == topic {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+// Reset 清除已保存的事件
+func (p *MemoryPublisher) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = nil
+}
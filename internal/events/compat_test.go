@@ -0,0 +1,109 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The files in testdata are what consumers built against each schema version rely on.
+// They are written by hand and never regenerated: a change that breaks one of these tests
+// would break an older consumer, so it needs a new schema version instead.
+
+func loadFixture(t *testing.T, eventType string, version int) []byte {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", fmt.Sprintf("%s.v%d.json", eventType, version)))
+	if err != nil {
+		t.Fatalf("missing fixture for %s v%d: %v", eventType, version, err)
+	}
+	return raw
+}
+
+// assertSuperset checks every field of old is still present in current with the same JSON kind
+func assertSuperset(t *testing.T, path string, old, current interface{}) {
+	t.Helper()
+	switch o := old.(type) {
+	case map[string]interface{}:
+		c, ok := current.(map[string]interface{})
+		if !assert.Truef(t, ok, "%s: expected an object, got %T", path, current) {
+			return
+		}
+		for key, value := range o {
+			next, ok := c[key]
+			if !assert.Truef(t, ok, "%s.%s was removed or renamed", path, key) {
+				continue
+			}
+			assertSuperset(t, path+"."+key, value, next)
+		}
+	default:
+		assert.Equalf(t, reflect.TypeOf(old), reflect.TypeOf(current), "%s changed its JSON type", path)
+	}
+}
+
+func toJSONValue(t *testing.T, v interface{}) interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	assert.NoError(t, err)
+	var out interface{}
+	assert.NoError(t, json.Unmarshal(raw, &out))
+	return out
+}
+
+// TestCompat_FixturesStillDecode verifies every published schema version is still readable
+// and that re-encoding with the current payload keeps every field older consumers read
+func TestCompat_FixturesStillDecode(t *testing.T) {
+	for _, eventType := range Types() {
+		current, _ := SchemaVersion(eventType)
+		for version := 1; version <= current; version++ {
+			t.Run(fmt.Sprintf("%s/v%d", eventType, version), func(t *testing.T) {
+				raw := loadFixture(t, eventType, version)
+
+				var fixture map[string]interface{}
+				assert.NoError(t, json.Unmarshal(raw, &fixture))
+
+				e, err := Parse(raw)
+				if !assert.NoError(t, err) {
+					return
+				}
+				payload, err := e.Decode()
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				assertSuperset(t, "data", fixture["data"], toJSONValue(t, payload))
+			})
+		}
+	}
+}
+
+// TestCompat_NewEnvelopeKeepsFixtureFields verifies envelopes produced today carry every envelope
+// and payload field of the current fixture
+func TestCompat_NewEnvelopeKeepsFixtureFields(t *testing.T) {
+	for _, eventType := range Types() {
+		version, _ := SchemaVersion(eventType)
+		t.Run(eventType, func(t *testing.T) {
+			raw := loadFixture(t, eventType, version)
+			fixture, err := Parse(raw)
+			if !assert.NoError(t, err) {
+				return
+			}
+			payload, err := fixture.Decode()
+			assert.NoError(t, err)
+
+			e, err := New(eventType, payload, WithTraceID(fixture.TraceID), WithOccurredAt(fixture.OccurredAt))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, version, e.SchemaVersion)
+
+			var old interface{}
+			assert.NoError(t, json.Unmarshal(raw, &old))
+			assertSuperset(t, "envelope", old, toJSONValue(t, e))
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Producer identifies this service in every envelope it emits
+const Producer = "mini-crypto-wallet-api"
+
+var (
+	ErrNotEnvelope        = errors.New("message is not an event envelope")
+	ErrUnknownEventType   = errors.New("unknown event type")
+	ErrUnsupportedVersion = errors.New("unsupported event schema version")
+	ErrPayloadType        = errors.New("payload does not match the event type")
+)
+
+// Envelope 所有 Kafka 事件共用的外層結構
+// data 的格式由 type 與 schema_version 決定；新增欄位不升版，刪除、改名或改型別才升版
+type Envelope struct {
+	EventID       string          `json:"event_id"`
+	Type          string          `json:"type"`
+	SchemaVersion int             `json:"schema_version"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	TraceID       string          `json:"trace_id,omitempty"`
+	Producer      string          `json:"producer"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// Option 設定 New 建立的 envelope
+type Option func(*Envelope)
+
+// WithTraceID 帶上觸發事件的請求 trace ID
+func WithTraceID(traceID string) Option {
+	return func(e *Envelope) { e.TraceID = traceID }
+}
+
+// WithOccurredAt 以業務發生的時間取代建立 envelope 的時間
+func WithOccurredAt(t time.Time) Option {
+	return func(e *Envelope) {
+		if !t.IsZero() {
+			e.OccurredAt = t.UTC()
+		}
+	}
+}
+
+// New 以目前註冊的 schema 版本包裝 payload，payload 的型別必須與 eventType 註冊的一致
+func New(eventType string, payload interface{}, opts ...Option) (*Envelope, error) {
+	s, ok := lookup(eventType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, eventType)
+	}
+	if t := reflect.TypeOf(payload); t != s.payloadType && t != reflect.PtrTo(s.payloadType) {
+		return nil, fmt.Errorf("%w: %s expects %s, got %v", ErrPayloadType, eventType, s.payloadType, t)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Envelope{
+		EventID:       uuid.NewString(),
+		Type:          eventType,
+		SchemaVersion: s.version,
+		OccurredAt:    time.Now().UTC(),
+		Producer:      Producer,
+		Data:          data,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
+}
+
+// Parse 解析 Kafka 訊息內容
+// 沒有 event_id / type 的訊息（導入 envelope 前的舊格式）回傳 ErrNotEnvelope，
+// 比目前註冊的版本更新的 schema 回傳 ErrUnsupportedVersion
+func Parse(raw []byte) (*Envelope, error) {
+	var e Envelope
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return nil, err
+	}
+	if e.EventID == "" || e.Type == "" || len(e.Data) == 0 {
+		return nil, ErrNotEnvelope
+	}
+
+	s, ok := lookup(e.Type)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, e.Type)
+	}
+	if e.SchemaVersion < 1 || e.SchemaVersion > s.version {
+		return nil, fmt.Errorf("%w: %s v%d (supported up to v%d)", ErrUnsupportedVersion, e.Type, e.SchemaVersion, s.version)
+	}
+	return &e, nil
+}
+
+// Decode 依 type 解出對應的 payload（回傳指標，例如 *TransferPayload）
+func (e *Envelope) Decode() (interface{}, error) {
+	s, ok := lookup(e.Type)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, e.Type)
+	}
+	payload := reflect.New(s.payloadType).Interface()
+	if err := json.Unmarshal(e.Data, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// DecodeInto 將 data 解到 v，v 必須是 type 註冊的 payload 指標
+func (e *Envelope) DecodeInto(v interface{}) error {
+	s, ok := lookup(e.Type)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownEventType, e.Type)
+	}
+	if reflect.TypeOf(v) != reflect.PtrTo(s.payloadType) {
+		return fmt.Errorf("%w: %s expects *%s, got %T", ErrPayloadType, e.Type, s.payloadType, v)
+	}
+	return json.Unmarshal(e.Data, v)
+}
@@ -0,0 +1,78 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_RoundTrip(t *testing.T) {
+	createdAt := time.Date(2026, 10, 1, 8, 30, 0, 0, time.UTC)
+	payload := TransferPayload{
+		Hash:            "abc",
+		TransactionType: "transfer",
+		FromUserID:      1,
+		ToUserID:        2,
+		CurrencyID:      1,
+		Amount:          decimal.RequireFromString("100.5"),
+		Fee:             decimal.RequireFromString("0.5"),
+		Status:          "completed",
+		CreatedAt:       createdAt,
+	}
+
+	e, err := New(TypeTransferCompleted, payload, WithTraceID("trace-1"), WithOccurredAt(createdAt))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, e.EventID)
+	assert.Equal(t, 1, e.SchemaVersion)
+	assert.Equal(t, Producer, e.Producer)
+	assert.Equal(t, "trace-1", e.TraceID)
+	assert.True(t, createdAt.Equal(e.OccurredAt))
+
+	raw, err := json.Marshal(e)
+	assert.NoError(t, err)
+	parsed, err := Parse(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, e.EventID, parsed.EventID)
+
+	var decoded TransferPayload
+	assert.NoError(t, parsed.DecodeInto(&decoded))
+	assert.Equal(t, "100.5", decoded.Amount.String())
+	assert.Equal(t, uint(2), decoded.ToUserID)
+
+	any, err := parsed.Decode()
+	assert.NoError(t, err)
+	assert.IsType(t, &TransferPayload{}, any)
+}
+
+func TestNew_RejectsMismatchedPayload(t *testing.T) {
+	_, err := New(TypeTransferCompleted, UserPayload{UserID: 1})
+	assert.ErrorIs(t, err, ErrPayloadType)
+
+	_, err = New("transfer.exploded", TransferPayload{})
+	assert.ErrorIs(t, err, ErrUnknownEventType)
+}
+
+func TestParse_RejectsUnknownAndFutureVersions(t *testing.T) {
+	_, err := Parse([]byte(`{"hash":"abc","from_user_id":1,"to_user_id":2,"amount":"1"}`))
+	assert.ErrorIs(t, err, ErrNotEnvelope, "pre-envelope messages are reported as such")
+
+	_, err = Parse([]byte(`{"event_id":"1","type":"transfer.completed","schema_version":2,"data":{}}`))
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	_, err = Parse([]byte(`{"event_id":"1","type":"transfer.exploded","schema_version":1,"data":{}}`))
+	assert.ErrorIs(t, err, ErrUnknownEventType)
+
+	_, err = Parse([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestDecodeInto_RejectsWrongTarget(t *testing.T) {
+	e, err := New(TypeUserCreated, UserPayload{UserID: 1, Username: "carol", Role: "user"})
+	assert.NoError(t, err)
+
+	var wrong TransferPayload
+	assert.ErrorIs(t, e.DecodeInto(&wrong), ErrPayloadType)
+}
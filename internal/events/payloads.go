@@ -0,0 +1,56 @@
+package events
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TransferPayload is the data of transfer.completed, transfer.status_changed and swap.completed
+type TransferPayload struct {
+	Hash            string          `json:"hash"`
+	TransactionType string          `json:"transaction_type"` // transfer or swap
+	FromUserID      uint            `json:"from_user_id"`
+	ToUserID        uint            `json:"to_user_id"`
+	CurrencyID      uint            `json:"currency_id"`
+	Amount          decimal.Decimal `json:"amount"`
+	Fee             decimal.Decimal `json:"fee"`
+	PreviousStatus  string          `json:"previous_status,omitempty"` // Only on status changes
+	Status          string          `json:"status"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// ReversalPayload is the data of reversal.completed and refund.completed
+// Funds move from the original recipient (from_user_id) back to the original sender (to_user_id)
+type ReversalPayload struct {
+	Hash         string          `json:"hash"`
+	OriginalHash string          `json:"original_hash"`
+	FromUserID   uint            `json:"from_user_id"`
+	ToUserID     uint            `json:"to_user_id"`
+	CurrencyID   uint            `json:"currency_id"`
+	Amount       decimal.Decimal `json:"amount"`
+	Reason       string          `json:"reason,omitempty"`
+	Status       string          `json:"status"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// FundingPayload is the data of deposit.status_changed and withdrawal.status_changed
+type FundingPayload struct {
+	Hash           string          `json:"hash"`
+	UserID         uint            `json:"user_id"`
+	CurrencyID     uint            `json:"currency_id"`
+	Amount         decimal.Decimal `json:"amount"`
+	Reference      string          `json:"reference,omitempty"` // Deposit source or withdrawal address
+	PreviousStatus string          `json:"previous_status,omitempty"`
+	Status         string          `json:"status"`
+	FailReason     string          `json:"fail_reason,omitempty"`
+}
+
+// UserPayload is the data of the user.* events
+type UserPayload struct {
+	UserID       uint   `json:"user_id"`
+	Username     string `json:"username"`
+	Role         string `json:"role"`
+	Frozen       bool   `json:"frozen"`
+	FrozenReason string `json:"frozen_reason,omitempty"`
+}
@@ -0,0 +1,63 @@
+package events
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Event types
+const (
+	TypeTransferCompleted       = "transfer.completed"
+	TypeTransferStatusChanged   = "transfer.status_changed" // e.g. a completed transfer marked reversed
+	TypeSwapCompleted           = "swap.completed"
+	TypeReversalCompleted       = "reversal.completed"
+	TypeRefundCompleted         = "refund.completed"
+	TypeDepositStatusChanged    = "deposit.status_changed"
+	TypeWithdrawalStatusChanged = "withdrawal.status_changed"
+	TypeUserCreated             = "user.created"
+	TypeUserFrozen              = "user.frozen"
+	TypeUserUnfrozen            = "user.unfrozen"
+	TypeUserRoleChanged         = "user.role_changed"
+)
+
+type schema struct {
+	version     int
+	payloadType reflect.Type
+}
+
+// registry 每種事件目前的 schema 版本與 payload 型別
+// 升版時必須在 testdata 加入新版本的範例，舊版本的範例要保留，相容性測試會確認它們仍能被解析
+var registry = map[string]schema{
+	TypeTransferCompleted:       {1, reflect.TypeOf(TransferPayload{})},
+	TypeTransferStatusChanged:   {1, reflect.TypeOf(TransferPayload{})},
+	TypeSwapCompleted:           {1, reflect.TypeOf(TransferPayload{})},
+	TypeReversalCompleted:       {1, reflect.TypeOf(ReversalPayload{})},
+	TypeRefundCompleted:         {1, reflect.TypeOf(ReversalPayload{})},
+	TypeDepositStatusChanged:    {1, reflect.TypeOf(FundingPayload{})},
+	TypeWithdrawalStatusChanged: {1, reflect.TypeOf(FundingPayload{})},
+	TypeUserCreated:             {1, reflect.TypeOf(UserPayload{})},
+	TypeUserFrozen:              {1, reflect.TypeOf(UserPayload{})},
+	TypeUserUnfrozen:            {1, reflect.TypeOf(UserPayload{})},
+	TypeUserRoleChanged:         {1, reflect.TypeOf(UserPayload{})},
+}
+
+func lookup(eventType string) (schema, bool) {
+	s, ok := registry[eventType]
+	return s, ok
+}
+
+// SchemaVersion 回傳事件目前的 schema 版本
+func SchemaVersion(eventType string) (int, bool) {
+	s, ok := lookup(eventType)
+	return s.version, ok
+}
+
+// Types 回傳所有已註冊的事件類型（排序後）
+func Types() []string {
+	types := make([]string, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,52 @@
+package merkle
+
+import "crypto/sha256"
+
+// 葉節點與內部節點使用不同前綴（RFC 6962），避免內部節點被當成葉節點偽造
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// LeafHash 回傳單一葉節點的 hash
+func LeafHash(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// NodeHash 回傳兩個子節點合併後的 hash
+func NodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Root 依 RFC 6962 計算 Merkle root：葉節點依傳入順序排列，
+// 每層兩兩合併，落單的節點直接升到上一層；沒有葉節點時為空字串的 SHA256
+func Root(leaves [][]byte) []byte {
+	if len(leaves) == 0 {
+		sum := sha256.Sum256(nil)
+		return sum[:]
+	}
+
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = LeafHash(leaf)
+	}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, NodeHash(level[i], level[i+1]))
+		}
+		level = next
+	}
+	return level[0]
+}
@@ -0,0 +1,38 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoot_EmptyAndSingleLeaf(t *testing.T) {
+	empty := sha256.Sum256(nil)
+	assert.Equal(t, empty[:], Root(nil))
+	assert.Equal(t, LeafHash([]byte("a")), Root([][]byte{[]byte("a")}))
+}
+
+// TestRoot_OddLeafIsPromoted verifies the tree shape for an odd number of leaves
+func TestRoot_OddLeafIsPromoted(t *testing.T) {
+	a, b, c := []byte("a"), []byte("b"), []byte("c")
+	expected := NodeHash(NodeHash(LeafHash(a), LeafHash(b)), LeafHash(c))
+	assert.Equal(t, hex.EncodeToString(expected), hex.EncodeToString(Root([][]byte{a, b, c})))
+}
+
+// TestRoot_ChangesWithAnyLeafOrOrder verifies the root commits to every leaf and their order
+func TestRoot_ChangesWithAnyLeafOrOrder(t *testing.T) {
+	leaves := [][]byte{[]byte("1:3:aa"), []byte("2:1:bb"), []byte("5:9:cc"), []byte("7:2:dd")}
+	root := Root(leaves)
+
+	tampered := [][]byte{leaves[0], leaves[1], []byte("5:9:cd"), leaves[3]}
+	assert.NotEqual(t, root, Root(tampered))
+
+	swapped := [][]byte{leaves[1], leaves[0], leaves[2], leaves[3]}
+	assert.NotEqual(t, root, Root(swapped))
+
+	// 內部節點不能被當成葉節點重新組出相同的 root
+	forged := [][]byte{NodeHash(LeafHash(leaves[0]), LeafHash(leaves[1])), NodeHash(LeafHash(leaves[2]), LeafHash(leaves[3]))}
+	assert.NotEqual(t, root, Root(forged))
+}
@@ -0,0 +1,84 @@
+package rates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// inversePrecision 反向匯率（1 / rate）保留的小數位數
+const inversePrecision = 16
+
+var ErrRateUnavailable = errors.New("exchange rate unavailable")
+
+// Provider 提供幣種之間的中間價
+// Rate 回傳 1 單位 base 可換得的 quote 數量，base 與 quote 為幣種代碼
+type Provider interface {
+	Rate(base, quote string) (decimal.Decimal, error)
+}
+
+// StaticProvider 以固定的匯率表提供報價，用於測試與離線環境
+// 匯率以 "BASE/QUOTE" 為 key；只設定單一方向時，反向匯率自動以倒數計算
+type StaticProvider struct {
+	mu    sync.RWMutex
+	rates map[string]decimal.Decimal
+}
+
+func NewStaticProvider(rates map[string]decimal.Decimal) *StaticProvider {
+	normalized := make(map[string]decimal.Decimal, len(rates))
+	for pair, rate := range rates {
+		normalized[strings.ToUpper(pair)] = rate
+	}
+	return &StaticProvider{rates: normalized}
+}
+
+// LoadFileProvider 從 JSON 檔讀取匯率表，例如 {"BTC/USDT": "65000", "ETH/USDT": "3200.5"}
+func LoadFileProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]decimal.Decimal
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse rates file %s: %w", path, err)
+	}
+	for pair, rate := range raw {
+		if !strings.Contains(pair, "/") {
+			return nil, fmt.Errorf("rates file %s: pair %q must look like BASE/QUOTE", path, pair)
+		}
+		if !rate.IsPositive() {
+			return nil, fmt.Errorf("rates file %s: rate of %s must be positive", path, pair)
+		}
+	}
+	return NewStaticProvider(raw), nil
+}
+
+// Set 新增或更新一組匯率
+func (p *StaticProvider) Set(pair string, rate decimal.Decimal) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rates[strings.ToUpper(pair)] = rate
+}
+
+func (p *StaticProvider) Rate(base, quote string) (decimal.Decimal, error) {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	if base == quote {
+		return decimal.NewFromInt(1), nil
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if rate, ok := p.rates[base+"/"+quote]; ok && rate.IsPositive() {
+		return rate, nil
+	}
+	if rate, ok := p.rates[quote+"/"+base]; ok && rate.IsPositive() {
+		return decimal.NewFromInt(1).DivRound(rate, inversePrecision), nil
+	}
+	return decimal.Zero, fmt.Errorf("%w: %s/%s", ErrRateUnavailable, base, quote)
+}
@@ -0,0 +1,49 @@
+package rates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFileProvider_DirectAndInverseRates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"btc/usdt": "50000", "ETH/USDT": "2500"}`), 0o600))
+
+	provider, err := LoadFileProvider(path)
+	assert.NoError(t, err)
+
+	rate, err := provider.Rate("BTC", "USDT")
+	assert.NoError(t, err)
+	assert.Equal(t, "50000", rate.String())
+
+	rate, err = provider.Rate("USDT", "BTC")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.00002", rate.String())
+
+	rate, err = provider.Rate("ETH", "ETH")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", rate.String())
+
+	_, err = provider.Rate("ETH", "BTC")
+	assert.ErrorIs(t, err, ErrRateUnavailable)
+}
+
+func TestLoadFileProvider_RejectsInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"pair.json":     `{"BTCUSDT": "50000"}`,
+		"negative.json": `{"BTC/USDT": "-1"}`,
+		"syntax.json":   `{"BTC/USDT": }`,
+	} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		_, err := LoadFileProvider(path)
+		assert.Error(t, err, name)
+	}
+
+	_, err := LoadFileProvider(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,224 @@
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultHistorySize = 256 // 每位用戶保留供續傳的事件數
+	DefaultBufferSize  = 64  // 每個訂閱尚未送出的事件上限，超過即中斷該連線
+
+	DefaultHistoryTTL = 15 * time.Minute // 用戶沒有連線且最新事件超過此時間後丟棄其歷史
+)
+
+var ErrMalformedEventID = errors.New("malformed last event id")
+
+// Event 推送給單一用戶的事件
+// ID 格式為 "<epoch>-<seq>"：epoch 每次啟動重新產生，seq 在同一個 broker 內遞增
+type Event struct {
+	ID        string          `json:"id"`
+	UserID    uint            `json:"-"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+
+	seq uint64
+}
+
+// Subscription 一條連線的訂閱，C 被關閉代表訂閱已中斷（例如消費太慢），用戶端應帶 Last-Event-ID 重新連線
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	userID uint
+	closed bool
+}
+
+// userLog 用戶最近的事件，evictedSeq 為已被淘汰的最大 seq
+type userLog struct {
+	events     []Event
+	evictedSeq uint64
+}
+
+// Broker 行程內的 pub/sub，依用戶分送事件並保留最近的事件供斷線續傳
+// 只在單一實例內有效，也不會跨重啟保留；無法續傳時 Subscribe 回報 gap，用戶端應重新查詢目前狀態
+// 沒有連線的用戶在最新事件超過 historyTTL 後歷史會被丟棄，記憶體只隨近期活躍的用戶數成長
+type Broker struct {
+	mu          sync.Mutex
+	epoch       string
+	seq         uint64
+	historySize int
+	bufferSize  int
+	historyTTL  time.Duration
+	logs        map[uint]*userLog
+	subs        map[uint]map[*Subscription]struct{}
+	prunedSeq   uint64 // 已丟棄的歷史中最大的 seq
+	lastPrune   time.Time
+	now         func() time.Time
+}
+
+func NewBroker(historySize, bufferSize int) *Broker {
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	return &Broker{
+		epoch:       strings.ReplaceAll(uuid.NewString(), "-", "")[:12],
+		historySize: historySize,
+		bufferSize:  bufferSize,
+		historyTTL:  DefaultHistoryTTL,
+		logs:        make(map[uint]*userLog),
+		subs:        make(map[uint]map[*Subscription]struct{}),
+		now:         time.Now,
+	}
+}
+
+// Publish 將事件送給用戶目前所有的訂閱並保留在歷史中
+// 不會阻塞：緩衝已滿的訂閱會被中斷，由用戶端重新連線後以歷史補上
+func (b *Broker) Publish(userID uint, eventType string, data interface{}) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if now.Sub(b.lastPrune) >= b.historyTTL {
+		b.pruneLocked(now)
+	}
+
+	b.seq++
+	event := Event{
+		ID:        fmt.Sprintf("%s-%d", b.epoch, b.seq),
+		UserID:    userID,
+		Type:      eventType,
+		Data:      raw,
+		CreatedAt: now.UTC(),
+		seq:       b.seq,
+	}
+
+	history, ok := b.logs[userID]
+	if !ok {
+		// 先前的歷史可能已被丟棄，無法確定其中有沒有這位用戶的事件
+		history = &userLog{evictedSeq: b.prunedSeq}
+		b.logs[userID] = history
+	}
+	history.events = append(history.events, event)
+	if over := len(history.events) - b.historySize; over > 0 {
+		history.evictedSeq = history.events[over-1].seq
+		history.events = append([]Event(nil), history.events[over:]...)
+	}
+
+	for sub := range b.subs[userID] {
+		select {
+		case sub.ch <- event:
+		default:
+			b.closeLocked(sub)
+		}
+	}
+	return event, nil
+}
+
+// Subscribe 訂閱用戶的事件
+// lastEventID 不為空時先回傳之後的事件供補送；gap 為 true 代表中間有事件已無法補送
+// （broker 已重啟或事件已被淘汰），用戶端應重新查詢餘額與交易
+func (b *Broker) Subscribe(userID uint, lastEventID string) (sub *Subscription, replay []Event, gap bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, b.bufferSize)
+	sub = &Subscription{C: ch, ch: ch, userID: userID}
+	if b.subs[userID] == nil {
+		b.subs[userID] = make(map[*Subscription]struct{})
+	}
+	b.subs[userID][sub] = struct{}{}
+
+	if lastEventID == "" {
+		return sub, nil, false
+	}
+
+	epoch, seq, err := ParseEventID(lastEventID)
+	if err != nil || epoch != b.epoch || seq > b.seq {
+		return sub, nil, true
+	}
+
+	history := b.logs[userID]
+	if history == nil {
+		return sub, nil, seq < b.prunedSeq
+	}
+	for _, event := range history.events {
+		if event.seq > seq {
+			replay = append(replay, event)
+		}
+	}
+	return sub, replay, seq < history.evictedSeq
+}
+
+// Unsubscribe 結束訂閱，可重複呼叫
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closeLocked(sub)
+}
+
+// Subscribers 回傳用戶目前的訂閱數
+func (b *Broker) Subscribers(userID uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs[userID])
+}
+
+// pruneLocked 丟棄沒有連線且最新事件已超過 historyTTL 的用戶歷史
+func (b *Broker) pruneLocked(now time.Time) {
+	b.lastPrune = now
+	cutoff := now.Add(-b.historyTTL)
+	for userID, history := range b.logs {
+		if len(b.subs[userID]) > 0 {
+			continue
+		}
+		newest := history.events[len(history.events)-1]
+		if newest.CreatedAt.After(cutoff) {
+			continue
+		}
+		if newest.seq > b.prunedSeq {
+			b.prunedSeq = newest.seq
+		}
+		delete(b.logs, userID)
+	}
+}
+
+func (b *Broker) closeLocked(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.ch)
+	delete(b.subs[sub.userID], sub)
+	if len(b.subs[sub.userID]) == 0 {
+		delete(b.subs, sub.userID)
+	}
+}
+
+// ParseEventID 拆解 "<epoch>-<seq>" 格式的事件 ID
+func ParseEventID(id string) (string, uint64, error) {
+	epoch, rawSeq, ok := strings.Cut(id, "-")
+	if !ok || epoch == "" {
+		return "", 0, ErrMalformedEventID
+	}
+	seq, err := strconv.ParseUint(rawSeq, 10, 64)
+	if err != nil {
+		return "", 0, ErrMalformedEventID
+	}
+	return epoch, seq, nil
+}
@@ -0,0 +1,166 @@
+package stream
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receive(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case event, ok := <-sub.C:
+		assert.True(t, ok, "subscription was closed")
+		return event
+	default:
+		t.Fatal("no event was delivered")
+		return Event{}
+	}
+}
+
+// TestBroker_PublishesToTheUsersSubscriptions verifies events only reach the subscriptions of their user
+func TestBroker_PublishesToTheUsersSubscriptions(t *testing.T) {
+	b := NewBroker(0, 0)
+	alice, _, _ := b.Subscribe(1, "")
+	aliceTab, _, _ := b.Subscribe(1, "")
+	bob, _, _ := b.Subscribe(2, "")
+
+	published, err := b.Publish(1, "balance", map[string]string{"balance": "40"})
+	assert.NoError(t, err)
+
+	for _, sub := range []*Subscription{alice, aliceTab} {
+		event := receive(t, sub)
+		assert.Equal(t, published.ID, event.ID)
+		assert.Equal(t, "balance", event.Type)
+		assert.JSONEq(t, `{"balance":"40"}`, string(event.Data))
+	}
+	assert.Len(t, bob.C, 0)
+
+	b.Unsubscribe(alice)
+	b.Unsubscribe(alice)
+	assert.Equal(t, 1, b.Subscribers(1))
+	_, ok := <-alice.C
+	assert.False(t, ok)
+}
+
+// TestBroker_ResumesFromLastEventID verifies reconnecting with a Last-Event-ID replays only what was missed
+func TestBroker_ResumesFromLastEventID(t *testing.T) {
+	b := NewBroker(0, 0)
+	first, _ := b.Publish(1, "transaction", 1)
+	b.Publish(2, "transaction", 2)
+	second, _ := b.Publish(1, "transaction", 3)
+	third, _ := b.Publish(1, "balance", 4)
+
+	_, replay, gap := b.Subscribe(1, first.ID)
+	assert.False(t, gap)
+	assert.Len(t, replay, 2)
+	assert.Equal(t, second.ID, replay[0].ID)
+	assert.Equal(t, third.ID, replay[1].ID)
+
+	_, replay, gap = b.Subscribe(1, third.ID)
+	assert.False(t, gap)
+	assert.Empty(t, replay)
+}
+
+// TestBroker_ReportsGaps verifies evicted events, another broker's IDs and malformed IDs are reported as gaps
+func TestBroker_ReportsGaps(t *testing.T) {
+	b := NewBroker(2, 0)
+	first, _ := b.Publish(1, "transaction", 1)
+	second, _ := b.Publish(1, "transaction", 2)
+	b.Publish(1, "transaction", 3)
+	b.Publish(1, "transaction", 4)
+
+	_, replay, gap := b.Subscribe(1, first.ID)
+	assert.True(t, gap, "events after the last seen one were evicted")
+	assert.Len(t, replay, 2, "what is still retained is replayed anyway")
+
+	_, replay, gap = b.Subscribe(1, second.ID)
+	assert.False(t, gap)
+	assert.Len(t, replay, 2)
+
+	restarted := NewBroker(2, 0)
+	_, replay, gap = restarted.Subscribe(1, second.ID)
+	assert.True(t, gap, "IDs from before a restart cannot be resumed")
+	assert.Empty(t, replay)
+
+	for _, id := range []string{"garbage", "-1", "abc-x"} {
+		_, _, gap = b.Subscribe(1, id)
+		assert.True(t, gap, id)
+	}
+}
+
+// TestBroker_DropsSlowSubscribers verifies a full buffer closes the subscription instead of blocking the publisher
+func TestBroker_DropsSlowSubscribers(t *testing.T) {
+	b := NewBroker(0, 2)
+	slow, _, _ := b.Subscribe(1, "")
+
+	for i := 0; i < 3; i++ {
+		_, err := b.Publish(1, "transaction", i)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 0, b.Subscribers(1))
+	var received []Event
+	for event := range slow.C {
+		received = append(received, event)
+	}
+	assert.Len(t, received, 2)
+
+	_, replay, gap := b.Subscribe(1, received[1].ID)
+	assert.False(t, gap)
+	assert.Len(t, replay, 1, "the dropped event is replayed after reconnecting")
+}
+
+// TestBroker_PrunesIdleHistories verifies the history of users without subscriptions is dropped once their
+// newest event is older than the TTL, and resuming from a dropped history reports a gap
+func TestBroker_PrunesIdleHistories(t *testing.T) {
+	now := time.Now()
+	b := NewBroker(0, 0)
+	b.now = func() time.Time { return now }
+
+	idle, _ := b.Publish(1, "transaction", 1)
+	b.Publish(1, "transaction", 1)
+	online, _, _ := b.Subscribe(2, "")
+	b.Publish(2, "transaction", 2)
+	recent, _ := b.Publish(3, "transaction", 3)
+
+	now = now.Add(DefaultHistoryTTL - time.Minute)
+	b.Publish(3, "transaction", 4)
+	now = now.Add(2 * time.Minute)
+	b.Publish(4, "transaction", 5)
+
+	b.mu.Lock()
+	_, idleKept := b.logs[1]
+	_, onlineKept := b.logs[2]
+	_, recentKept := b.logs[3]
+	b.mu.Unlock()
+	assert.False(t, idleKept, "idle user past the TTL is dropped")
+	assert.True(t, onlineKept, "users with a subscription keep their history")
+	assert.True(t, recentKept, "a recent event keeps the history")
+
+	_, replay, gap := b.Subscribe(1, idle.ID)
+	assert.True(t, gap, "the missed event was dropped with the history")
+	assert.Empty(t, replay)
+
+	_, replay, gap = b.Subscribe(3, recent.ID)
+	assert.False(t, gap)
+	assert.Len(t, replay, 1)
+
+	b.Unsubscribe(online)
+}
+
+// TestWriteEvent verifies the Server-Sent Events framing, including multi-line data
+func TestWriteEvent(t *testing.T) {
+	var b strings.Builder
+	assert.NoError(t, WriteRetry(&b, 3000))
+	assert.NoError(t, WriteEvent(&b, "abc-7", "balance", []byte(`{"balance":"40"}`)))
+	assert.NoError(t, WriteEvent(&b, "", "reset", []byte("line one\nline two")))
+	assert.NoError(t, WriteComment(&b, "heartbeat"))
+
+	assert.Equal(t, "retry: 3000\n\n"+
+		"id: abc-7\nevent: balance\ndata: {\"balance\":\"40\"}\n\n"+
+		"event: reset\ndata: line one\ndata: line two\n\n"+
+		": heartbeat\n\n", b.String())
+}
@@ -0,0 +1,35 @@
+package stream
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteEvent 以 Server-Sent Events 格式寫出事件，id 為空時不帶 id（不會更新用戶端的 Last-Event-ID）
+func WriteEvent(w io.Writer, id, eventType string, data []byte) error {
+	var b strings.Builder
+	if id != "" {
+		fmt.Fprintf(&b, "id: %s\n", id)
+	}
+	fmt.Fprintf(&b, "event: %s\n", eventType)
+	// data 內的換行必須拆成多行 data:，用戶端會以 \n 接回
+	for _, line := range strings.Split(string(data), "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteComment 寫出註解行，用戶端會忽略，用於 heartbeat 讓代理與負載平衡器不因閒置而斷線
+func WriteComment(w io.Writer, comment string) error {
+	_, err := fmt.Fprintf(w, ": %s\n\n", comment)
+	return err
+}
+
+// WriteRetry 設定用戶端斷線後重新連線前等待的毫秒數
+func WriteRetry(w io.Writer, millis int) error {
+	_, err := fmt.Fprintf(w, "retry: %d\n\n", millis)
+	return err
+}
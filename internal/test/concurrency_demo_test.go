@@ -25,7 +25,7 @@ func TestConcurrentTransfers(t *testing.T) {
 	// 初始化 repository 和 service
 	walletRepo := repositories.NewWalletRepository()
 	txRepo := repositories.NewTransactionRepository()
-	txService := services.NewTransactionService(walletRepo, txRepo, nil) // Kafka 可用 nil
+	txService := services.NewTransactionService(walletRepo, txRepo)
 
 	// 重置 A/B 錢包
 	resetWallets(db_conn.Conn_DB.MasterDB, walletRepo)
@@ -0,0 +1,60 @@
+package test
+
+import (
+	"mini-crypto-wallet-api/internal/eventbus"
+	"mini-crypto-wallet-api/internal/events"
+	"testing"
+)
+
+// EventRecorder is an in-memory EventPublisher that decodes what was published,
+// so tests can assert on events by type and payload
+type EventRecorder struct {
+	*eventbus.MemoryPublisher
+}
+
+// NewEventRecorder creates an empty recorder
+func NewEventRecorder() *EventRecorder {
+	return &EventRecorder{MemoryPublisher: eventbus.NewMemoryPublisher()}
+}
+
+// Envelopes parses every event published to topic, failing the test on messages that are not envelopes
+func (r *EventRecorder) Envelopes(t *testing.T, topic string) []*events.Envelope {
+	t.Helper()
+	messages := r.MessagesForTopic(topic)
+	envelopes := make([]*events.Envelope, 0, len(messages))
+	for _, msg := range messages {
+		envelope, err := events.Parse(msg.Value)
+		if err != nil {
+			t.Fatalf("event on %s is not a valid envelope: %v (%s)", topic, err, msg.Value)
+		}
+		envelopes = append(envelopes, envelope)
+	}
+	return envelopes
+}
+
+// Types returns the event types published to topic in order
+func (r *EventRecorder) Types(t *testing.T, topic string) []string {
+	t.Helper()
+	envelopes := r.Envelopes(t, topic)
+	types := make([]string, len(envelopes))
+	for i, envelope := range envelopes {
+		types[i] = envelope.Type
+	}
+	return types
+}
+
+// Last decodes the payload of the most recent event of eventType on topic into v
+func (r *EventRecorder) Last(t *testing.T, topic string, eventType string, v interface{}) {
+	t.Helper()
+	envelopes := r.Envelopes(t, topic)
+	for i := len(envelopes) - 1; i >= 0; i-- {
+		if envelopes[i].Type != eventType {
+			continue
+		}
+		if err := envelopes[i].DecodeInto(v); err != nil {
+			t.Fatalf("decode %s: %v", eventType, err)
+		}
+		return
+	}
+	t.Fatalf("no %s event was published to %s", eventType, topic)
+}
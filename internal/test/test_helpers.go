@@ -1,9 +1,11 @@
 package test
 
 import (
+	"fmt"
 	"log"
 	"mini-crypto-wallet-api/db_conn"
 	"mini-crypto-wallet-api/models"
+	"sync/atomic"
 
 	"github.com/shopspring/decimal"
 	"gorm.io/driver/sqlite"
@@ -11,9 +13,15 @@ import (
 	"gorm.io/gorm/logger"
 )
 
+// testDBSeq gives every SetupTestDB call its own named in-memory database
+var testDBSeq atomic.Uint64
+
 // SetupTestDB initializes an in-memory SQLite database for testing
+// The database uses a shared cache so every pooled connection (including the
+// one held by an open transaction) sees the same data
 func SetupTestDB() *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+	dsn := fmt.Sprintf("file:testdb%d?mode=memory&cache=shared", testDBSeq.Add(1))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
@@ -30,6 +38,27 @@ func SetupTestDB() *gorm.DB {
 		&models.Wallet{},
 		&models.Transaction{},
 		&models.BalanceHistory{},
+		&models.OutboxEvent{},
+		&models.IdempotencyKey{},
+		&models.LedgerAccount{},
+		&models.JournalEntry{},
+		&models.LedgerPosting{},
+		&models.ReconciliationRun{},
+		&models.ReconciliationFinding{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.AdminAuditLog{},
+		&models.SwapQuote{},
+		&models.FeeSchedule{},
+		&models.FeeTier{},
+		&models.TransferLimit{},
+		&models.ScheduledTransfer{},
+		&models.ScheduledTransferRun{},
+		&models.AuditCheckpoint{},
+		&models.AuditCheckpointLeaf{},
+		&models.Notification{},
+		&models.WebhookEndpoint{},
+		&models.WebhookDelivery{},
 	)
 	if err != nil {
 		log.Fatal("❌ Failed to migrate test database:", err)
@@ -0,0 +1,120 @@
+package txsign
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"mini-crypto-wallet-api/internal/auth"
+)
+
+// Algorithm 交易簽章使用的演算法
+const Algorithm = "Ed25519"
+
+var (
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrInvalidSignature = errors.New("signature does not match")
+)
+
+// Key 一把以 kid 識別的 Ed25519 金鑰
+// PrivateKey 為 nil 時只能用於驗證（已輪替下來的舊金鑰）
+type Key struct {
+	KID        string
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+}
+
+// Signer 以 active kid 的金鑰簽署交易，並以任何已知 kid 的公鑰驗證
+// 輪替金鑰時保留舊的公鑰，舊交易的簽章才能繼續驗證
+type Signer struct {
+	keys      map[string]*Key
+	activeKID string
+}
+
+// NewSigner 建立 Signer，activeKID 必須是一把有私鑰的金鑰
+func NewSigner(keys []*Key, activeKID string) (*Signer, error) {
+	s := &Signer{keys: make(map[string]*Key, len(keys)), activeKID: activeKID}
+	for _, key := range keys {
+		s.keys[key.KID] = key
+	}
+	active, ok := s.keys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("active signing key %q not found", activeKID)
+	}
+	if active.PrivateKey == nil {
+		return nil, fmt.Errorf("active signing key %q has no private key", activeKID)
+	}
+	return s, nil
+}
+
+// NewEphemeralSigner 以行程內臨時產生的金鑰建立 Signer，重啟後舊簽章即無法驗證，只適合測試與開發
+func NewEphemeralSigner() *Signer {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	signer, _ := NewSigner([]*Key{{KID: "ephemeral", PrivateKey: priv, PublicKey: pub}}, "ephemeral")
+	return signer
+}
+
+// LoadSigner 讀取目錄中所有 Ed25519 PEM 金鑰，檔名（去掉 .pem / .pub.pem）即為 kid
+// 只有一把金鑰且未指定 activeKID 時使用該金鑰
+func LoadSigner(dir, activeKID string) (*Signer, error) {
+	loaded, err := auth.LoadSigningKeys(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*Key, 0, len(loaded))
+	for _, key := range loaded {
+		pub, ok := key.PublicKey.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%s: transaction signing keys must be Ed25519", key.KID)
+		}
+		k := &Key{KID: key.KID, PublicKey: pub}
+		if key.PrivateKey != nil {
+			k.PrivateKey = key.PrivateKey.(ed25519.PrivateKey)
+		}
+		keys = append(keys, k)
+	}
+
+	if activeKID == "" && len(keys) == 1 {
+		activeKID = keys[0].KID
+	}
+	return NewSigner(keys, activeKID)
+}
+
+// ActiveKID 回傳簽署新交易所用的 kid
+func (s *Signer) ActiveKID() string {
+	return s.activeKID
+}
+
+// Sign 以 active 金鑰簽署 payload，回傳 kid 與 base64 編碼的簽章
+func (s *Signer) Sign(payload []byte) (string, string) {
+	key := s.keys[s.activeKID]
+	return key.KID, base64.StdEncoding.EncodeToString(ed25519.Sign(key.PrivateKey, payload))
+}
+
+// Verify 以 kid 對應的公鑰驗證 payload 的簽章
+func (s *Signer) Verify(kid string, payload []byte, signature string) error {
+	key, ok := s.keys[kid]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownKey, kid)
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !ed25519.Verify(key.PublicKey, payload, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// PublicKey 回傳 kid 對應的 base64 公鑰，讓呼叫端可以自行驗證
+func (s *Signer) PublicKey(kid string) (string, bool) {
+	key, ok := s.keys[kid]
+	if !ok {
+		return "", false
+	}
+	return base64.StdEncoding.EncodeToString(key.PublicKey), true
+}
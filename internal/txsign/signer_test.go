@@ -0,0 +1,72 @@
+package txsign
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKey(t *testing.T, dir, kid string, publicOnly bool) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	block := &pem.Block{Type: "PRIVATE KEY"}
+	name := kid + ".pem"
+	if publicOnly {
+		block.Type, name = "PUBLIC KEY", kid+".pub.pem"
+		block.Bytes, err = x509.MarshalPKIXPublicKey(pub)
+	} else {
+		block.Bytes, err = x509.MarshalPKCS8PrivateKey(priv)
+	}
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(block), 0o600))
+}
+
+// TestSigner_SignAndVerify verifies signatures round-trip and tampering is detected
+func TestSigner_SignAndVerify(t *testing.T) {
+	signer := NewEphemeralSigner()
+	payload := []byte(`{"v":1,"amount":"40"}`)
+
+	kid, sig := signer.Sign(payload)
+	assert.Equal(t, signer.ActiveKID(), kid)
+	assert.NoError(t, signer.Verify(kid, payload, sig))
+
+	assert.ErrorIs(t, signer.Verify(kid, []byte(`{"v":1,"amount":"41"}`), sig), ErrInvalidSignature)
+	assert.ErrorIs(t, signer.Verify(kid, payload, "not base64!"), ErrInvalidSignature)
+	assert.ErrorIs(t, signer.Verify("other", payload, sig), ErrUnknownKey)
+}
+
+// TestLoadSigner_RotationKeepsOldKeysVerifiable verifies a rotated key still verifies but cannot sign
+func TestLoadSigner_RotationKeepsOldKeysVerifiable(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "2026-01", false)
+	payload := []byte("payload")
+
+	old, err := LoadSigner(dir, "")
+	assert.NoError(t, err)
+	kid, sig := old.Sign(payload)
+	assert.Equal(t, "2026-01", kid)
+
+	writeKey(t, dir, "2026-10", false)
+	writeKey(t, dir, "2025-06", true)
+
+	_, err = LoadSigner(dir, "")
+	assert.Error(t, err, "several keys require an explicit active kid")
+	_, err = LoadSigner(dir, "2025-06")
+	assert.Error(t, err, "public-only keys cannot sign")
+
+	rotated, err := LoadSigner(dir, "2026-10")
+	assert.NoError(t, err)
+	assert.NoError(t, rotated.Verify(kid, payload, sig))
+	newKID, _ := rotated.Sign(payload)
+	assert.Equal(t, "2026-10", newKID)
+	_, ok := rotated.PublicKey("2025-06")
+	assert.True(t, ok)
+}
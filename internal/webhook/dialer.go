@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+var ErrForbiddenAddress = errors.New("webhook destination resolves to a private address")
+
+// ForbiddenIP 回報不可作為投遞目的地的位址：本機、內網、link-local、未指定與 multicast
+func ForbiddenIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast()
+}
+
+// DialControl 給 net.Dialer.Control 使用，在每次連線前檢查 DNS 實際解析出的位址
+// 網域在註冊後才改指向內網（DNS rebinding）也會在這裡被擋下
+func DialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ForbiddenIP(ip) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
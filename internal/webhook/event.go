@@ -0,0 +1,16 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event 每次投遞 POST 給 endpoint 的 JSON body
+// id 對同一事件固定不變（重試與重新投遞都相同），接收端應以它去重
+// data 與對應 Kafka 事件（events.Envelope）的 data 相同
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSign_Verify verifies a signature round-trips and detects tampering or the wrong secret
+func TestSign_Verify(t *testing.T) {
+	secret, err := NewSecret()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, SecretPrefix))
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"id":"transfer.received:1","type":"transfer.received"}`)
+	header := Sign(secret, now, body)
+	assert.True(t, strings.HasPrefix(header, "t=1792238400,v1="))
+
+	assert.NoError(t, Verify(secret, header, body, now, DefaultTolerance))
+	assert.ErrorIs(t, Verify(secret, header, []byte(`{"id":"transfer.received:2"}`), now, DefaultTolerance), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("whsec_other", header, body, now, DefaultTolerance), ErrInvalidSignature)
+}
+
+// TestVerify_RejectsReplayAndMalformedHeaders verifies the timestamp tolerance and header parsing
+func TestVerify_RejectsReplayAndMalformedHeaders(t *testing.T) {
+	signedAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{}`)
+	header := Sign("whsec_test", signedAt, body)
+
+	assert.ErrorIs(t, Verify("whsec_test", header, body, signedAt.Add(10*time.Minute), DefaultTolerance), ErrSignatureExpired)
+	assert.NoError(t, Verify("whsec_test", header, body, signedAt.Add(10*time.Minute), 0), "tolerance 0 skips the timestamp check")
+
+	for _, bad := range []string{"", "v1=abc", "t=1792238400", "t=abc,v1=abc", "garbage"} {
+		assert.ErrorIs(t, Verify("whsec_test", bad, body, signedAt, DefaultTolerance), ErrMalformedSignature, bad)
+	}
+}
@@ -0,0 +1,233 @@
+package kafka_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// DeadLetterSuffix 死信 topic 的後綴，例如 tx.created 的死信 topic 為 tx.created.dlq
+const DeadLetterSuffix = ".dlq"
+
+const (
+	defaultConsumerRetryBackoff    = 500 * time.Millisecond
+	defaultConsumerMaxRetryBackoff = 30 * time.Second
+)
+
+// DeadLetterTopic 回傳 topic 對應的死信 topic
+func DeadLetterTopic(topic string) string {
+	return topic + DeadLetterSuffix
+}
+
+// MessageHandler 處理單筆訊息，回傳 nil 後 offset 才會被提交
+// 訊息可能重複投遞（at-least-once），handler 必須是冪等的
+type MessageHandler func(ctx context.Context, msg kafka.Message) error
+
+// MessageReader 從 consumer group 讀取與提交訊息
+// *kafka.Reader 實作此介面，測試時可替換成假實作
+type MessageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// DeadLetterMessage 寫入死信 topic 的內容，保留原始訊息與失敗原因以便人工處理或重放
+type DeadLetterMessage struct {
+	Topic     string `json:"topic"`
+	Partition int    `json:"partition"`
+	Offset    int64  `json:"offset"`
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Error     string `json:"error"`
+	Attempts  int    `json:"attempts"`
+	GroupID   string `json:"group_id"`
+	FailedAt  string `json:"failed_at"`
+}
+
+// poisonError 標記無法透過重試處理的訊息（例如格式錯誤）
+type poisonError struct {
+	err error
+}
+
+func (e *poisonError) Error() string { return e.err.Error() }
+func (e *poisonError) Unwrap() error { return e.err }
+
+// Poison 包裝 handler 的錯誤，表示訊息無法處理，直接送到死信 topic 而不重試
+func Poison(err error) error {
+	return &poisonError{err: err}
+}
+
+// IsPoison 判斷錯誤是否為 Poison 包裝過的錯誤
+func IsPoison(err error) bool {
+	var p *poisonError
+	return errors.As(err, &p)
+}
+
+// ConsumerConfig consumer group 設定
+type ConsumerConfig struct {
+	Brokers []string
+	GroupID string
+	Topics  []string
+}
+
+// Consumer 以 consumer group 消費訊息，handler 成功後才手動提交 offset
+// 只有被標記為 Poison 的訊息會送到死信 topic；暫時性錯誤（例如 DB 斷線）會持續重試同一筆，不提交也不跳過
+type Consumer struct {
+	reader          MessageReader
+	deadLetters     MessagePublisher
+	handler         MessageHandler
+	groupID         string
+	retryBackoff    time.Duration
+	maxRetryBackoff time.Duration
+	now             func() time.Time
+}
+
+// NewConsumer 建立 Kafka consumer group；CommitInterval 為 0 代表同步提交，由 Consumer 決定提交時機
+func NewConsumer(cfg ConsumerConfig, handler MessageHandler, deadLetters MessagePublisher) *Consumer {
+	// 嘗試建立死信 topic（如不存在）
+	for _, topic := range cfg.Topics {
+		if err := createTopic(cfg.Brokers[0], DeadLetterTopic(topic), 1, 1); err != nil {
+			log.Printf("⚠️ Kafka topic create failed: %v", err)
+		}
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        cfg.Brokers,
+		GroupID:        cfg.GroupID,
+		GroupTopics:    cfg.Topics,
+		StartOffset:    kafka.FirstOffset,
+		CommitInterval: 0,
+	})
+	return NewConsumerWithReader(reader, cfg.GroupID, handler, deadLetters)
+}
+
+// NewConsumerWithReader 以自訂的 MessageReader 建立 Consumer
+func NewConsumerWithReader(reader MessageReader, groupID string, handler MessageHandler, deadLetters MessagePublisher) *Consumer {
+	return &Consumer{
+		reader:          reader,
+		deadLetters:     deadLetters,
+		handler:         handler,
+		groupID:         groupID,
+		retryBackoff:    defaultConsumerRetryBackoff,
+		maxRetryBackoff: defaultConsumerMaxRetryBackoff,
+		now:             time.Now,
+	}
+}
+
+// Run 持續消費訊息，直到 ctx 被取消
+func (c *Consumer) Run(ctx context.Context) {
+	for {
+		if err := c.ProcessNext(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Println("⚠️ Kafka consumer error:", err)
+			if !sleepContext(ctx, c.retryBackoff) {
+				return
+			}
+		}
+	}
+}
+
+// ProcessNext 讀取並處理一筆訊息
+// 只有在 handler 成功或 poison 訊息已寫入死信 topic 之後才提交 offset，否則訊息會在重新平衡或重啟後再次投遞
+func (c *Consumer) ProcessNext(ctx context.Context) error {
+	msg, err := c.reader.FetchMessage(ctx)
+	if err != nil {
+		return err
+	}
+
+	attempts, handleErr := c.handle(ctx, msg)
+	if handleErr != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("⚠️ Kafka: %s[%d]@%d moved to dead letter topic after %d attempt(s): %v", msg.Topic, msg.Partition, msg.Offset, attempts, handleErr)
+		if err := c.deadLetter(ctx, msg, attempts, handleErr); err != nil {
+			return err
+		}
+	}
+
+	return c.reader.CommitMessages(ctx, msg)
+}
+
+// handle 呼叫 handler，暫時性錯誤以指數退避（上限 maxRetryBackoff）重試直到成功或 ctx 被取消
+// 只有 Poison 錯誤會回傳給呼叫端送往死信 topic
+func (c *Consumer) handle(ctx context.Context, msg kafka.Message) (int, error) {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = c.handler(ctx, msg); err == nil {
+			return attempt, nil
+		}
+		if IsPoison(err) {
+			return attempt, err
+		}
+		log.Printf("⚠️ Kafka: %s[%d]@%d attempt %d failed, retrying: %v", msg.Topic, msg.Partition, msg.Offset, attempt, err)
+		if !sleepContext(ctx, c.retryDelay(attempt)) {
+			return attempt, err
+		}
+	}
+}
+
+// retryDelay 第 attempt 次失敗後的等待時間，從 retryBackoff 開始每次加倍，最多 maxRetryBackoff
+func (c *Consumer) retryDelay(attempt int) time.Duration {
+	delay := c.retryBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= c.maxRetryBackoff {
+			return c.maxRetryBackoff
+		}
+	}
+	return delay
+}
+
+// deadLetter 將訊息寫入死信 topic；寫入失敗時會持續重試，避免提交後遺失訊息
+func (c *Consumer) deadLetter(ctx context.Context, msg kafka.Message, attempts int, cause error) error {
+	payload, err := json.Marshal(DeadLetterMessage{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       string(msg.Key),
+		Value:     string(msg.Value),
+		Error:     cause.Error(),
+		Attempts:  attempts,
+		GroupID:   c.groupID,
+		FailedAt:  c.now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		err := c.deadLetters.Publish(ctx, DeadLetterTopic(msg.Topic), string(msg.Key), payload)
+		if err == nil {
+			return nil
+		}
+		log.Println("⚠️ Kafka: failed to publish dead letter:", err)
+		if !sleepContext(ctx, c.retryBackoff) {
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *Consumer) Close() {
+	if err := c.reader.Close(); err != nil {
+		log.Println("Failed to close Kafka reader:", err)
+	}
+}
+
+// sleepContext 等待 d，ctx 被取消時提前返回 false
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
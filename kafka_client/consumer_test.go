@@ -0,0 +1,192 @@
+package kafka_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeReader serves queued messages and records commits
+type fakeReader struct {
+	mu        sync.Mutex
+	queue     []kafka.Message
+	committed []kafka.Message
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.queue) == 0 {
+		return kafka.Message{}, context.Canceled
+	}
+	msg := r.queue[0]
+	r.queue = r.queue[1:]
+	return msg, nil
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.committed = append(r.committed, msgs...)
+	return nil
+}
+
+func (r *fakeReader) Close() error { return nil }
+
+type recordedMessage struct {
+	topic string
+	key   string
+	value []byte
+}
+
+// fakeDeadLetters records dead letters and fails the first `failures` publishes
+type fakeDeadLetters struct {
+	failures int
+	messages []recordedMessage
+}
+
+func (p *fakeDeadLetters) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("kafka: broker not available")
+	}
+	p.messages = append(p.messages, recordedMessage{topic, key, value})
+	return nil
+}
+
+func newTestConsumer(reader MessageReader, handler MessageHandler, deadLetters MessagePublisher) *Consumer {
+	c := NewConsumerWithReader(reader, "test-group", handler, deadLetters)
+	c.retryBackoff = time.Millisecond
+	return c
+}
+
+func testMessage(offset int64) kafka.Message {
+	return kafka.Message{Topic: TopicTxCreated, Partition: 0, Offset: offset, Key: []byte("hash"), Value: []byte(`{"hash":"hash"}`)}
+}
+
+func TestConsumer_CommitsAfterSuccess(t *testing.T) {
+	reader := &fakeReader{queue: []kafka.Message{testMessage(1)}}
+	dlq := &fakeDeadLetters{}
+	handled := 0
+	c := newTestConsumer(reader, func(ctx context.Context, msg kafka.Message) error {
+		handled++
+		return nil
+	}, dlq)
+
+	assert.NoError(t, c.ProcessNext(context.Background()))
+	assert.Equal(t, 1, handled)
+	assert.Len(t, reader.committed, 1)
+	assert.Equal(t, int64(1), reader.committed[0].Offset)
+	assert.Empty(t, dlq.messages)
+}
+
+func TestConsumer_RetriesTransientErrors(t *testing.T) {
+	reader := &fakeReader{queue: []kafka.Message{testMessage(1)}}
+	dlq := &fakeDeadLetters{}
+	calls := 0
+	c := newTestConsumer(reader, func(ctx context.Context, msg kafka.Message) error {
+		calls++
+		if calls < 3 {
+			return errors.New("database is locked")
+		}
+		return nil
+	}, dlq)
+
+	assert.NoError(t, c.ProcessNext(context.Background()))
+	assert.Equal(t, 3, calls)
+	assert.Len(t, reader.committed, 1)
+	assert.Empty(t, dlq.messages)
+}
+
+func TestConsumer_TransientErrorsRetryUntilCancelled(t *testing.T) {
+	reader := &fakeReader{queue: []kafka.Message{testMessage(7)}}
+	dlq := &fakeDeadLetters{}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	calls := 0
+	c := newTestConsumer(reader, func(ctx context.Context, msg kafka.Message) error {
+		calls++
+		return errors.New("database is locked")
+	}, dlq)
+	c.maxRetryBackoff = 2 * time.Millisecond
+
+	assert.Error(t, c.ProcessNext(ctx))
+	assert.Greater(t, calls, 3, "transient errors keep being retried")
+	assert.Empty(t, dlq.messages, "transient errors never go to the dead letter topic")
+	assert.Empty(t, reader.committed, "the message is redelivered after a restart")
+}
+
+func TestConsumer_RetryBackoffIsCapped(t *testing.T) {
+	c := NewConsumerWithReader(nil, "test-group", nil, nil)
+
+	assert.Equal(t, defaultConsumerRetryBackoff, c.retryDelay(1))
+	assert.Equal(t, 2*defaultConsumerRetryBackoff, c.retryDelay(2))
+	assert.Equal(t, defaultConsumerMaxRetryBackoff, c.retryDelay(50))
+}
+
+func TestConsumer_PoisonMessageSkipsRetries(t *testing.T) {
+	reader := &fakeReader{queue: []kafka.Message{testMessage(1), testMessage(2)}}
+	dlq := &fakeDeadLetters{}
+	calls := 0
+	c := newTestConsumer(reader, func(ctx context.Context, msg kafka.Message) error {
+		calls++
+		if msg.Offset == 1 {
+			return Poison(errors.New("invalid payload"))
+		}
+		return nil
+	}, dlq)
+
+	assert.NoError(t, c.ProcessNext(context.Background()))
+	assert.NoError(t, c.ProcessNext(context.Background()))
+
+	assert.Equal(t, 2, calls, "a poison message is handled once")
+	assert.Len(t, reader.committed, 2, "the next message is processed after the poison one")
+
+	assert.Len(t, dlq.messages, 1)
+	assert.Equal(t, "tx.created.dlq", dlq.messages[0].topic)
+	assert.Equal(t, "hash", dlq.messages[0].key)
+
+	var dead DeadLetterMessage
+	assert.NoError(t, json.Unmarshal(dlq.messages[0].value, &dead))
+	assert.Equal(t, TopicTxCreated, dead.Topic)
+	assert.Equal(t, int64(1), dead.Offset)
+	assert.Equal(t, `{"hash":"hash"}`, dead.Value)
+	assert.Equal(t, "invalid payload", dead.Error)
+	assert.Equal(t, 1, dead.Attempts)
+	assert.Equal(t, "test-group", dead.GroupID)
+}
+
+func TestConsumer_DeadLetterPublishIsRetriedBeforeCommit(t *testing.T) {
+	reader := &fakeReader{queue: []kafka.Message{testMessage(1)}}
+	dlq := &fakeDeadLetters{failures: 2}
+	c := newTestConsumer(reader, func(ctx context.Context, msg kafka.Message) error {
+		return Poison(errors.New("invalid payload"))
+	}, dlq)
+
+	assert.NoError(t, c.ProcessNext(context.Background()))
+	assert.Len(t, dlq.messages, 1)
+	assert.Len(t, reader.committed, 1)
+}
+
+func TestConsumer_CancelledWhileDeadLetterDownDoesNotCommit(t *testing.T) {
+	reader := &fakeReader{queue: []kafka.Message{testMessage(1)}}
+	dlq := &fakeDeadLetters{failures: 1 << 30}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	c := newTestConsumer(reader, func(ctx context.Context, msg kafka.Message) error {
+		return Poison(errors.New("invalid payload"))
+	}, dlq)
+
+	assert.Error(t, c.ProcessNext(ctx))
+	assert.Empty(t, reader.committed, "the message is redelivered after a restart")
+}
+
+func TestDeadLetterTopic(t *testing.T) {
+	assert.Equal(t, "tx.created.dlq", DeadLetterTopic(TopicTxCreated))
+}
@@ -6,46 +6,95 @@ import (
 	"github.com/segmentio/kafka-go"
 	"github.com/shopspring/decimal"
 	"log"
+	"sync"
+	"time"
 )
 
+// TopicTxCreated 轉帳完成事件的 topic
+const TopicTxCreated = "tx.created"
+
+// TopicTxStatusChanged 入金 / 出金狀態變更事件的 topic
+const TopicTxStatusChanged = "tx.status_changed"
+
+// TopicUserEvents 用戶建立、凍結與角色變更事件的 topic
+const TopicUserEvents = "user.events"
+
+// TxCreatedMessage 導入 events.Envelope 前 tx.created 的訊息格式
+// 新事件一律以 envelope 發送，consumer 仍需能解析 outbox 或 topic 中殘留的舊訊息
 type TxCreatedMessage struct {
-	Hash       string          `json:"hash"`
-	FromUserID uint            `json:"from_user_id"`
-	ToUserID   uint            `json:"to_user_id"`
-	Amount     decimal.Decimal `json:"amount"`
-	Timestamp  string          `json:"timestamp"`
+	Hash         string          `json:"hash"`
+	Type         string          `json:"type,omitempty"`
+	OriginalHash string          `json:"original_hash,omitempty"` // Transfer undone by a reversal or refund
+	FromUserID   uint            `json:"from_user_id"`
+	ToUserID     uint            `json:"to_user_id"`
+	Amount       decimal.Decimal `json:"amount"`
+	Fee          decimal.Decimal `json:"fee"`
+	Timestamp    string          `json:"timestamp"`
+}
+
+// MessagePublisher 發送原始訊息到指定 topic
+// KafkaProducer 實作此介面，測試時可替換成 in-process 的假實作
+type MessagePublisher interface {
+	Publish(ctx context.Context, topic string, key string, value []byte) error
+}
+
+// TxStatusChangedMessage 導入 events.Envelope 前 tx.status_changed 的訊息格式
+type TxStatusChangedMessage struct {
+	Hash           string          `json:"hash"`
+	Type           string          `json:"type"`
+	UserID         uint            `json:"user_id"`
+	CurrencyID     uint            `json:"currency_id"`
+	Amount         decimal.Decimal `json:"amount"`
+	PreviousStatus string          `json:"previous_status,omitempty"`
+	Status         string          `json:"status"`
+	Timestamp      string          `json:"timestamp"`
 }
 
 type KafkaProducer struct {
 	writer *kafka.Writer
+	broker string
+	topic  string
+
+	mu            sync.Mutex
+	createdTopics map[string]bool
 }
 
+// NewKafkaProducer 建立 producer，不會連線 broker；topic 在第一次發送時才建立
 func NewKafkaProducer(brokerAddr string, topic string) *KafkaProducer {
-	// 嘗試建立 topic（如不存在）
-	if err := createTopic(brokerAddr, topic, 1, 1); err != nil {
-		log.Printf("⚠️ Kafka topic create failed: %v", err)
-	}
-
 	return &KafkaProducer{
 		writer: &kafka.Writer{
 			Addr:     kafka.TCP(brokerAddr),
-			Topic:    topic,
 			Balancer: &kafka.LeastBytes{},
+			// 預設 1 秒才送出未滿的批次，每次只寫一筆的 outbox relay 會被拖慢
+			BatchTimeout: 10 * time.Millisecond,
 		},
+		broker:        brokerAddr,
+		topic:         topic,
+		createdTopics: make(map[string]bool),
 	}
 }
 
+// ensureTopic 嘗試建立 topic（如不存在），每個 topic 成功一次後不再檢查
+func (kp *KafkaProducer) ensureTopic(topic string) {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	if kp.createdTopics[topic] {
+		return
+	}
+	if err := createTopic(kp.broker, topic, 1, 1); err != nil {
+		log.Printf("⚠️ Kafka topic create failed: %v", err)
+		return
+	}
+	kp.createdTopics[topic] = true
+}
+
 func (kp *KafkaProducer) SendTxCreated(msg TxCreatedMessage) error {
 	bytes, err := json.Marshal(msg)
 	if err != nil {
 		return err
 	}
 
-	if err = kp.writer.WriteMessages(context.Background(), kafka.Message{
-		Key:   []byte(msg.Hash),
-		Value: bytes,
-	}); err != nil {
-		log.Println("Kafka write error:", err)
+	if err = kp.Publish(context.Background(), kp.topic, msg.Hash, bytes); err != nil {
 		return err
 	}
 
@@ -54,10 +103,28 @@ func (kp *KafkaProducer) SendTxCreated(msg TxCreatedMessage) error {
 	return nil
 }
 
-func (kp *KafkaProducer) Close() {
+// Publish 發送單筆訊息，供 outbox relay 使用
+func (kp *KafkaProducer) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	kp.ensureTopic(topic)
+
+	if err := kp.writer.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Key:   []byte(key),
+		Value: value,
+	}); err != nil {
+		log.Println("Kafka write error:", err)
+		return err
+	}
+
+	return nil
+}
+
+func (kp *KafkaProducer) Close() error {
 	if err := kp.writer.Close(); err != nil {
 		log.Println("Failed to close Kafka writer:", err)
+		return err
 	}
+	return nil
 }
 
 func createTopic(broker, topic string, numPartitions, replicationFactor int) error {
@@ -14,8 +14,18 @@
 package main
 
 import (
+	"context"
+	"log"
+	"mini-crypto-wallet-api/internal/auth"
 	"mini-crypto-wallet-api/internal/config"
+	"mini-crypto-wallet-api/internal/eventbus"
+	"mini-crypto-wallet-api/internal/rates"
+	"mini-crypto-wallet-api/internal/txsign"
 	"mini-crypto-wallet-api/kafka_client"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"mini-crypto-wallet-api/services"
+	"time"
 
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -29,14 +39,129 @@ func main() {
 	config.LoadConfig()
 	db_conn.InitDatabase()
 
-	// 初始化 Kafka Producer
+	precision, err := services.ParsePrecisionPolicy(config.Config.AmountPrecisionPolicy)
+	if err != nil {
+		log.Fatalf("❌ Invalid config: %v", err)
+	}
+
+	// 初始化 event bus，log / noop 會丟棄事件，只允許在 development 使用
 	kafkaBroker := config.Config.KafkaBroker
 	if kafkaBroker == "" {
 		kafkaBroker = "localhost:9092"
 	}
-	producer := kafka_client.NewKafkaProducer(kafkaBroker, "tx.created")
+	if !eventbus.IsDurable(config.Config.EventBus) && config.Config.AppEnv != "development" {
+		log.Fatalf("❌ event_bus %q drops events and is only allowed in development", config.Config.EventBus)
+	}
+	publisher, err := eventbus.New(eventbus.Config{Backend: config.Config.EventBus, KafkaBroker: kafkaBroker})
+	if err != nil {
+		log.Fatalf("❌ Invalid config: %v", err)
+	}
+	defer publisher.Close()
+
+	// 啟動 Outbox Relay，將 outbox_events 發送到 event bus
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	relay := services.NewOutboxRelay(repositories.NewOutboxRepository(), publisher)
+	go relay.Run(ctx)
+
+	// 消費 tx.created 產生用戶通知，無法處理的訊息送到死信 topic；沒有 Kafka 時不啟動
+	if config.Config.NotificationConsumerGroup != "" && eventbus.IsDurable(config.Config.EventBus) {
+		notifications := services.NewNotificationService(repositories.NewNotificationRepository())
+		consumer := kafka_client.NewConsumer(kafka_client.ConsumerConfig{
+			Brokers: []string{kafkaBroker},
+			GroupID: config.Config.NotificationConsumerGroup,
+			Topics:  []string{kafka_client.TopicTxCreated},
+		}, notifications.HandleTxCreated, publisher)
+		defer consumer.Close()
+		go consumer.Run(ctx)
+	}
+
+	// 消費交易事件建立 webhook 投遞，並定期送出到期的投遞
+	webhookService := services.NewWebhookService(repositories.NewWebhookRepository(), services.WebhookOptions{
+		AllowInsecureURLs: config.Config.AppEnv == "development",
+	})
+	if config.Config.WebhookConsumerGroup != "" && eventbus.IsDurable(config.Config.EventBus) {
+		consumer := kafka_client.NewConsumer(kafka_client.ConsumerConfig{
+			Brokers: []string{kafkaBroker},
+			GroupID: config.Config.WebhookConsumerGroup,
+			Topics:  []string{kafka_client.TopicTxCreated, kafka_client.TopicTxStatusChanged},
+		}, webhookService.HandleEvent, publisher)
+		defer consumer.Close()
+		go consumer.Run(ctx)
+	}
+	if interval, err := time.ParseDuration(config.Config.WebhookDeliveryInterval); err == nil && interval > 0 {
+		go webhookService.RunScheduler(ctx, interval)
+	}
+
+	// 定期清除過期的 refresh token 與 access token 黑名單
+	go services.PurgeExpiredTokens(ctx, repositories.NewAuthTokenRepository(), time.Hour)
+
+	// 啟動定期對帳
+	if interval, err := time.ParseDuration(config.Config.ReconciliationInterval); err == nil && interval > 0 {
+		reconciliation := services.NewReconciliationService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+		go reconciliation.RunScheduler(ctx, interval)
+	}
+
+	// 執行到期的預約 / 週期轉帳
+	if interval, err := time.ParseDuration(config.Config.ScheduledTransferInterval); err == nil && interval > 0 {
+		txService := services.NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository()).WithPrecisionPolicy(precision)
+		go services.NewScheduledTransferService(txService).RunScheduler(ctx, interval)
+	}
+
+	// 定期為餘額歷史 hash chain 建立 Merkle checkpoint
+	if interval, err := time.ParseDuration(config.Config.AuditCheckpointInterval); err == nil && interval > 0 {
+		go services.NewAuditChainService(repositories.NewWalletRepository()).RunScheduler(ctx, interval)
+	}
+
+	// 初始化 JWT Manager，非 development 環境不接受預設密鑰
+	jwtManager, err := auth.NewJWTManagerFromOptions(auth.Options{
+		AppEnv:        config.Config.AppEnv,
+		Secret:        config.Config.JWTSecret,
+		KeysDir:       config.Config.JWTKeysDir,
+		ActiveKID:     config.Config.JWTActiveKID,
+		TokenDuration: auth.DefaultAccessTokenDuration,
+	})
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize JWT: %v", err)
+	}
+
+	// 交易簽章金鑰，非 development 環境必須設定
+	if config.Config.TxSigningKeysDir != "" {
+		signer, err := txsign.LoadSigner(config.Config.TxSigningKeysDir, config.Config.TxSigningActiveKID)
+		if err != nil {
+			log.Fatalf("❌ Failed to load transaction signing keys: %v", err)
+		}
+		services.TransactionSigner = signer
+	} else if config.Config.AppEnv != "development" {
+		log.Fatal("❌ tx_signing_keys_dir is required outside development")
+	} else {
+		log.Println("⚠️ Signing transactions with an ephemeral key, signatures will not verify after a restart")
+	}
+
+	// 指派第一位管理員
+	if config.Config.BootstrapAdmin != "" {
+		userService := services.NewUserService(repositories.NewUserRepository(), repositories.NewWalletRepository(), repositories.NewCurrencyRepository())
+		if err := userService.EnsureRole(config.Config.BootstrapAdmin, models.RoleAdmin); err != nil {
+			log.Printf("⚠️ Failed to promote bootstrap admin %q: %v", config.Config.BootstrapAdmin, err)
+		}
+	}
+
+	// 兌換匯率：未設定匯率檔時所有兌換都會回傳 RATE_UNAVAILABLE
+	rateProvider := rates.NewStaticProvider(nil)
+	if config.Config.RatesFile != "" {
+		if rateProvider, err = rates.LoadFileProvider(config.Config.RatesFile); err != nil {
+			log.Fatalf("❌ Failed to load exchange rates: %v", err)
+		}
+	}
+	swapOptions := services.SwapOptions{SpreadBps: config.Config.SwapSpreadBps, PrecisionPolicy: precision}
+	if ttl, err := time.ParseDuration(config.Config.SwapQuoteTTL); err == nil {
+		swapOptions.QuoteTTL = ttl
+	}
+
+	// 即時推送的 heartbeat 間隔，未設定時使用預設值
+	streamHeartbeat, _ := time.ParseDuration(config.Config.StreamHeartbeatInterval)
 
-	r := router.SetupRouter(producer)
+	r := router.SetupRouter(jwtManager, rateProvider, precision, swapOptions, webhookService, streamHeartbeat)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log"
+	"mini-crypto-wallet-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const auditDetailsKey = "audit_details"
+
+// AuditRecorder 保存管理操作的稽核紀錄
+type AuditRecorder interface {
+	RecordAdminAction(entry *models.AdminAuditLog) error
+}
+
+// AuditAdminActions 在請求完成後記錄操作者、路由與結果，被拒絕的請求也會記錄
+// 必須放在 AuthMiddleware 之後、RequireRole 之前
+func AuditAdminActions(recorder AuditRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		userID, _ := c.Get("user_id")
+		actorID, _ := userID.(uint)
+
+		entry := &models.AdminAuditLog{
+			ActorID:    actorID,
+			ActorRole:  CurrentRole(c),
+			Action:     c.Request.Method + " " + c.FullPath(),
+			Path:       c.Request.URL.Path,
+			StatusCode: c.Writer.Status(),
+			ClientIP:   c.ClientIP(),
+			TraceID:    c.GetString(TraceIDKey),
+		}
+		if details, ok := c.Get(auditDetailsKey); ok {
+			if encoded, err := json.Marshal(details); err == nil {
+				entry.Details = string(encoded)
+			}
+		}
+
+		if err := recorder.RecordAdminAction(entry); err != nil {
+			log.Println("⚠️ Failed to record admin audit log:", err)
+		}
+	}
+}
+
+// AddAuditDetail 為本次管理操作的稽核紀錄加上細節（例如凍結原因、新角色）
+func AddAuditDetail(c *gin.Context, key string, value interface{}) {
+	details, _ := c.Get(auditDetailsKey)
+	detailMap, ok := details.(map[string]interface{})
+	if !ok {
+		detailMap = make(map[string]interface{})
+		c.Set(auditDetailsKey, detailMap)
+	}
+	detailMap[key] = value
+}
@@ -5,11 +5,18 @@ import (
 	"strings"
 
 	"mini-crypto-wallet-api/internal/auth"
+	apperrors "mini-crypto-wallet-api/internal/errors"
+	"mini-crypto-wallet-api/models"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+// TokenRevocationChecker 查詢 access token 是否已被撤銷（登出或 refresh token 重用）
+type TokenRevocationChecker interface {
+	IsTokenRevoked(jti string) (bool, error)
+}
+
+func AuthMiddleware(jwtManager *auth.JWTManager, revocations TokenRevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -33,9 +40,32 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			return
 		}
 
+		// 沒有 jti 的 token 無法撤銷，一律拒絕
+		if claims.ID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": auth.ErrInvalidToken.Error()})
+			c.Abort()
+			return
+		}
+		if revocations != nil {
+			revoked, err := revocations.IsTokenRevoked(claims.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify token"})
+				c.Abort()
+				return
+			}
+			if revoked {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "token revoked", "code": apperrors.ErrCodeTokenRevoked})
+				c.Abort()
+				return
+			}
+		}
+
 		// 將用戶信息存儲到 context 中
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("role", claims.Role)
+		c.Set("jti", claims.ID)
+		c.Set("token_expires_at", claims.ExpiresAt.Time)
 
 		c.Next()
 	}
@@ -58,3 +88,40 @@ func RequireUserID(c *gin.Context, targetUserID uint) bool {
 
 	return true
 }
+
+// CurrentUserID 取得目前登入用戶的 ID，未登入時回傳 401
+func CurrentUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	uid, ok := userID.(uint)
+	if !exists || !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Abort()
+		return 0, false
+	}
+
+	return uid, true
+}
+
+// CurrentRole 取得目前登入用戶的角色，舊 token 沒有角色時視為一般用戶
+func CurrentRole(c *gin.Context) string {
+	if role := c.GetString("role"); role != "" {
+		return role
+	}
+	return models.RoleUser
+}
+
+// RequireRole 只允許指定角色的用戶通過，必須放在 AuthMiddleware 之後
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := CurrentRole(c)
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden: insufficient role", "code": apperrors.ErrCodeForbidden})
+		c.Abort()
+	}
+}
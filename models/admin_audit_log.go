@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// AdminAuditLog records one request made against the /admin APIs, including denied attempts
+type AdminAuditLog struct {
+	ID         uint   `gorm:"primarykey"`
+	ActorID    uint   `gorm:"index;not null"`
+	ActorRole  string `gorm:"size:20;not null"`
+	Action     string `gorm:"size:150;not null;index"` // HTTP method and route, e.g. POST /admin/users/:id/freeze
+	Path       string `gorm:"size:255;not null"`       // Concrete request path including IDs
+	Details    string `gorm:"type:text"`               // JSON encoded action details (reason, new role, ...)
+	StatusCode int    `gorm:"not null"`
+	ClientIP   string `gorm:"size:64"`
+	TraceID    string `gorm:"size:64"`
+	CreatedAt  time.Time
+}
+
+// TableName specifies the table name for GORM
+func (AdminAuditLog) TableName() string {
+	return "admin_audit_logs"
+}
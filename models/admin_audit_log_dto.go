@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// AdminAuditLogResponse represents the HTTP response for an audit log entry
+type AdminAuditLogResponse struct {
+	ID         uint      `json:"id" example:"1"`
+	ActorID    uint      `json:"actor_id" example:"1"`
+	ActorRole  string    `json:"actor_role" example:"admin"`
+	Action     string    `json:"action" example:"POST /admin/users/:id/freeze"`
+	Path       string    `json:"path" example:"/admin/users/7/freeze"`
+	Details    string    `json:"details,omitempty"`
+	StatusCode int       `json:"status_code" example:"200"`
+	ClientIP   string    `json:"client_ip"`
+	TraceID    string    `json:"trace_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ToAdminAuditLogResponses converts a slice of AdminAuditLog models to DTOs
+func ToAdminAuditLogResponses(logs []AdminAuditLog) []AdminAuditLogResponse {
+	responses := make([]AdminAuditLogResponse, len(logs))
+	for i, log := range logs {
+		responses[i] = AdminAuditLogResponse{
+			ID:         log.ID,
+			ActorID:    log.ActorID,
+			ActorRole:  log.ActorRole,
+			Action:     log.Action,
+			Path:       log.Path,
+			Details:    log.Details,
+			StatusCode: log.StatusCode,
+			ClientIP:   log.ClientIP,
+			TraceID:    log.TraceID,
+			CreatedAt:  log.CreatedAt,
+		}
+	}
+	return responses
+}
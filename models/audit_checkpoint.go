@@ -0,0 +1,41 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// AuditCheckpoint records the Merkle root over the head of every wallet's balance history chain
+// Exported roots let an auditor prove later that no chain was rewritten or truncated
+type AuditCheckpoint struct {
+	ID          uint   `gorm:"primarykey"`
+	MerkleRoot  string `gorm:"size:64;not null"`
+	WalletCount int    `gorm:"not null;default:0"`
+	EntryCount  uint64 `gorm:"not null;default:0"` // Sum of the head sequences
+	CreatedAt   time.Time
+}
+
+// TableName specifies the table name for GORM
+func (AuditCheckpoint) TableName() string {
+	return "audit_checkpoints"
+}
+
+// AuditCheckpointLeaf is one wallet's chain head included in a checkpoint
+type AuditCheckpointLeaf struct {
+	ID           uint   `gorm:"primarykey"`
+	CheckpointID uint   `gorm:"not null;index"`
+	Position     int    `gorm:"not null"` // Leaf order in the Merkle tree (by wallet ID)
+	WalletID     uint   `gorm:"not null"`
+	Sequence     uint64 `gorm:"not null"`
+	EntryHash    string `gorm:"size:64;not null"`
+}
+
+// TableName specifies the table name for GORM
+func (AuditCheckpointLeaf) TableName() string {
+	return "audit_checkpoint_leaves"
+}
+
+// LeafData returns the bytes hashed into the Merkle tree: "<wallet_id>:<sequence>:<entry_hash>"
+func (l *AuditCheckpointLeaf) LeafData() []byte {
+	return []byte(fmt.Sprintf("%d:%d:%s", l.WalletID, l.Sequence, l.EntryHash))
+}
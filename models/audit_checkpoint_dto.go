@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// AuditCheckpointResponse represents the HTTP response for an audit checkpoint
+// Leaves are only included when exporting a single checkpoint
+type AuditCheckpointResponse struct {
+	ID          uint                          `json:"id" example:"1"`
+	MerkleRoot  string                        `json:"merkle_root" example:"9f86d081884c7d65..."`
+	WalletCount int                           `json:"wallet_count" example:"120"`
+	EntryCount  uint64                        `json:"entry_count" example:"4500"`
+	CreatedAt   time.Time                     `json:"created_at"`
+	Leaves      []AuditCheckpointLeafResponse `json:"leaves,omitempty"`
+}
+
+// AuditCheckpointLeafResponse represents one wallet chain head in a checkpoint
+type AuditCheckpointLeafResponse struct {
+	WalletID  uint   `json:"wallet_id" example:"3"`
+	Sequence  uint64 `json:"sequence" example:"42"`
+	EntryHash string `json:"entry_hash" example:"2c26b46b68ffc68f..."`
+}
+
+// AuditChainBreak points to the first broken link of one wallet's chain
+type AuditChainBreak struct {
+	WalletID  uint   `json:"wallet_id" example:"3"`
+	Sequence  uint64 `json:"sequence" example:"17"`
+	HistoryID uint   `json:"history_id,omitempty" example:"981"` // Empty when the entry is missing
+	Reason    string `json:"reason" example:"entry hash does not match its contents"`
+}
+
+// AuditChainReport represents the result of verifying the balance history chains
+type AuditChainReport struct {
+	Intact         bool              `json:"intact" example:"true"`
+	WalletsChecked int               `json:"wallets_checked" example:"120"`
+	EntriesChecked int               `json:"entries_checked" example:"4500"`
+	CheckpointID   *uint             `json:"checkpoint_id,omitempty" example:"7"` // Latest checkpoint the chains were compared with
+	Breaks         []AuditChainBreak `json:"breaks"`
+}
+
+// ToAuditCheckpointResponse converts an AuditCheckpoint model and its leaves to DTO
+func ToAuditCheckpointResponse(checkpoint *AuditCheckpoint, leaves []AuditCheckpointLeaf) *AuditCheckpointResponse {
+	response := &AuditCheckpointResponse{
+		ID:          checkpoint.ID,
+		MerkleRoot:  checkpoint.MerkleRoot,
+		WalletCount: checkpoint.WalletCount,
+		EntryCount:  checkpoint.EntryCount,
+		CreatedAt:   checkpoint.CreatedAt,
+	}
+	for _, leaf := range leaves {
+		response.Leaves = append(response.Leaves, AuditCheckpointLeafResponse{
+			WalletID:  leaf.WalletID,
+			Sequence:  leaf.Sequence,
+			EntryHash: leaf.EntryHash,
+		})
+	}
+	return response
+}
+
+// ToAuditCheckpointResponses converts a slice of AuditCheckpoint models to DTOs without leaves
+func ToAuditCheckpointResponses(checkpoints []AuditCheckpoint) []AuditCheckpointResponse {
+	responses := make([]AuditCheckpointResponse, len(checkpoints))
+	for i := range checkpoints {
+		responses[i] = *ToAuditCheckpointResponse(&checkpoints[i], nil)
+	}
+	return responses
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// RefreshToken is a rotating refresh token; only the SHA256 hash of the token is stored
+// Tokens issued from the same login share a FamilyID so a reused token can revoke the whole chain
+type RefreshToken struct {
+	ID              uint   `gorm:"primarykey"`
+	UserID          uint   `gorm:"index;not null"`
+	FamilyID        string `gorm:"index;size:32;not null"`
+	TokenHash       string `gorm:"uniqueIndex;size:64;not null"`
+	AccessJTI       string `gorm:"size:32"` // Access token issued together with this refresh token
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time  `gorm:"index;not null"`
+	RotatedAt       *time.Time // Set once the token has been exchanged for a new pair
+	RevokedAt       *time.Time
+	CreatedAt       time.Time
+}
+
+// TableName specifies the table name for GORM
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// IsUsable reports whether the token can still be exchanged at now
+func (t *RefreshToken) IsUsable(now time.Time) bool {
+	return t.RotatedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// RevokedToken is a denylisted access token jti, kept until the token would have expired
+type RevokedToken struct {
+	ID        uint      `gorm:"primarykey"`
+	JTI       string    `gorm:"column:jti;uniqueIndex;size:32;not null"`
+	UserID    uint      `gorm:"index;not null"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time
+}
+
+// TableName specifies the table name for GORM
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
@@ -1,20 +1,106 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"time"
 
 	"github.com/shopspring/decimal"
 )
 
+// BalanceHistory change types
+// Balance* fields always describe the wallet's available balance
+const (
+	ChangeTypeCredit  = "credit"  // available balance increases
+	ChangeTypeDebit   = "debit"   // available balance decreases
+	ChangeTypeHold    = "hold"    // funds move from available to held (withdrawal requested)
+	ChangeTypeRelease = "release" // held funds return to available (withdrawal failed/cancelled)
+	ChangeTypeSettle  = "settle"  // held funds leave the wallet (withdrawal completed)
+	ChangeTypeStatus  = "status"  // lifecycle transition without balance movement
+)
+
 // BalanceHistory 餘額變動歷史記錄
+// 每個錢包的紀錄以 Sequence 排成一條 hash chain：EntryHash 涵蓋 PrevHash，修改或刪除任何一筆都會斷鏈
+// Sequence 為 0 的紀錄寫於啟用 hash chain 之前，不在鏈上
 type BalanceHistory struct {
 	ID            uint            `json:"id" gorm:"primarykey"`
 	UserID        uint            `json:"user_id" gorm:"index"`
-	WalletID      uint            `json:"wallet_id" gorm:"index"`
+	WalletID      uint            `json:"wallet_id" gorm:"index;uniqueIndex:idx_balance_history_wallet_seq,priority:1,where:sequence > 0"`
 	TransactionID uint            `json:"transaction_id" gorm:"index"`
-	ChangeType   string           `json:"change_type"` // credit, debit
+	ChangeType    string          `json:"change_type"`                     // credit, debit, hold, release, settle, status
+	Status        string          `json:"status,omitempty" gorm:"size:50"` // Transaction status after this change
 	Amount        decimal.Decimal `json:"amount" gorm:"type:decimal(20,8)"`
 	BalanceBefore decimal.Decimal `json:"balance_before" gorm:"type:decimal(20,8)"`
 	BalanceAfter  decimal.Decimal `json:"balance_after" gorm:"type:decimal(20,8)"`
 	CreatedAt     time.Time       `json:"created_at"`
+
+	Sequence  uint64 `json:"sequence" gorm:"not null;default:0;uniqueIndex:idx_balance_history_wallet_seq,priority:2,where:sequence > 0"` // 1-based position in the wallet's chain, unique per wallet
+	PrevHash  string `json:"prev_hash,omitempty" gorm:"size:64"`                                                                          // EntryHash of the previous entry; empty for the first
+	EntryHash string `json:"entry_hash,omitempty" gorm:"size:64"`                                                                         // SHA256 of EntryPayload
+}
+
+// balanceHistoryEntryPayload fixes the field order of the hashed JSON document
+type balanceHistoryEntryPayload struct {
+	Version       int    `json:"v"`
+	WalletID      uint   `json:"wallet_id"`
+	Sequence      uint64 `json:"sequence"`
+	PrevHash      string `json:"prev_hash"`
+	UserID        uint   `json:"user_id"`
+	TransactionID uint   `json:"transaction_id"`
+	ChangeType    string `json:"change_type"`
+	Status        string `json:"status"`
+	Amount        string `json:"amount"`
+	BalanceBefore string `json:"balance_before"`
+	BalanceAfter  string `json:"balance_after"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// EntryPayload returns the canonical encoding EntryHash is computed from
+// Same conventions as Transaction.HashPayload
+func (h *BalanceHistory) EntryPayload() []byte {
+	data, _ := json.Marshal(balanceHistoryEntryPayload{
+		Version:       1,
+		WalletID:      h.WalletID,
+		Sequence:      h.Sequence,
+		PrevHash:      h.PrevHash,
+		UserID:        h.UserID,
+		TransactionID: h.TransactionID,
+		ChangeType:    h.ChangeType,
+		Status:        h.Status,
+		Amount:        h.Amount.String(),
+		BalanceBefore: h.BalanceBefore.String(),
+		BalanceAfter:  h.BalanceAfter.String(),
+		CreatedAt:     canonicalTime(h.CreatedAt),
+	})
+	return data
+}
+
+// ComputeEntryHash returns the SHA256 of EntryPayload
+func (h *BalanceHistory) ComputeEntryHash() string {
+	hash := sha256.Sum256(h.EntryPayload())
+	return hex.EncodeToString(hash[:])
+}
+
+// LinkTo appends the entry after prev in the wallet's chain
+// prev is the zero value when the wallet has no chained entries yet
+func (h *BalanceHistory) LinkTo(prev *BalanceHistory) {
+	if h.CreatedAt.IsZero() {
+		h.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	}
+	h.Sequence = prev.Sequence + 1
+	h.PrevHash = prev.EntryHash
+	h.EntryHash = h.ComputeEntryHash()
+}
+
+// AvailableDelta returns the signed change this entry applied to the wallet's available balance
+func (h *BalanceHistory) AvailableDelta() decimal.Decimal {
+	switch h.ChangeType {
+	case ChangeTypeCredit, ChangeTypeRelease:
+		return h.Amount
+	case ChangeTypeDebit, ChangeTypeHold:
+		return h.Amount.Neg()
+	default:
+		return decimal.Zero
+	}
 }
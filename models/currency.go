@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 // Currency represents the database model for currency/cryptocurrency data
 // Pure GORM model - no JSON/binding tags for HTTP layer separation
@@ -19,3 +23,13 @@ type Currency struct {
 func (Currency) TableName() string {
 	return "currencies"
 }
+
+// FitsPrecision reports whether amount has no more decimal places than the currency allows
+func (c *Currency) FitsPrecision(amount decimal.Decimal) bool {
+	return amount.Equal(amount.Truncate(int32(c.Decimals)))
+}
+
+// FormatAmount renders amount with exactly the currency's number of decimal places
+func (c *Currency) FormatAmount(amount decimal.Decimal) string {
+	return amount.StringFixed(int32(c.Decimals))
+}
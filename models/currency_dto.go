@@ -37,3 +37,24 @@ func ToCurrencyResponses(currencies []Currency) []CurrencyResponse {
 	}
 	return responses
 }
+
+// MaxCurrencyDecimals is the largest precision wallet balances can store (decimal(20,8))
+const MaxCurrencyDecimals = 8
+
+// CreateCurrencyRequest represents the HTTP request body for creating a currency
+type CreateCurrencyRequest struct {
+	Code     string `json:"code" binding:"required,alphanum,max=10" example:"USDC"`
+	Name     string `json:"name" binding:"required,max=100" example:"USD Coin"`
+	Symbol   string `json:"symbol" binding:"required,max=10" example:"$"`
+	Decimals *int   `json:"decimals" binding:"required,min=0,max=8" example:"6"`
+	IsActive *bool  `json:"is_active" example:"true"` // Defaults to true
+}
+
+// UpdateCurrencyRequest represents the HTTP request body for editing a currency
+// Omitted fields are left unchanged
+type UpdateCurrencyRequest struct {
+	Name     *string `json:"name" binding:"omitempty,min=1,max=100" example:"USD Coin"`
+	Symbol   *string `json:"symbol" binding:"omitempty,min=1,max=10" example:"$"`
+	Decimals *int    `json:"decimals" binding:"omitempty,min=0,max=8" example:"6"`
+	IsActive *bool   `json:"is_active" example:"true"`
+}
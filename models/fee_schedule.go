@@ -0,0 +1,90 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Fee schedule types
+const (
+	FeeTypeFlat       = "flat"       // FlatFee on every transfer
+	FeeTypePercentage = "percentage" // Rate × amount
+	FeeTypeTiered     = "tiered"     // FlatFee + Rate × amount of the tier the amount falls into
+)
+
+// FeeSchedule defines the transfer fee of one currency
+// The fee is paid by the sender on top of the transfer amount and is clamped to [MinFee, MaxFee]
+type FeeSchedule struct {
+	ID         uint            `gorm:"primarykey"`
+	CurrencyID uint            `gorm:"uniqueIndex;not null"`
+	Type       string          `gorm:"size:20;not null"`                      // flat, percentage, tiered
+	FlatFee    decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0"` // Used by flat schedules
+	Rate       decimal.Decimal `gorm:"type:decimal(10,8);not null;default:0"` // Fraction of the amount (0.001 = 0.1%), used by percentage schedules
+	MinFee     decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0"`
+	MaxFee     decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0"` // 0 means no cap
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+
+	Tiers []FeeTier `gorm:"foreignKey:ScheduleID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName specifies the table name for GORM
+func (FeeSchedule) TableName() string {
+	return "fee_schedules"
+}
+
+// FeeTier is one bracket of a tiered schedule; it applies to amounts from MinAmount up to the next tier
+type FeeTier struct {
+	ID         uint            `gorm:"primarykey"`
+	ScheduleID uint            `gorm:"index;not null"`
+	MinAmount  decimal.Decimal `gorm:"type:decimal(20,8);not null"`
+	FlatFee    decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0"`
+	Rate       decimal.Decimal `gorm:"type:decimal(10,8);not null;default:0"`
+}
+
+// TableName specifies the table name for GORM
+func (FeeTier) TableName() string {
+	return "fee_tiers"
+}
+
+// Calculate returns the fee for amount, rounded up to the currency's decimals
+// Domain logic method - the schedule decides its own pricing
+func (s *FeeSchedule) Calculate(amount decimal.Decimal, decimals int) decimal.Decimal {
+	var fee decimal.Decimal
+	switch s.Type {
+	case FeeTypeFlat:
+		fee = s.FlatFee
+	case FeeTypePercentage:
+		fee = amount.Mul(s.Rate)
+	case FeeTypeTiered:
+		if tier := s.tierFor(amount); tier != nil {
+			fee = tier.FlatFee.Add(amount.Mul(tier.Rate))
+		}
+	}
+
+	if fee.LessThan(s.MinFee) {
+		fee = s.MinFee
+	}
+	if s.MaxFee.IsPositive() && fee.GreaterThan(s.MaxFee) {
+		fee = s.MaxFee
+	}
+	return fee.RoundUp(int32(decimals))
+}
+
+// tierFor returns the tier with the highest MinAmount not above amount
+func (s *FeeSchedule) tierFor(amount decimal.Decimal) *FeeTier {
+	tiers := make([]FeeTier, len(s.Tiers))
+	copy(tiers, s.Tiers)
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinAmount.LessThan(tiers[j].MinAmount) })
+
+	var match *FeeTier
+	for i := range tiers {
+		if tiers[i].MinAmount.GreaterThan(amount) {
+			break
+		}
+		match = &tiers[i]
+	}
+	return match
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FeeScheduleRequest represents the HTTP request body for setting a currency's fee schedule
+type FeeScheduleRequest struct {
+	Type    string           `json:"type" binding:"required,oneof=flat percentage tiered" example:"percentage"`
+	FlatFee decimal.Decimal  `json:"flat_fee" swaggertype:"number" example:"0"`
+	Rate    decimal.Decimal  `json:"rate" swaggertype:"number" example:"0.001"` // Fraction of the amount (0.001 = 0.1%)
+	MinFee  decimal.Decimal  `json:"min_fee" swaggertype:"number" example:"0.5"`
+	MaxFee  decimal.Decimal  `json:"max_fee" swaggertype:"number" example:"25"` // 0 means no cap
+	Tiers   []FeeTierRequest `json:"tiers" binding:"omitempty,dive"`
+}
+
+// FeeTierRequest represents one tier of a tiered fee schedule
+type FeeTierRequest struct {
+	MinAmount decimal.Decimal `json:"min_amount" swaggertype:"number" example:"0"`
+	FlatFee   decimal.Decimal `json:"flat_fee" swaggertype:"number" example:"1"`
+	Rate      decimal.Decimal `json:"rate" swaggertype:"number" example:"0.002"`
+}
+
+// FeeScheduleResponse represents the HTTP response for a fee schedule
+type FeeScheduleResponse struct {
+	CurrencyID uint              `json:"currency_id" example:"1"`
+	Type       string            `json:"type" example:"percentage"`
+	FlatFee    decimal.Decimal   `json:"flat_fee" swaggertype:"number" example:"0"`
+	Rate       decimal.Decimal   `json:"rate" swaggertype:"number" example:"0.001"`
+	MinFee     decimal.Decimal   `json:"min_fee" swaggertype:"number" example:"0.5"`
+	MaxFee     decimal.Decimal   `json:"max_fee" swaggertype:"number" example:"25"`
+	Tiers      []FeeTierResponse `json:"tiers,omitempty"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// FeeTierResponse represents one tier of a tiered fee schedule
+type FeeTierResponse struct {
+	MinAmount decimal.Decimal `json:"min_amount" swaggertype:"number" example:"0"`
+	FlatFee   decimal.Decimal `json:"flat_fee" swaggertype:"number" example:"1"`
+	Rate      decimal.Decimal `json:"rate" swaggertype:"number" example:"0.002"`
+}
+
+// TransferQuoteRequest represents the HTTP request body for quoting a transfer fee
+type TransferQuoteRequest struct {
+	CurrencyID uint            `json:"currency_id" binding:"required" example:"1"`
+	Amount     decimal.Decimal `json:"amount" binding:"required" swaggertype:"number" example:"150.0"`
+}
+
+// TransferQuoteResponse represents the fee a transfer would be charged
+// The recipient receives Amount; the sender pays Total = Amount + Fee
+type TransferQuoteResponse struct {
+	CurrencyID uint            `json:"currency_id" example:"1"`
+	Amount     decimal.Decimal `json:"amount" swaggertype:"number" example:"150.0"`
+	Fee        decimal.Decimal `json:"fee" swaggertype:"number" example:"0.15"`
+	Total      decimal.Decimal `json:"total" swaggertype:"number" example:"150.15"`
+}
+
+// ToFeeScheduleResponse converts a FeeSchedule model to FeeScheduleResponse DTO
+func ToFeeScheduleResponse(schedule *FeeSchedule) *FeeScheduleResponse {
+	response := &FeeScheduleResponse{
+		CurrencyID: schedule.CurrencyID,
+		Type:       schedule.Type,
+		FlatFee:    schedule.FlatFee,
+		Rate:       schedule.Rate,
+		MinFee:     schedule.MinFee,
+		MaxFee:     schedule.MaxFee,
+		UpdatedAt:  schedule.UpdatedAt,
+	}
+	for _, tier := range schedule.Tiers {
+		response.Tiers = append(response.Tiers, FeeTierResponse{
+			MinAmount: tier.MinAmount,
+			FlatFee:   tier.FlatFee,
+			Rate:      tier.Rate,
+		})
+	}
+	return response
+}
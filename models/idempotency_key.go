@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// IdempotencyKey stores the outcome of a request sent with an Idempotency-Key header
+// Keys are scoped per user; a replay within the retention window returns the stored response
+type IdempotencyKey struct {
+	ID           uint      `gorm:"primarykey"`
+	UserID       uint      `gorm:"uniqueIndex:idx_idempotency_user_key;not null"`
+	Key          string    `gorm:"column:idempotency_key;uniqueIndex:idx_idempotency_user_key;size:255;not null"`
+	RequestHash  string    `gorm:"size:64;not null"` // SHA256 fingerprint of the request body
+	StatusCode   int       `gorm:"not null"`
+	ResponseBody string    `gorm:"type:text;not null"` // JSON encoded response
+	ExpiresAt    time.Time `gorm:"index;not null"`
+	CreatedAt    time.Time
+}
+
+// TableName specifies the table name for GORM
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
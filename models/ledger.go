@@ -0,0 +1,101 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Ledger account kinds
+const (
+	LedgerKindWalletAvailable = "wallet_available"
+	LedgerKindWalletHeld      = "wallet_held"
+	LedgerKindFees            = "system_fees"
+	LedgerKindDeposits        = "system_deposits"
+	LedgerKindWithdrawals     = "system_withdrawals"
+	LedgerKindExchange        = "system_exchange" // Takes the other side of swaps
+	LedgerKindOpening         = "system_opening"  // Balances that existed before the ledger was introduced
+)
+
+// Posting directions
+const (
+	PostingDebit  = "debit"
+	PostingCredit = "credit"
+)
+
+// Journal entry types
+const (
+	JournalTransfer          = "transfer"
+	JournalDeposit           = "deposit"
+	JournalWithdrawalHold    = "withdrawal_hold"
+	JournalWithdrawalSettle  = "withdrawal_settle"
+	JournalWithdrawalRelease = "withdrawal_release"
+	JournalOpening           = "opening_balance"
+	JournalSwap              = "swap" // Includes the spread fee posting
+	JournalReversal          = "reversal"
+	JournalRefund            = "refund"
+)
+
+// LedgerAccount is an account of the double-entry ledger
+// Every wallet owns an available and a held account; system accounts exist once per currency
+// Balance caches credits minus debits so it can be compared with the wallet inside the same DB transaction
+type LedgerAccount struct {
+	ID         uint            `gorm:"primarykey"`
+	Code       string          `gorm:"uniqueIndex;size:100;not null"` // e.g. wallet:12:available, system:1:fees
+	Kind       string          `gorm:"size:30;not null;index"`
+	WalletID   *uint           `gorm:"index"`
+	CurrencyID uint            `gorm:"index;not null"`
+	Balance    decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// TableName specifies the table name for GORM
+func (LedgerAccount) TableName() string {
+	return "ledger_accounts"
+}
+
+// WalletAccountCode returns the ledger account code of a wallet's available or held funds
+func WalletAccountCode(walletID uint, held bool) string {
+	if held {
+		return fmt.Sprintf("wallet:%d:held", walletID)
+	}
+	return fmt.Sprintf("wallet:%d:available", walletID)
+}
+
+// SystemAccountCode returns the ledger account code of a per-currency system account
+func SystemAccountCode(kind string, currencyID uint) string {
+	return fmt.Sprintf("system:%d:%s", currencyID, kind)
+}
+
+// JournalEntry groups the postings of one balanced accounting event
+type JournalEntry struct {
+	ID            uint   `gorm:"primarykey"`
+	TransactionID uint   `gorm:"index"` // 0 for entries not tied to a transaction (opening balances)
+	EntryType     string `gorm:"size:30;not null"`
+	CreatedAt     time.Time
+
+	Postings []LedgerPosting `gorm:"foreignKey:JournalEntryID"`
+}
+
+// TableName specifies the table name for GORM
+func (JournalEntry) TableName() string {
+	return "journal_entries"
+}
+
+// LedgerPosting is a single debit or credit line of a journal entry
+type LedgerPosting struct {
+	ID             uint            `gorm:"primarykey"`
+	JournalEntryID uint            `gorm:"index;not null"`
+	AccountID      uint            `gorm:"index;not null"`
+	CurrencyID     uint            `gorm:"not null"`
+	Direction      string          `gorm:"size:6;not null"` // debit, credit
+	Amount         decimal.Decimal `gorm:"type:decimal(20,8);not null"`
+	CreatedAt      time.Time
+}
+
+// TableName specifies the table name for GORM
+func (LedgerPosting) TableName() string {
+	return "ledger_postings"
+}
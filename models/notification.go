@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Notification types
+const (
+	NotificationTransferReceived = "transfer_received"
+	NotificationTransferSent     = "transfer_sent"
+	NotificationRefundReceived   = "refund_received"
+	NotificationRefundSent       = "refund_sent"
+	NotificationTransferReversed = "transfer_reversed"
+	NotificationSwapCompleted    = "swap_completed"
+)
+
+// Notification is a per-user message derived from a transaction event
+// (user_id, event_key) is unique so a redelivered Kafka message creates no duplicates
+type Notification struct {
+	ID                 uint            `gorm:"primarykey"`
+	UserID             uint            `gorm:"not null;uniqueIndex:idx_notification_user_event;index:idx_notification_user_read"`
+	EventKey           string          `gorm:"size:100;not null;uniqueIndex:idx_notification_user_event"` // Hash of the transaction that produced it
+	Type               string          `gorm:"size:40;not null"`
+	TransactionHash    string          `gorm:"size:64;not null"`
+	CounterpartyUserID uint            `gorm:"not null;default:0"` // 0 when the user is on both sides (swaps)
+	Amount             decimal.Decimal `gorm:"type:decimal(20,8);not null"`
+	Message            string          `gorm:"size:255;not null"`
+	ReadAt             *time.Time      `gorm:"index:idx_notification_user_read"`
+	CreatedAt          time.Time
+}
+
+// TableName specifies the table name for GORM
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// IsRead reports whether the user has marked the notification as read
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
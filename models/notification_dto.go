@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// NotificationListRequest represents the query parameters for listing notifications
+type NotificationListRequest struct {
+	PaginationRequest
+	Unread bool `form:"unread" json:"unread" example:"true"` // Only return unread notifications
+}
+
+// NotificationResponse represents the HTTP response for a notification
+type NotificationResponse struct {
+	ID                 uint            `json:"id" example:"1"`
+	Type               string          `json:"type" example:"transfer_received"`
+	TransactionHash    string          `json:"transaction_hash" example:"9f2c..."`
+	CounterpartyUserID uint            `json:"counterparty_user_id,omitempty" example:"2"`
+	Amount             decimal.Decimal `json:"amount" swaggertype:"number" example:"100.5"`
+	Message            string          `json:"message" example:"You received 100.5 from user 2"`
+	Read               bool            `json:"read" example:"false"`
+	ReadAt             *time.Time      `json:"read_at,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+}
+
+// ToNotificationResponse converts a Notification model to NotificationResponse DTO
+func ToNotificationResponse(n *Notification) *NotificationResponse {
+	return &NotificationResponse{
+		ID:                 n.ID,
+		Type:               n.Type,
+		TransactionHash:    n.TransactionHash,
+		CounterpartyUserID: n.CounterpartyUserID,
+		Amount:             n.Amount,
+		Message:            n.Message,
+		Read:               n.IsRead(),
+		ReadAt:             n.ReadAt,
+		CreatedAt:          n.CreatedAt,
+	}
+}
+
+// ToNotificationResponses converts a slice of Notification models to NotificationResponse DTOs
+func ToNotificationResponses(notifications []Notification) []NotificationResponse {
+	responses := make([]NotificationResponse, len(notifications))
+	for i := range notifications {
+		responses[i] = *ToNotificationResponse(&notifications[i])
+	}
+	return responses
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Outbox event statuses
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusPublished = "published"
+)
+
+// OutboxEvent represents a message waiting to be published to Kafka
+// Rows are written inside the same DB transaction as the business change
+// and published later by the outbox relay (transactional outbox pattern)
+type OutboxEvent struct {
+	ID            uint      `gorm:"primarykey"`
+	Topic         string    `gorm:"size:100;not null"`
+	EventKey      string    `gorm:"size:255;not null"`                                               // Kafka message key
+	Payload       string    `gorm:"type:text;not null"`                                              // JSON encoded message body
+	Status        string    `gorm:"size:20;not null;default:'pending';index:idx_outbox_status_next"` // pending, published
+	Attempts      int       `gorm:"not null;default:0"`                                              // Failed publish attempts so far
+	NextAttemptAt time.Time `gorm:"not null;index:idx_outbox_status_next"`                           // Earliest time the relay may retry
+	LastError     string    `gorm:"size:500"`
+	PublishedAt   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TableName specifies the table name for GORM
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
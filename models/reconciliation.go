@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Reconciliation finding types
+const (
+	FindingBalanceDrift     = "balance_drift"      // wallet balance differs from its balance history
+	FindingLedgerDrift      = "ledger_drift"       // wallet balance differs from its ledger postings
+	FindingOrphanedHistory  = "orphaned_history"   // history row points to a missing wallet or transaction
+	FindingOneSidedTransfer = "one_sided_transfer" // transfer histories do not cancel out
+	FindingHashMismatch     = "hash_mismatch"      // stored transaction hash cannot be recomputed from the row
+)
+
+// Reconciliation triggers
+const (
+	ReconciliationTriggerScheduled = "scheduled"
+	ReconciliationTriggerManual    = "manual"
+)
+
+// Reconciliation run statuses
+const (
+	ReconciliationStatusRunning   = "running"
+	ReconciliationStatusCompleted = "completed"
+	ReconciliationStatusFailed    = "failed"
+)
+
+// ReconciliationRun records one execution of the reconciliation job
+type ReconciliationRun struct {
+	ID                  uint   `gorm:"primarykey"`
+	Trigger             string `gorm:"size:20;not null"`
+	Status              string `gorm:"size:20;not null"`
+	WalletsChecked      int    `gorm:"not null;default:0"`
+	TransactionsChecked int    `gorm:"not null;default:0"`
+	FindingCount        int    `gorm:"not null;default:0"`
+	Error               string `gorm:"size:500"`
+	StartedAt           time.Time
+	FinishedAt          *time.Time
+}
+
+// TableName specifies the table name for GORM
+func (ReconciliationRun) TableName() string {
+	return "reconciliation_runs"
+}
+
+// ReconciliationFinding is a single inconsistency detected during a run
+type ReconciliationFinding struct {
+	ID            uint   `gorm:"primarykey"`
+	RunID         uint   `gorm:"index;not null"`
+	Type          string `gorm:"size:30;not null;index"`
+	WalletID      uint   `gorm:"index"`
+	TransactionID uint   `gorm:"index"`
+	HistoryID     uint
+	Expected      decimal.Decimal `gorm:"type:decimal(20,8)"`
+	Actual        decimal.Decimal `gorm:"type:decimal(20,8)"`
+	Detail        string          `gorm:"size:500"`
+	CreatedAt     time.Time
+}
+
+// TableName specifies the table name for GORM
+func (ReconciliationFinding) TableName() string {
+	return "reconciliation_findings"
+}
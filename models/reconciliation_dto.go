@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ReconciliationRunResponse represents the HTTP response for a reconciliation run
+type ReconciliationRunResponse struct {
+	ID                  uint       `json:"id" example:"1"`
+	Trigger             string     `json:"trigger" example:"manual"`
+	Status              string     `json:"status" example:"completed"`
+	WalletsChecked      int        `json:"wallets_checked" example:"120"`
+	TransactionsChecked int        `json:"transactions_checked" example:"4500"`
+	FindingCount        int        `json:"finding_count" example:"0"`
+	Error               string     `json:"error,omitempty"`
+	StartedAt           time.Time  `json:"started_at"`
+	FinishedAt          *time.Time `json:"finished_at,omitempty"`
+}
+
+// ReconciliationFindingResponse represents the HTTP response for a reconciliation finding
+type ReconciliationFindingResponse struct {
+	ID            uint            `json:"id" example:"1"`
+	RunID         uint            `json:"run_id" example:"1"`
+	Type          string          `json:"type" example:"balance_drift"`
+	WalletID      uint            `json:"wallet_id,omitempty" example:"3"`
+	TransactionID uint            `json:"transaction_id,omitempty" example:"42"`
+	HistoryID     uint            `json:"history_id,omitempty"`
+	Expected      decimal.Decimal `json:"expected" swaggertype:"number" example:"900"`
+	Actual        decimal.Decimal `json:"actual" swaggertype:"number" example:"950"`
+	Detail        string          `json:"detail"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// ToReconciliationRunResponse converts a ReconciliationRun model to DTO
+func ToReconciliationRunResponse(run *ReconciliationRun) *ReconciliationRunResponse {
+	return &ReconciliationRunResponse{
+		ID:                  run.ID,
+		Trigger:             run.Trigger,
+		Status:              run.Status,
+		WalletsChecked:      run.WalletsChecked,
+		TransactionsChecked: run.TransactionsChecked,
+		FindingCount:        run.FindingCount,
+		Error:               run.Error,
+		StartedAt:           run.StartedAt,
+		FinishedAt:          run.FinishedAt,
+	}
+}
+
+// ToReconciliationRunResponses converts a slice of ReconciliationRun models to DTOs
+func ToReconciliationRunResponses(runs []ReconciliationRun) []ReconciliationRunResponse {
+	responses := make([]ReconciliationRunResponse, len(runs))
+	for i, run := range runs {
+		responses[i] = *ToReconciliationRunResponse(&run)
+	}
+	return responses
+}
+
+// ToReconciliationFindingResponses converts a slice of ReconciliationFinding models to DTOs
+func ToReconciliationFindingResponses(findings []ReconciliationFinding) []ReconciliationFindingResponse {
+	responses := make([]ReconciliationFindingResponse, len(findings))
+	for i, finding := range findings {
+		responses[i] = ReconciliationFindingResponse{
+			ID:            finding.ID,
+			RunID:         finding.RunID,
+			Type:          finding.Type,
+			WalletID:      finding.WalletID,
+			TransactionID: finding.TransactionID,
+			HistoryID:     finding.HistoryID,
+			Expected:      finding.Expected,
+			Actual:        finding.Actual,
+			Detail:        finding.Detail,
+			CreatedAt:     finding.CreatedAt,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Scheduled transfer frequencies
+const (
+	FrequencyOnce    = "once"
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// Scheduled transfer statuses
+const (
+	ScheduleStatusActive    = "active"
+	ScheduleStatusPaused    = "paused"    // Paused by the owner
+	ScheduleStatusDisabled  = "disabled"  // Disabled by the worker after repeated insufficient-balance failures
+	ScheduleStatusCompleted = "completed" // No occurrences left
+	ScheduleStatusCancelled = "cancelled" // Deleted by the owner; runs are kept for history
+)
+
+// Scheduled transfer run statuses
+const (
+	ScheduleRunCompleted = "completed"
+	ScheduleRunFailed    = "failed"  // Last attempt failed, will be retried
+	ScheduleRunSkipped   = "skipped" // Gave up on this occurrence after the maximum number of attempts
+)
+
+// ScheduledTransfer is a transfer that runs once at a future time or repeats daily, weekly or monthly
+// Occurrence counts the occurrences already handled (completed or skipped); NextRunAt is the time of the next one
+// and NextAttemptAt is when the worker picks it up, which is later than NextRunAt while a failed run waits for a retry.
+type ScheduledTransfer struct {
+	ID                  uint            `gorm:"primarykey"`
+	UserID              uint            `gorm:"index;not null"` // Sender and owner
+	ToUserID            uint            `gorm:"not null"`
+	CurrencyID          uint            `gorm:"not null"`
+	Amount              decimal.Decimal `gorm:"type:decimal(20,8);not null"`
+	Frequency           string          `gorm:"size:10;not null"`
+	StartAt             time.Time       `gorm:"not null"`
+	EndAt               *time.Time      // No occurrences after this time; nil repeats forever
+	Status              string          `gorm:"size:20;not null;index"`
+	Occurrence          int             `gorm:"not null;default:0"`
+	NextRunAt           time.Time       `gorm:"not null"`
+	NextAttemptAt       time.Time       `gorm:"not null;index"`
+	ConsecutiveFailures int             `gorm:"not null;default:0"` // Insufficient-balance failures since the last success
+	LastError           string          `gorm:"size:255"`
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// TableName specifies the table name for GORM
+func (ScheduledTransfer) TableName() string {
+	return "scheduled_transfers"
+}
+
+// OccurrenceAt returns the time of the n-th occurrence (0-based), counted from StartAt so monthly schedules do not drift
+// Monthly schedules starting on the 29th-31st run on the last day of shorter months.
+func (s *ScheduledTransfer) OccurrenceAt(n int) time.Time {
+	switch s.Frequency {
+	case FrequencyDaily:
+		return s.StartAt.AddDate(0, 0, n)
+	case FrequencyWeekly:
+		return s.StartAt.AddDate(0, 0, 7*n)
+	case FrequencyMonthly:
+		first := time.Date(s.StartAt.Year(), s.StartAt.Month()+time.Month(n), 1,
+			s.StartAt.Hour(), s.StartAt.Minute(), s.StartAt.Second(), s.StartAt.Nanosecond(), s.StartAt.Location())
+		lastDay := first.AddDate(0, 1, -1).Day()
+		return first.AddDate(0, 0, min(s.StartAt.Day(), lastDay)-1)
+	default:
+		return s.StartAt
+	}
+}
+
+// HasOccurrence reports whether the n-th occurrence exists
+func (s *ScheduledTransfer) HasOccurrence(n int) bool {
+	if s.Frequency == FrequencyOnce {
+		return n == 0
+	}
+	return s.EndAt == nil || !s.OccurrenceAt(n).After(*s.EndAt)
+}
+
+// ScheduledTransferRun records the outcome of one occurrence of a scheduled transfer
+// (ScheduleID, Occurrence) is unique, so an occurrence can only ever be completed once.
+type ScheduledTransferRun struct {
+	ID            uint      `gorm:"primarykey"`
+	ScheduleID    uint      `gorm:"uniqueIndex:idx_schedule_run_occurrence;not null"`
+	Occurrence    int       `gorm:"uniqueIndex:idx_schedule_run_occurrence;not null"`
+	ScheduledFor  time.Time `gorm:"not null"`
+	Status        string    `gorm:"size:20;not null"`
+	Attempts      int       `gorm:"not null;default:0"`
+	TransactionID *uint
+	Error         string `gorm:"size:255"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TableName specifies the table name for GORM
+func (ScheduledTransferRun) TableName() string {
+	return "scheduled_transfer_runs"
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CreateScheduledTransferRequest represents the HTTP request body for scheduling a transfer
+type CreateScheduledTransferRequest struct {
+	ToUserID   uint            `json:"to_user_id" binding:"required" example:"2"`
+	CurrencyID uint            `json:"currency_id" binding:"required" example:"1"`
+	Amount     decimal.Decimal `json:"amount" binding:"required" swaggertype:"number" example:"1500"`
+	Frequency  string          `json:"frequency" binding:"required,oneof=once daily weekly monthly" example:"monthly"`
+	StartAt    time.Time       `json:"start_at" binding:"required" example:"2026-11-01T09:00:00Z"`
+	EndAt      *time.Time      `json:"end_at" example:"2027-10-31T23:59:59Z"`
+}
+
+// UpdateScheduledTransferRequest represents the HTTP request body for editing a scheduled transfer
+// Set active to false to pause and true to resume (which also re-enables a schedule disabled after failures)
+type UpdateScheduledTransferRequest struct {
+	Amount *decimal.Decimal `json:"amount" swaggertype:"number" example:"1600"`
+	EndAt  *time.Time       `json:"end_at" example:"2027-10-31T23:59:59Z"`
+	Active *bool            `json:"active" example:"true"`
+}
+
+// ScheduledTransferResponse represents the HTTP response for a scheduled transfer
+type ScheduledTransferResponse struct {
+	ID                  uint            `json:"id" example:"1"`
+	ToUserID            uint            `json:"to_user_id" example:"2"`
+	CurrencyID          uint            `json:"currency_id" example:"1"`
+	Amount              decimal.Decimal `json:"amount" swaggertype:"number" example:"1500"`
+	Frequency           string          `json:"frequency" example:"monthly"`
+	StartAt             time.Time       `json:"start_at"`
+	EndAt               *time.Time      `json:"end_at,omitempty"`
+	Status              string          `json:"status" example:"active"`
+	RunCount            int             `json:"run_count" example:"3"` // Occurrences handled so far
+	NextRunAt           *time.Time      `json:"next_run_at,omitempty"` // Omitted once the schedule is no longer active
+	ConsecutiveFailures int             `json:"consecutive_failures" example:"0"`
+	LastError           string          `json:"last_error,omitempty"`
+	CreatedAt           time.Time       `json:"created_at"`
+}
+
+// ScheduledTransferRunResponse represents the HTTP response for one occurrence of a scheduled transfer
+type ScheduledTransferRunResponse struct {
+	Occurrence    int       `json:"occurrence" example:"0"`
+	ScheduledFor  time.Time `json:"scheduled_for"`
+	Status        string    `json:"status" example:"completed"`
+	Attempts      int       `json:"attempts" example:"1"`
+	TransactionID *uint     `json:"transaction_id,omitempty" example:"42"`
+	Error         string    `json:"error,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ToScheduledTransferResponse converts a ScheduledTransfer model to ScheduledTransferResponse DTO
+func ToScheduledTransferResponse(schedule *ScheduledTransfer) *ScheduledTransferResponse {
+	response := &ScheduledTransferResponse{
+		ID:                  schedule.ID,
+		ToUserID:            schedule.ToUserID,
+		CurrencyID:          schedule.CurrencyID,
+		Amount:              schedule.Amount,
+		Frequency:           schedule.Frequency,
+		StartAt:             schedule.StartAt,
+		EndAt:               schedule.EndAt,
+		Status:              schedule.Status,
+		RunCount:            schedule.Occurrence,
+		ConsecutiveFailures: schedule.ConsecutiveFailures,
+		LastError:           schedule.LastError,
+		CreatedAt:           schedule.CreatedAt,
+	}
+	if schedule.Status == ScheduleStatusActive {
+		next := schedule.NextAttemptAt
+		response.NextRunAt = &next
+	}
+	return response
+}
+
+// ToScheduledTransferResponses converts a slice of ScheduledTransfer models to ScheduledTransferResponse DTOs
+func ToScheduledTransferResponses(schedules []ScheduledTransfer) []ScheduledTransferResponse {
+	responses := make([]ScheduledTransferResponse, len(schedules))
+	for i := range schedules {
+		responses[i] = *ToScheduledTransferResponse(&schedules[i])
+	}
+	return responses
+}
+
+// ToScheduledTransferRunResponses converts a slice of ScheduledTransferRun models to DTOs
+func ToScheduledTransferRunResponses(runs []ScheduledTransferRun) []ScheduledTransferRunResponse {
+	responses := make([]ScheduledTransferRunResponse, len(runs))
+	for i, run := range runs {
+		responses[i] = ScheduledTransferRunResponse{
+			Occurrence:    run.Occurrence,
+			ScheduledFor:  run.ScheduledFor,
+			Status:        run.Status,
+			Attempts:      run.Attempts,
+			TransactionID: run.TransactionID,
+			Error:         run.Error,
+			UpdatedAt:     run.UpdatedAt,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,26 @@
+package models
+
+// Stream event types pushed on GET /stream
+const (
+	StreamEventTransaction = "transaction" // data is a TransactionResponse
+	StreamEventBalance     = "balance"     // data is a StreamBalanceData with the wallet's latest balance
+	StreamEventReset       = "reset"       // events since Last-Event-ID can no longer be replayed, refetch the current state
+)
+
+// StreamResetData is the data of a reset event
+type StreamResetData struct {
+	Reason string `json:"reason" example:"events since the last event id are no longer available"`
+}
+
+// StreamBalanceData is the data of a balance event
+// Sequence is the position in the wallet's balance history the balance was read at. Balance events of
+// concurrent transactions can arrive out of order, so clients keep the value with the highest sequence
+type StreamBalanceData struct {
+	WalletResponse
+	Sequence uint64 `json:"sequence" example:"42"`
+}
+
+// ToStreamBalanceData converts a Wallet model and its balance history sequence to a balance event
+func ToStreamBalanceData(wallet *Wallet, sequence uint64) *StreamBalanceData {
+	return &StreamBalanceData{WalletResponse: *ToWalletResponse(wallet), Sequence: sequence}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SwapQuote is a priced offer to convert AmountIn of one currency into another
+// The quote is valid until ExpiresAt and can be executed once; the swap itself
+// runs at the live rate and is refused when it is worse than the quote by more than the allowed slippage
+type SwapQuote struct {
+	ID             uint            `gorm:"primarykey"`
+	QuoteID        string          `gorm:"uniqueIndex;size:32;not null"` // Public identifier (random hex)
+	UserID         uint            `gorm:"index;not null"`
+	FromCurrencyID uint            `gorm:"not null"`
+	ToCurrencyID   uint            `gorm:"not null"`
+	AmountIn       decimal.Decimal `gorm:"type:decimal(20,8);not null"`
+	Rate           decimal.Decimal `gorm:"type:decimal(30,16);not null"` // Mid rate: units of To per unit of From
+	Fee            decimal.Decimal `gorm:"type:decimal(20,8);not null"`  // Spread fee in the From currency
+	AmountOut      decimal.Decimal `gorm:"type:decimal(20,8);not null"`
+	ExpiresAt      time.Time       `gorm:"not null"`
+	ExecutedAt     *time.Time
+	TransactionID  uint            `gorm:"index"`               // Swap transaction, 0 until executed
+	ExecutedRate   decimal.Decimal `gorm:"type:decimal(30,16)"` // Live rate used at execution
+	ExecutedOut    decimal.Decimal `gorm:"type:decimal(20,8)"`  // Amount actually credited
+	CreatedAt      time.Time
+}
+
+// TableName specifies the table name for GORM
+func (SwapQuote) TableName() string {
+	return "swap_quotes"
+}
+
+// IsExpired reports whether the quote can no longer be executed
+func (q *SwapQuote) IsExpired(now time.Time) bool {
+	return !now.Before(q.ExpiresAt)
+}
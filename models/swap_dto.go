@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SwapQuoteRequest represents the HTTP request body for pricing a swap
+type SwapQuoteRequest struct {
+	FromCurrencyID uint            `json:"from_currency_id" binding:"required" example:"1"`
+	ToCurrencyID   uint            `json:"to_currency_id" binding:"required" example:"2"`
+	Amount         decimal.Decimal `json:"amount" binding:"required" swaggertype:"number" example:"100.0"`
+}
+
+// SwapRequest represents the HTTP request body for executing a quote
+// MaxSlippageBps bounds how much worse than the quote the live amount may be (1 bps = 0.01%)
+type SwapRequest struct {
+	QuoteID        string `json:"quote_id" binding:"required,max=32" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	MaxSlippageBps *int   `json:"max_slippage_bps" binding:"omitempty,min=0,max=1000" example:"50"`
+}
+
+// SwapQuoteResponse represents the HTTP response for a swap quote
+type SwapQuoteResponse struct {
+	QuoteID        string          `json:"quote_id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	FromCurrencyID uint            `json:"from_currency_id" example:"1"`
+	ToCurrencyID   uint            `json:"to_currency_id" example:"2"`
+	AmountIn       decimal.Decimal `json:"amount_in" swaggertype:"number" example:"100.0"`
+	Rate           decimal.Decimal `json:"rate" swaggertype:"number" example:"0.000015"`
+	Fee            decimal.Decimal `json:"fee" swaggertype:"number" example:"0.3"`
+	AmountOut      decimal.Decimal `json:"amount_out" swaggertype:"number" example:"0.00149550"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+}
+
+// SwapResponse represents the HTTP response for an executed swap
+type SwapResponse struct {
+	Message     string              `json:"message" example:"swap successful"`
+	Quote       SwapQuoteResponse   `json:"quote"`
+	Rate        decimal.Decimal     `json:"rate" swaggertype:"number" example:"0.000015"`         // Live rate used
+	AmountOut   decimal.Decimal     `json:"amount_out" swaggertype:"number" example:"0.00149550"` // Amount credited
+	Transaction TransactionResponse `json:"transaction"`
+}
+
+// ToSwapQuoteResponse converts a SwapQuote model to SwapQuoteResponse DTO
+func ToSwapQuoteResponse(quote *SwapQuote) *SwapQuoteResponse {
+	return &SwapQuoteResponse{
+		QuoteID:        quote.QuoteID,
+		FromCurrencyID: quote.FromCurrencyID,
+		ToCurrencyID:   quote.ToCurrencyID,
+		AmountIn:       quote.AmountIn,
+		Rate:           quote.Rate,
+		Fee:            quote.Fee,
+		AmountOut:      quote.AmountOut,
+		ExpiresAt:      quote.ExpiresAt,
+	}
+}
+
+// ToSwapResponse converts an executed quote and its transaction to SwapResponse DTO
+func ToSwapResponse(quote *SwapQuote, tx *Transaction) *SwapResponse {
+	return &SwapResponse{
+		Message:     "swap successful",
+		Quote:       *ToSwapQuoteResponse(quote),
+		Rate:        quote.ExecutedRate,
+		AmountOut:   quote.ExecutedOut,
+		Transaction: *ToTransactionResponse(tx),
+	}
+}
@@ -3,26 +3,71 @@ package models
 import (
 	"crypto/sha256"
 	"encoding/hex"
-	"fmt"
+	"encoding/json"
 	"time"
 
 	"github.com/shopspring/decimal"
 )
 
+// Transaction types
+const (
+	TxTypeTransfer   = "transfer"
+	TxTypeDeposit    = "deposit"
+	TxTypeWithdrawal = "withdrawal"
+	TxTypeSwap       = "swap"     // Conversion between two wallets of the same user in different currencies
+	TxTypeReversal   = "reversal" // Staff-initiated compensation that undoes a transfer
+	TxTypeRefund     = "refund"   // Full or partial return of a transfer by its recipient
+)
+
+// Transaction statuses
+const (
+	TxStatusPending    = "pending"
+	TxStatusProcessing = "processing"
+	TxStatusCompleted  = "completed"
+	TxStatusFailed     = "failed"
+	TxStatusCancelled  = "cancelled"
+	TxStatusReversed   = "reversed" // Completed transfer undone by a reversal transaction
+)
+
+// Transaction hash versions
+const (
+	HashVersionLegacy  = 0 // Random hash from before content addressing; cannot be recomputed
+	HashVersionContent = 1 // SHA256 over HashPayload
+)
+
+// txStatusTransitions lists the statuses each status may move to
+var txStatusTransitions = map[string][]string{
+	TxStatusPending:    {TxStatusProcessing, TxStatusFailed, TxStatusCancelled},
+	TxStatusProcessing: {TxStatusCompleted, TxStatusFailed},
+}
+
 // Transaction represents the database model for money transfers between users
 // Pure GORM model - no JSON/binding tags for HTTP layer separation
-// Retains domain logic methods (GenerateHash, GenerateSignature) as they are
+// Retains domain logic methods (GenerateHash, SigningPayload) as they are
 // part of the business logic, not HTTP serialization
 type Transaction struct {
 	ID         uint            `gorm:"primarykey"`
-	FromUserID uint            `gorm:"index;not null"`
+	Type       string          `gorm:"size:20;not null;default:'transfer';index"` // transfer, deposit, withdrawal, swap, reversal, refund
+	FromUserID uint            `gorm:"index;not null"`                            // Deposits and withdrawals use the owner on both sides
 	ToUserID   uint            `gorm:"index;not null"`
+	CurrencyID uint            `gorm:"index"`
 	Amount     decimal.Decimal `gorm:"type:decimal(20,8);not null"`
-	Hash       string          `gorm:"uniqueIndex;size:64;not null"`       // SHA256 hash (64 hex chars)
-	Signature  string          `gorm:"size:255;not null"`                  // Transaction signature
-	Status     string          `gorm:"size:50;not null;default:'pending'"` // pending, processing, completed, failed, cancelled
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	Fee        decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0"` // Paid by the sender on top of Amount
+	Hash       string          `gorm:"uniqueIndex;size:64;not null"`          // SHA256 of HashPayload (64 hex chars)
+	Signature  string          `gorm:"size:255;not null"`                     // Base64 Ed25519 signature over SigningPayload
+	Status     string          `gorm:"size:50;not null;default:'pending'"`    // pending, processing, completed, failed, cancelled, reversed
+	Reference  string          `gorm:"size:255"`                              // External reference (deposit source, withdrawal address, reversal reason)
+	FailReason string          `gorm:"size:255"`
+
+	OriginalTransactionID *uint           `gorm:"index"`                                 // Transfer undone by a reversal or refund
+	RefundedAmount        decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0"` // Sum of refunds issued against this transfer
+	SignatureKID          string          `gorm:"column:signature_kid;size:64"`          // Key that produced Signature; empty on legacy rows
+	Nonce                 string          `gorm:"size:32"`                               // Random per transaction so identical transfers never share a hash
+	HashVersion           int             `gorm:"not null;default:0"`                    // HashVersionLegacy or HashVersionContent
+	LegacyHash            string          `gorm:"size:64;index"`                         // Hash before migrating to content addressing, still accepted for lookups
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
 
 	// Relationships - only for GORM, not exposed directly via HTTP
 	FromUser User `gorm:"foreignKey:FromUserID"`
@@ -34,16 +79,122 @@ func (Transaction) TableName() string {
 	return "transactions"
 }
 
-// GenerateHash creates a unique SHA256 hash for the transaction
+// CanTransitionTo reports whether the transaction may move from its current status to next
+// Domain logic method - enforces the pending → processing → completed/failed/cancelled lifecycle
+func (t *Transaction) CanTransitionTo(next string) bool {
+	for _, allowed := range txStatusTransitions[t.Status] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal reports whether the transaction reached a terminal status
+func (t *Transaction) IsFinal() bool {
+	return len(txStatusTransitions[t.Status]) == 0
+}
+
+// RefundableAmount returns how much of a transfer has not been refunded yet
+func (t *Transaction) RefundableAmount() decimal.Decimal {
+	return t.Amount.Sub(t.RefundedAmount)
+}
+
+// transactionHashPayload fixes the field order of the hashed JSON document
+type transactionHashPayload struct {
+	Version               int    `json:"v"`
+	Type                  string `json:"type"`
+	FromUserID            uint   `json:"from_user_id"`
+	ToUserID              uint   `json:"to_user_id"`
+	CurrencyID            uint   `json:"currency_id"`
+	Amount                string `json:"amount"`
+	Fee                   string `json:"fee"`
+	Reference             string `json:"reference"`
+	OriginalTransactionID uint   `json:"original_transaction_id"`
+	Nonce                 string `json:"nonce"`
+	CreatedAt             string `json:"created_at"`
+}
+
+// HashPayload returns the canonical encoding the transaction hash is computed from
+// Uses the same conventions as SigningPayload and covers every field fixed at creation,
+// including the nonce and the stored created_at, so the hash can be recomputed from the row
+func (t *Transaction) HashPayload() []byte {
+	payload := transactionHashPayload{
+		Version:    HashVersionContent,
+		Type:       t.Type,
+		FromUserID: t.FromUserID,
+		ToUserID:   t.ToUserID,
+		CurrencyID: t.CurrencyID,
+		Amount:     t.Amount.String(),
+		Fee:        t.Fee.String(),
+		Reference:  t.Reference,
+		Nonce:      t.Nonce,
+		CreatedAt:  canonicalTime(t.CreatedAt),
+	}
+	if t.OriginalTransactionID != nil {
+		payload.OriginalTransactionID = *t.OriginalTransactionID
+	}
+	data, _ := json.Marshal(payload)
+	return data
+}
+
+// GenerateHash returns the content-addressed SHA256 hash of HashPayload
 // Domain logic method - belongs with the model
 func (t *Transaction) GenerateHash() string {
-	data := fmt.Sprintf("%d|%d|%s|%d", t.FromUserID, t.ToUserID, t.Amount.String(), time.Now().UnixNano())
-	hash := sha256.Sum256([]byte(data))
+	hash := sha256.Sum256(t.HashPayload())
 	return hex.EncodeToString(hash[:])
 }
 
-// GenerateSignature creates a signature for the transaction
-// Domain logic method - simulated signature for demonstration
-func (t *Transaction) GenerateSignature() string {
-	return fmt.Sprintf("SIG-%d-%s-%d", t.FromUserID, t.Amount.String(), t.CreatedAt.UnixNano())
+// HashMatches reports whether the stored hash can be recomputed from the row
+// Legacy hashes were random and never match
+func (t *Transaction) HashMatches() bool {
+	return t.HashVersion == HashVersionContent && t.GenerateHash() == t.Hash
+}
+
+// canonicalTime formats a timestamp the way the database keeps it: UTC with microsecond precision
+func canonicalTime(ts time.Time) string {
+	return ts.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+}
+
+// SigningPayloadVersion identifies the layout of SigningPayload
+const SigningPayloadVersion = 1
+
+// transactionSigningPayload fixes the field order of the signed JSON document
+type transactionSigningPayload struct {
+	Version               int    `json:"v"`
+	Hash                  string `json:"hash"`
+	Type                  string `json:"type"`
+	FromUserID            uint   `json:"from_user_id"`
+	ToUserID              uint   `json:"to_user_id"`
+	CurrencyID            uint   `json:"currency_id"`
+	Amount                string `json:"amount"`
+	Fee                   string `json:"fee"`
+	Reference             string `json:"reference"`
+	OriginalTransactionID uint   `json:"original_transaction_id"`
+	CreatedAt             string `json:"created_at"`
+}
+
+// SigningPayload returns the canonical serialization covered by the transaction signature
+// Compact JSON with a fixed key order, amounts as plain decimal strings without trailing zeros
+// and created_at in UTC RFC 3339 with up to microsecond precision (what the database keeps).
+// Only fields fixed at creation are signed: Status, FailReason and RefundedAmount change
+// during the transaction's lifecycle and are not covered.
+func (t *Transaction) SigningPayload() []byte {
+	payload := transactionSigningPayload{
+		Version:    SigningPayloadVersion,
+		Hash:       t.Hash,
+		Type:       t.Type,
+		FromUserID: t.FromUserID,
+		ToUserID:   t.ToUserID,
+		CurrencyID: t.CurrencyID,
+		Amount:     t.Amount.String(),
+		Fee:        t.Fee.String(),
+		Reference:  t.Reference,
+		CreatedAt:  canonicalTime(t.CreatedAt),
+	}
+	if t.OriginalTransactionID != nil {
+		payload.OriginalTransactionID = *t.OriginalTransactionID
+	}
+	data, _ := json.Marshal(payload)
+	return data
 }
@@ -13,26 +13,48 @@ import (
 // 3. Allow future API versioning without breaking database layer
 type TransactionResponse struct {
 	ID         uint            `json:"id" example:"1"`
+	Type       string          `json:"type" example:"transfer"`
 	FromUserID uint            `json:"from_user_id" example:"1"`
 	ToUserID   uint            `json:"to_user_id" example:"2"`
+	CurrencyID uint            `json:"currency_id" example:"1"`
 	Amount     decimal.Decimal `json:"amount" swaggertype:"number" example:"100.0"`
+	Fee        decimal.Decimal `json:"fee" swaggertype:"number" example:"0.1"`
 	Hash       string          `json:"hash" example:"abc123..."`
-	Signature  string          `json:"signature" example:"SIG-1-100-123456"`
+	Signature  string          `json:"signature" example:"3q2+7w..."` // Base64 Ed25519 signature, see GET /tx/{hash}/verify
 	Status     string          `json:"status" example:"completed"`
-	CreatedAt  time.Time       `json:"created_at"`
+	Reference  string          `json:"reference,omitempty" example:"0xabc..."`
+	FailReason string          `json:"fail_reason,omitempty"`
+
+	OriginalTransactionID *uint           `json:"original_transaction_id,omitempty" example:"7"` // Set on reversals and refunds
+	RefundedAmount        decimal.Decimal `json:"refunded_amount" swaggertype:"number" example:"0"`
+	SignatureKID          string          `json:"signature_kid,omitempty" example:"2026-10"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // ToTransactionResponse converts a Transaction model to TransactionResponse DTO
 func ToTransactionResponse(tx *Transaction) *TransactionResponse {
 	return &TransactionResponse{
 		ID:         tx.ID,
+		Type:       tx.Type,
 		FromUserID: tx.FromUserID,
 		ToUserID:   tx.ToUserID,
+		CurrencyID: tx.CurrencyID,
 		Amount:     tx.Amount,
+		Fee:        tx.Fee,
 		Hash:       tx.Hash,
 		Signature:  tx.Signature,
 		Status:     tx.Status,
-		CreatedAt:  tx.CreatedAt,
+		Reference:  tx.Reference,
+		FailReason: tx.FailReason,
+
+		OriginalTransactionID: tx.OriginalTransactionID,
+		RefundedAmount:        tx.RefundedAmount,
+		SignatureKID:          tx.SignatureKID,
+
+		CreatedAt: tx.CreatedAt,
+		UpdatedAt: tx.UpdatedAt,
 	}
 }
 
@@ -44,3 +66,125 @@ func ToTransactionResponses(txs []Transaction) []TransactionResponse {
 	}
 	return responses
 }
+
+// TransferResponse represents the HTTP response for a successful transfer
+// It is also persisted verbatim for Idempotency-Key replays
+type TransferResponse struct {
+	Message     string              `json:"message" example:"transfer successful"`
+	Transaction TransactionResponse `json:"transaction"`
+}
+
+// ToTransferResponse converts a completed Transaction model to TransferResponse DTO
+func ToTransferResponse(tx *Transaction) *TransferResponse {
+	return &TransferResponse{
+		Message:     "transfer successful",
+		Transaction: *ToTransactionResponse(tx),
+	}
+}
+
+// DepositRequest represents the HTTP request body for announcing an incoming deposit
+type DepositRequest struct {
+	CurrencyID uint            `json:"currency_id" binding:"required" example:"1"`
+	Amount     decimal.Decimal `json:"amount" binding:"required" swaggertype:"number" example:"250.0"`
+	Reference  string          `json:"reference" binding:"max=255" example:"bank-transfer-8812"`
+}
+
+// FailFundingRequest represents the HTTP request body for failing a deposit or withdrawal
+type FailFundingRequest struct {
+	Reason string `json:"reason" binding:"required,max=255" example:"rejected by compliance"`
+}
+
+// WithdrawalRequest represents the HTTP request body for requesting a withdrawal
+type WithdrawalRequest struct {
+	CurrencyID uint            `json:"currency_id" binding:"required" example:"1"`
+	Amount     decimal.Decimal `json:"amount" binding:"required" swaggertype:"number" example:"100.0"`
+	Address    string          `json:"address" binding:"required,max=255" example:"0x1234..."`
+}
+
+// Batch transfer modes
+const (
+	BatchModeAllOrNothing = "all_or_nothing" // Any failed item rolls back the whole batch
+	BatchModeBestEffort   = "best_effort"    // Failed items are skipped, the rest are committed
+)
+
+// Batch transfer item statuses
+const (
+	BatchItemCompleted  = "completed"
+	BatchItemFailed     = "failed"
+	BatchItemRolledBack = "rolled_back" // Succeeded on its own but was undone because another item failed
+)
+
+// MaxBatchTransferItems caps the number of items in one batch transfer
+const MaxBatchTransferItems = 500
+
+// BatchTransferRequest represents the HTTP request body for paying many recipients from the authenticated user's wallet
+type BatchTransferRequest struct {
+	CurrencyID uint                `json:"currency_id" binding:"required" example:"1"`
+	Mode       string              `json:"mode" binding:"required,oneof=all_or_nothing best_effort" example:"best_effort"`
+	Items      []BatchTransferItem `json:"items" binding:"required,min=1,max=500,dive"`
+}
+
+// BatchTransferItem is one recipient of a batch transfer
+type BatchTransferItem struct {
+	ToUserID uint            `json:"to_user_id" binding:"required" example:"2"`
+	Amount   decimal.Decimal `json:"amount" binding:"required" swaggertype:"number" example:"25.5"`
+}
+
+// BatchTransferItemResult represents the outcome of one batch item
+type BatchTransferItemResult struct {
+	Index       int                  `json:"index" example:"0"`
+	ToUserID    uint                 `json:"to_user_id" example:"2"`
+	Amount      decimal.Decimal      `json:"amount" swaggertype:"number" example:"25.5"`
+	Status      string               `json:"status" example:"completed"`
+	Transaction *TransactionResponse `json:"transaction,omitempty"`
+	Error       string               `json:"error,omitempty"`
+	Code        string               `json:"code,omitempty"`
+}
+
+// BatchTransferResponse represents the HTTP response for a batch transfer
+type BatchTransferResponse struct {
+	Mode      string                    `json:"mode" example:"best_effort"`
+	Committed bool                      `json:"committed" example:"true"`
+	Succeeded int                       `json:"succeeded" example:"499"`
+	Failed    int                       `json:"failed" example:"1"`
+	Items     []BatchTransferItemResult `json:"items"`
+}
+
+// TransactionVerificationResponse represents the result of checking a transaction's signature and hash
+// signed_payload is the canonical document that was signed; with public_key anyone can re-check it offline
+type TransactionVerificationResponse struct {
+	Hash          string `json:"hash" example:"a1b2c3d4e5f6..."`
+	Valid         bool   `json:"valid" example:"true"`     // Signature and hash both check out
+	HashVersion   int    `json:"hash_version" example:"1"` // 0 = legacy random hash, 1 = content-addressed
+	HashValid     bool   `json:"hash_valid" example:"true"`
+	Algorithm     string `json:"algorithm" example:"Ed25519"`
+	KID           string `json:"kid,omitempty" example:"2026-10"`
+	PublicKey     string `json:"public_key,omitempty" example:"MCowBQYDK2VwAyEA..."` // Base64 raw Ed25519 public key
+	SignedPayload string `json:"signed_payload"`
+	Reason        string `json:"reason,omitempty" example:"signature does not match the transaction record"`
+}
+
+// ReverseTransactionRequest represents the HTTP request body for reversing a transfer
+type ReverseTransactionRequest struct {
+	Reason string `json:"reason" binding:"required,max=200" example:"confirmed account takeover"`
+}
+
+// RefundRequest represents the HTTP request body for refunding a received transfer
+// Omit amount to refund everything that has not been refunded yet
+type RefundRequest struct {
+	Amount *decimal.Decimal `json:"amount" swaggertype:"number" example:"25"`
+}
+
+// CompensationResponse represents the HTTP response for a reversal or refund
+type CompensationResponse struct {
+	Original     TransactionResponse `json:"original"`
+	Compensation TransactionResponse `json:"compensation"`
+}
+
+// ToCompensationResponse converts an original transfer and its reversal / refund to CompensationResponse DTO
+func ToCompensationResponse(original, compensation *Transaction) *CompensationResponse {
+	return &CompensationResponse{
+		Original:     *ToTransactionResponse(original),
+		Compensation: *ToTransactionResponse(compensation),
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TransferLimit caps outgoing transfers in one currency
+// UserID 0 holds the currency-wide default; a row for a specific user overrides it entirely.
+// Zero values mean "no limit" for that check.
+type TransferLimit struct {
+	ID                  uint            `gorm:"primarykey"`
+	UserID              uint            `gorm:"uniqueIndex:idx_transfer_limit_user_currency;not null;default:0"` // 0 = currency default
+	CurrencyID          uint            `gorm:"uniqueIndex:idx_transfer_limit_user_currency;not null"`
+	MaxSingleAmount     decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0"`
+	DailyLimit          decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0"` // Sum of amounts per UTC calendar day
+	MonthlyLimit        decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0"` // Sum of amounts per UTC calendar month
+	MaxTransfersPerHour int             `gorm:"not null;default:0"`                    // Rolling 60 minute window
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// TableName specifies the table name for GORM
+func (TransferLimit) TableName() string {
+	return "transfer_limits"
+}
+
+// IsUserOverride reports whether the limit applies to a single user rather than the whole currency
+func (l *TransferLimit) IsUserOverride() bool {
+	return l.UserID != 0
+}
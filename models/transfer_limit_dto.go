@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TransferLimitRequest represents the HTTP request body for setting transfer limits
+// Zero (or omitted) values mean "no limit"
+type TransferLimitRequest struct {
+	MaxSingleAmount     decimal.Decimal `json:"max_single_amount" swaggertype:"number" example:"1000"`
+	DailyLimit          decimal.Decimal `json:"daily_limit" swaggertype:"number" example:"5000"`
+	MonthlyLimit        decimal.Decimal `json:"monthly_limit" swaggertype:"number" example:"50000"`
+	MaxTransfersPerHour int             `json:"max_transfers_per_hour" binding:"min=0" example:"10"`
+}
+
+// TransferLimitResponse represents the HTTP response for transfer limits
+type TransferLimitResponse struct {
+	UserID              uint            `json:"user_id,omitempty" example:"1"` // Omitted for currency defaults
+	CurrencyID          uint            `json:"currency_id" example:"1"`
+	MaxSingleAmount     decimal.Decimal `json:"max_single_amount" swaggertype:"number" example:"1000"`
+	DailyLimit          decimal.Decimal `json:"daily_limit" swaggertype:"number" example:"5000"`
+	MonthlyLimit        decimal.Decimal `json:"monthly_limit" swaggertype:"number" example:"50000"`
+	MaxTransfersPerHour int             `json:"max_transfers_per_hour" example:"10"`
+	UpdatedAt           time.Time       `json:"updated_at"`
+}
+
+// ToTransferLimitResponse converts a TransferLimit model to TransferLimitResponse DTO
+func ToTransferLimitResponse(limit *TransferLimit) *TransferLimitResponse {
+	return &TransferLimitResponse{
+		UserID:              limit.UserID,
+		CurrencyID:          limit.CurrencyID,
+		MaxSingleAmount:     limit.MaxSingleAmount,
+		DailyLimit:          limit.DailyLimit,
+		MonthlyLimit:        limit.MonthlyLimit,
+		MaxTransfersPerHour: limit.MaxTransfersPerHour,
+		UpdatedAt:           limit.UpdatedAt,
+	}
+}
+
+// ToTransferLimitResponses converts a slice of TransferLimit models to TransferLimitResponse DTOs
+func ToTransferLimitResponses(limits []TransferLimit) []TransferLimitResponse {
+	responses := make([]TransferLimitResponse, len(limits))
+	for i := range limits {
+		responses[i] = *ToTransferLimitResponse(&limits[i])
+	}
+	return responses
+}
@@ -2,15 +2,25 @@ package models
 
 import "time"
 
+// User roles
+const (
+	RoleUser    = "user"
+	RoleSupport = "support" // Staff: can look up users and freeze accounts
+	RoleAdmin   = "admin"   // Staff: full access to the /admin APIs
+)
+
 // User represents the database model for user data
 // Pure GORM model - no JSON/binding tags for HTTP layer separation
 type User struct {
-	ID        uint   `gorm:"primarykey"`
-	Username  string `gorm:"uniqueIndex;size:50;not null"`
-	Email     string `gorm:"uniqueIndex;size:255;not null"`
-	Password  string `gorm:"column:password;size:255;not null"` // bcrypt hash
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID           uint       `gorm:"primarykey"`
+	Username     string     `gorm:"uniqueIndex;size:50;not null"`
+	Email        string     `gorm:"uniqueIndex;size:255;not null"`
+	Password     string     `gorm:"column:password;size:255;not null"` // bcrypt hash
+	Role         string     `gorm:"size:20;not null;default:'user'"`   // user, support, admin
+	FrozenAt     *time.Time // Frozen accounts cannot log in or move funds out
+	FrozenReason string     `gorm:"size:255"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
 
 // TableName specifies the table name for GORM
@@ -18,6 +28,16 @@ func (User) TableName() string {
 	return "users"
 }
 
+// IsFrozen reports whether the account has been frozen by staff
+func (u *User) IsFrozen() bool {
+	return u.FrozenAt != nil
+}
+
+// IsValidRole reports whether role is one of the known roles
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleSupport || role == RoleAdmin
+}
+
 // LoginRequest represents the HTTP request body for user login
 // Pure DTO - no GORM tags for database layer separation
 type LoginRequest struct {
@@ -28,8 +48,21 @@ type LoginRequest struct {
 // LoginResponse represents the HTTP response for successful login
 // Pure DTO - no GORM tags for database layer separation
 type LoginResponse struct {
-	Token     string `json:"token"`
-	UserID    uint   `json:"user_id"`
-	Username  string `json:"username"`
-	ExpiresIn int    `json:"expires_in"` // Seconds until token expiration
+	Token            string `json:"token"`
+	RefreshToken     string `json:"refresh_token"`
+	UserID           uint   `json:"user_id"`
+	Username         string `json:"username"`
+	ExpiresIn        int    `json:"expires_in"`         // Seconds until access token expiration
+	RefreshExpiresIn int    `json:"refresh_expires_in"` // Seconds until refresh token expiration
+}
+
+// RefreshRequest represents the HTTP request body for exchanging a refresh token
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest represents the HTTP request body for logging out
+// RefreshToken is optional; when present its whole token family is revoked
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
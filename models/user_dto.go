@@ -14,19 +14,35 @@ type UserCreateRequest struct {
 // UserResponse represents the HTTP response for user data
 // Excludes sensitive fields like password hash
 type UserResponse struct {
-	ID        uint      `json:"id" example:"1"`
-	Username  string    `json:"username" example:"alice"`
-	Email     string    `json:"email" example:"alice@example.com"`
-	CreatedAt time.Time `json:"created_at"`
+	ID           uint       `json:"id" example:"1"`
+	Username     string     `json:"username" example:"alice"`
+	Email        string     `json:"email" example:"alice@example.com"`
+	Role         string     `json:"role" example:"user"`
+	FrozenAt     *time.Time `json:"frozen_at,omitempty"`
+	FrozenReason string     `json:"frozen_reason,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// FreezeUserRequest represents the HTTP request body for freezing an account
+type FreezeUserRequest struct {
+	Reason string `json:"reason" binding:"required,max=255" example:"suspected account takeover"`
+}
+
+// UpdateRoleRequest represents the HTTP request body for changing a user's role
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user support admin" example:"support"`
 }
 
 // ToUserResponse converts a User model to UserResponse DTO
 // Ensures password and other sensitive fields are never exposed
 func ToUserResponse(user *User) *UserResponse {
 	return &UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
+		ID:           user.ID,
+		Username:     user.Username,
+		Email:        user.Email,
+		Role:         user.Role,
+		FrozenAt:     user.FrozenAt,
+		FrozenReason: user.FrozenReason,
+		CreatedAt:    user.CreatedAt,
 	}
 }
@@ -8,13 +8,15 @@ import (
 
 // Wallet represents the database model for user wallet data
 // Pure GORM model - no JSON/binding tags for HTTP layer separation
+// A user holds at most one wallet per currency, enforced by a unique (user_id, currency_id) index
 type Wallet struct {
-	ID         uint            `gorm:"primarykey"`
-	UserID     uint            `gorm:"index;not null"`
-	CurrencyID uint            `gorm:"index;not null"`
-	Balance    decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0"`
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	ID          uint            `gorm:"primarykey"`
+	UserID      uint            `gorm:"uniqueIndex:idx_wallet_user_currency;not null"`
+	CurrencyID  uint            `gorm:"uniqueIndex:idx_wallet_user_currency;index;not null"`
+	Balance     decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0"` // Available balance
+	HeldBalance decimal.Decimal `gorm:"type:decimal(20,8);not null;default:0"` // Funds on hold for pending withdrawals
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 
 	// Relationships - only for GORM, not exposed directly via HTTP
 	Currency Currency `gorm:"foreignKey:CurrencyID"`
@@ -12,11 +12,12 @@ import (
 // 2. Prevent database relationships from leaking into HTTP layer
 // 3. Allow API contract evolution without database changes
 type WalletResponse struct {
-	ID         uint            `json:"id" example:"1"`
-	UserID     uint            `json:"user_id" example:"1"`
-	CurrencyID uint            `json:"currency_id" example:"1"`
-	Balance    decimal.Decimal `json:"balance" swaggertype:"number" example:"1000.0"`
-	CreatedAt  time.Time       `json:"created_at"`
+	ID          uint      `json:"id" example:"1"`
+	UserID      uint      `json:"user_id" example:"1"`
+	CurrencyID  uint      `json:"currency_id" example:"1"`
+	Balance     string    `json:"balance" example:"1000.00"`   // Rendered with the currency's decimals
+	HeldBalance string    `json:"held_balance" example:"0.00"` // Rendered with the currency's decimals
+	CreatedAt   time.Time `json:"created_at"`
 	// Currency can be added optionally if needed, but not by default
 	// to avoid exposing unnecessary database relationships
 }
@@ -24,33 +25,41 @@ type WalletResponse struct {
 // WalletWithCurrencyResponse includes currency details
 // Used when the API caller explicitly needs currency information
 type WalletWithCurrencyResponse struct {
-	ID         uint            `json:"id" example:"1"`
-	UserID     uint            `json:"user_id" example:"1"`
-	CurrencyID uint            `json:"currency_id" example:"1"`
-	Balance    decimal.Decimal `json:"balance" swaggertype:"number" example:"1000.0"`
-	CreatedAt  time.Time       `json:"created_at"`
-	Currency   *CurrencyResponse `json:"currency,omitempty"`
+	ID          uint              `json:"id" example:"1"`
+	UserID      uint              `json:"user_id" example:"1"`
+	CurrencyID  uint              `json:"currency_id" example:"1"`
+	Balance     string            `json:"balance" example:"1000.00"`   // Rendered with the currency's decimals
+	HeldBalance string            `json:"held_balance" example:"0.00"` // Rendered with the currency's decimals
+	CreatedAt   time.Time         `json:"created_at"`
+	Currency    *CurrencyResponse `json:"currency,omitempty"`
+}
+
+// OpenWalletRequest represents the HTTP request body for opening a wallet in a new currency
+type OpenWalletRequest struct {
+	CurrencyID uint `json:"currency_id" binding:"required" example:"2"`
 }
 
 // ToWalletResponse converts a Wallet model to WalletResponse DTO
 func ToWalletResponse(wallet *Wallet) *WalletResponse {
 	return &WalletResponse{
-		ID:         wallet.ID,
-		UserID:     wallet.UserID,
-		CurrencyID: wallet.CurrencyID,
-		Balance:    wallet.Balance,
-		CreatedAt:  wallet.CreatedAt,
+		ID:          wallet.ID,
+		UserID:      wallet.UserID,
+		CurrencyID:  wallet.CurrencyID,
+		Balance:     formatWalletAmount(wallet, wallet.Balance),
+		HeldBalance: formatWalletAmount(wallet, wallet.HeldBalance),
+		CreatedAt:   wallet.CreatedAt,
 	}
 }
 
 // ToWalletWithCurrencyResponse converts a Wallet model with Currency to DTO
 func ToWalletWithCurrencyResponse(wallet *Wallet) *WalletWithCurrencyResponse {
 	response := &WalletWithCurrencyResponse{
-		ID:         wallet.ID,
-		UserID:     wallet.UserID,
-		CurrencyID: wallet.CurrencyID,
-		Balance:    wallet.Balance,
-		CreatedAt:  wallet.CreatedAt,
+		ID:          wallet.ID,
+		UserID:      wallet.UserID,
+		CurrencyID:  wallet.CurrencyID,
+		Balance:     formatWalletAmount(wallet, wallet.Balance),
+		HeldBalance: formatWalletAmount(wallet, wallet.HeldBalance),
+		CreatedAt:   wallet.CreatedAt,
 	}
 
 	// Only include currency if it's loaded
@@ -60,3 +69,21 @@ func ToWalletWithCurrencyResponse(wallet *Wallet) *WalletWithCurrencyResponse {
 
 	return response
 }
+
+// ToWalletWithCurrencyResponses converts a slice of Wallet models with Currency to DTOs
+func ToWalletWithCurrencyResponses(wallets []Wallet) []WalletWithCurrencyResponse {
+	responses := make([]WalletWithCurrencyResponse, len(wallets))
+	for i, wallet := range wallets {
+		responses[i] = *ToWalletWithCurrencyResponse(&wallet)
+	}
+	return responses
+}
+
+// formatWalletAmount renders an amount with the wallet currency's decimals
+// Falls back to the shortest representation when the currency is not loaded
+func formatWalletAmount(wallet *Wallet, amount decimal.Decimal) string {
+	if wallet.Currency.ID == 0 {
+		return amount.String()
+	}
+	return wallet.Currency.FormatAmount(amount)
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Webhook event types a user can subscribe to
+const (
+	WebhookEventTransferReceived = "transfer.received"
+	WebhookEventTransferSent     = "transfer.sent"
+	WebhookEventDepositConfirmed = "deposit.confirmed"
+)
+
+// WebhookEventTypes lists every event type an endpoint may subscribe to
+var WebhookEventTypes = []string{WebhookEventTransferReceived, WebhookEventTransferSent, WebhookEventDepositConfirmed}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed" // Gave up after the maximum number of attempts
+)
+
+// WebhookEndpoint is a user-registered URL that receives signed HTTP callbacks
+type WebhookEndpoint struct {
+	ID         uint   `gorm:"primarykey"`
+	UserID     uint   `gorm:"not null;index"`
+	URL        string `gorm:"size:500;not null"`
+	Secret     string `gorm:"size:100;not null"` // HMAC-SHA256 key used to sign every delivery
+	EventTypes string `gorm:"size:255;not null"` // Comma separated subscribed event types
+	Active     bool   `gorm:"not null;default:true"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// TableName specifies the table name for GORM
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+// Events returns the subscribed event types
+func (e *WebhookEndpoint) Events() []string {
+	if e.EventTypes == "" {
+		return nil
+	}
+	return strings.Split(e.EventTypes, ",")
+}
+
+// Subscribes reports whether the endpoint is active and subscribed to eventType
+func (e *WebhookEndpoint) Subscribes(eventType string) bool {
+	if !e.Active {
+		return false
+	}
+	for _, t := range e.Events() {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent (or to be sent) to an endpoint, kept as a delivery log
+// (endpoint_id, event_id) is unique so a redelivered Kafka message creates no duplicates
+type WebhookDelivery struct {
+	ID             uint      `gorm:"primarykey"`
+	EndpointID     uint      `gorm:"not null;uniqueIndex:idx_webhook_delivery_endpoint_event"`
+	EventID        string    `gorm:"size:100;not null;uniqueIndex:idx_webhook_delivery_endpoint_event"` // Stable ID sent in the body, receivers use it to deduplicate
+	EventType      string    `gorm:"size:40;not null"`
+	Payload        string    `gorm:"type:text;not null"`                                                        // JSON body POSTed to the endpoint
+	Status         string    `gorm:"size:20;not null;default:'pending';index:idx_webhook_delivery_status_next"` // pending, succeeded, failed
+	Attempts       int       `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time `gorm:"not null;index:idx_webhook_delivery_status_next"`
+	ResponseStatus int       `gorm:"not null;default:0"` // HTTP status of the last attempt, 0 when no response was received
+	LastError      string    `gorm:"size:500"`
+	LastAttemptAt  *time.Time
+	LeaseToken     string `gorm:"size:32;not null;default:''"` // Set by the dispatcher holding the lease, only the holder may record the result
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// TableName specifies the table name for GORM
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
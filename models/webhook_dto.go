@@ -0,0 +1,101 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CreateWebhookRequest represents the HTTP request body for registering a webhook endpoint
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,url,max=500" example:"https://merchant.example.com/hooks/wallet"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,dive,oneof=transfer.received transfer.sent deposit.confirmed" example:"transfer.received,deposit.confirmed"`
+}
+
+// UpdateWebhookRequest represents the HTTP request body for editing a webhook endpoint
+// Set active to false to pause deliveries; events that happen while paused are not delivered later
+type UpdateWebhookRequest struct {
+	URL        *string  `json:"url" binding:"omitempty,url,max=500" example:"https://merchant.example.com/hooks/wallet"`
+	EventTypes []string `json:"event_types" binding:"omitempty,min=1,dive,oneof=transfer.received transfer.sent deposit.confirmed" example:"transfer.sent"`
+	Active     *bool    `json:"active" example:"false"`
+}
+
+// WebhookEndpointResponse represents the HTTP response for a webhook endpoint
+type WebhookEndpointResponse struct {
+	ID         uint      `json:"id" example:"1"`
+	URL        string    `json:"url" example:"https://merchant.example.com/hooks/wallet"`
+	EventTypes []string  `json:"event_types" example:"transfer.received,deposit.confirmed"`
+	Active     bool      `json:"active" example:"true"`
+	Secret     string    `json:"secret,omitempty" example:"whsec_4f1c..."` // Only returned when the endpoint is created
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ToWebhookEndpointResponse converts a WebhookEndpoint model to WebhookEndpointResponse DTO without its secret
+func ToWebhookEndpointResponse(e *WebhookEndpoint) *WebhookEndpointResponse {
+	return &WebhookEndpointResponse{
+		ID:         e.ID,
+		URL:        e.URL,
+		EventTypes: e.Events(),
+		Active:     e.Active,
+		CreatedAt:  e.CreatedAt,
+		UpdatedAt:  e.UpdatedAt,
+	}
+}
+
+// ToWebhookEndpointResponses converts a slice of WebhookEndpoint models to WebhookEndpointResponse DTOs
+func ToWebhookEndpointResponses(endpoints []WebhookEndpoint) []WebhookEndpointResponse {
+	responses := make([]WebhookEndpointResponse, len(endpoints))
+	for i := range endpoints {
+		responses[i] = *ToWebhookEndpointResponse(&endpoints[i])
+	}
+	return responses
+}
+
+// WebhookDeliveryResponse represents the HTTP response for a webhook delivery log entry
+type WebhookDeliveryResponse struct {
+	ID             uint            `json:"id" example:"1"`
+	EndpointID     uint            `json:"endpoint_id" example:"1"`
+	EventID        string          `json:"event_id" example:"transfer.received:3b0c..."`
+	EventType      string          `json:"event_type" example:"transfer.received"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status" example:"succeeded"`
+	Attempts       int             `json:"attempts" example:"1"`
+	ResponseStatus int             `json:"response_status,omitempty" example:"200"`
+	LastError      string          `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"` // Only while the delivery is pending
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// ToWebhookDeliveryResponse converts a WebhookDelivery model to WebhookDeliveryResponse DTO
+func ToWebhookDeliveryResponse(d *WebhookDelivery) *WebhookDeliveryResponse {
+	resp := &WebhookDeliveryResponse{
+		ID:             d.ID,
+		EndpointID:     d.EndpointID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        json.RawMessage(d.Payload),
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		LastAttemptAt:  d.LastAttemptAt,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.Status == WebhookDeliveryPending {
+		next := d.NextAttemptAt
+		resp.NextAttemptAt = &next
+	}
+	return resp
+}
+
+// ToWebhookDeliveryResponses converts a slice of WebhookDelivery models to WebhookDeliveryResponse DTOs
+func ToWebhookDeliveryResponses(deliveries []WebhookDelivery) []WebhookDeliveryResponse {
+	responses := make([]WebhookDeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		responses[i] = *ToWebhookDeliveryResponse(&deliveries[i])
+	}
+	return responses
+}
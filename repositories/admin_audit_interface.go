@@ -0,0 +1,8 @@
+package repositories
+
+import "mini-crypto-wallet-api/models"
+
+type IAdminAudit interface {
+	CreateLog(log *models.AdminAuditLog) error
+	GetLogs(actorID uint, offset, limit int) ([]models.AdminAuditLog, int64, error)
+}
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories/entity"
+)
+
+type adminAuditRepository struct {
+	entity.DBClient
+}
+
+func NewAdminAuditRepository() IAdminAudit {
+	r := new(adminAuditRepository)
+	r.DBClient.MasterDB = db_conn.Conn_DB.MasterDB
+	return r
+}
+
+func (r *adminAuditRepository) CreateLog(log *models.AdminAuditLog) error {
+	return r.DBClient.MasterDB.Create(log).Error
+}
+
+// GetLogs 分頁取得稽核紀錄（新到舊），actorID 為 0 時不篩選
+func (r *adminAuditRepository) GetLogs(actorID uint, offset, limit int) ([]models.AdminAuditLog, int64, error) {
+	query := r.DBClient.MasterDB.Model(&models.AdminAuditLog{})
+	if actorID != 0 {
+		query = query.Where("actor_id = ?", actorID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []models.AdminAuditLog
+	err := query.Order("id desc").Offset(offset).Limit(limit).Find(&logs).Error
+	return logs, total, err
+}
@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"mini-crypto-wallet-api/models"
+)
+
+type IAuditCheckpoint interface {
+	CreateCheckpoint(checkpoint *models.AuditCheckpoint, leaves []models.AuditCheckpointLeaf) error
+	GetCheckpoints(limit int) ([]models.AuditCheckpoint, error)
+	GetCheckpointByID(id uint) (*models.AuditCheckpoint, error)
+	GetLatestCheckpoint() (*models.AuditCheckpoint, error)
+	GetLeavesByCheckpointID(checkpointID uint) ([]models.AuditCheckpointLeaf, error)
+}
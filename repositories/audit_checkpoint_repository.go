@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories/entity"
+)
+
+const checkpointLeavesBatchSize = 500
+
+type auditCheckpointRepository struct {
+	entity.DBClient
+}
+
+func NewAuditCheckpointRepository() IAuditCheckpoint {
+	r := new(auditCheckpointRepository)
+	r.DBClient.MasterDB = db_conn.Conn_DB.MasterDB
+	return r
+}
+
+// CreateCheckpoint 在同一個 DB 交易中寫入 checkpoint 與它的葉節點
+func (r *auditCheckpointRepository) CreateCheckpoint(checkpoint *models.AuditCheckpoint, leaves []models.AuditCheckpointLeaf) error {
+	return r.DBClient.MasterDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(checkpoint).Error; err != nil {
+			return err
+		}
+		if len(leaves) == 0 {
+			return nil
+		}
+		for i := range leaves {
+			leaves[i].CheckpointID = checkpoint.ID
+		}
+		return tx.CreateInBatches(leaves, checkpointLeavesBatchSize).Error
+	})
+}
+
+// GetCheckpoints 取得最近的 checkpoint（新到舊）
+func (r *auditCheckpointRepository) GetCheckpoints(limit int) ([]models.AuditCheckpoint, error) {
+	var checkpoints []models.AuditCheckpoint
+	err := r.DBClient.MasterDB.Order("id desc").Limit(limit).Find(&checkpoints).Error
+	return checkpoints, err
+}
+
+func (r *auditCheckpointRepository) GetCheckpointByID(id uint) (*models.AuditCheckpoint, error) {
+	var checkpoint models.AuditCheckpoint
+	if err := r.DBClient.MasterDB.First(&checkpoint, id).Error; err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+func (r *auditCheckpointRepository) GetLatestCheckpoint() (*models.AuditCheckpoint, error) {
+	var checkpoint models.AuditCheckpoint
+	if err := r.DBClient.MasterDB.Order("id desc").First(&checkpoint).Error; err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// GetLeavesByCheckpointID 依 Merkle tree 的順序取得 checkpoint 的葉節點
+func (r *auditCheckpointRepository) GetLeavesByCheckpointID(checkpointID uint) ([]models.AuditCheckpointLeaf, error) {
+	var leaves []models.AuditCheckpointLeaf
+	err := r.DBClient.MasterDB.Where("checkpoint_id = ?", checkpointID).Order("position asc").Find(&leaves).Error
+	return leaves, err
+}
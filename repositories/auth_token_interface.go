@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"mini-crypto-wallet-api/models"
+)
+
+type IAuthToken interface {
+	CreateRefreshToken(token *models.RefreshToken, tx ...*gorm.DB) error
+	FindRefreshTokenByHashWithTx(tokenHash string, tx ...*gorm.DB) (*models.RefreshToken, error)
+	UpdateRefreshToken(token *models.RefreshToken, tx ...*gorm.DB) error
+	GetRefreshTokensByFamily(familyID string, tx ...*gorm.DB) ([]models.RefreshToken, error)
+	RevokeFamily(familyID string, revokedAt time.Time, tx ...*gorm.DB) error
+	GetActiveFamilies(userID uint, now time.Time, tx ...*gorm.DB) ([]string, error)
+	CreateRevokedToken(token *models.RevokedToken, tx ...*gorm.DB) error
+	IsTokenRevoked(jti string) (bool, error)
+	DeleteExpired(now time.Time) error
+}
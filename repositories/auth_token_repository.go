@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories/entity"
+)
+
+type authTokenRepository struct {
+	entity.DBClient
+}
+
+func NewAuthTokenRepository() IAuthToken {
+	r := new(authTokenRepository)
+	r.DBClient.MasterDB = db_conn.Conn_DB.MasterDB
+
+	return r
+}
+
+func (r *authTokenRepository) CreateRefreshToken(token *models.RefreshToken, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Create(token).Error
+}
+
+// FindRefreshTokenByHashWithTx 以 SELECT ... FOR UPDATE 鎖定 refresh token，避免同一個 token 被同時兌換
+func (r *authTokenRepository) FindRefreshTokenByHashWithTx(tokenHash string, tx ...*gorm.DB) (*models.RefreshToken, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var token models.RefreshToken
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *authTokenRepository) UpdateRefreshToken(token *models.RefreshToken, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Save(token).Error
+}
+
+func (r *authTokenRepository) GetRefreshTokensByFamily(familyID string, tx ...*gorm.DB) ([]models.RefreshToken, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var tokens []models.RefreshToken
+	err := db.Where("family_id = ?", familyID).Order("id asc").Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokeFamily 撤銷同一個 family 中所有尚未撤銷的 refresh token
+func (r *authTokenRepository) RevokeFamily(familyID string, revokedAt time.Time, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", revokedAt).Error
+}
+
+// GetActiveFamilies 取得用戶仍有可用 refresh token 的 family
+func (r *authTokenRepository) GetActiveFamilies(userID uint, now time.Time, tx ...*gorm.DB) ([]string, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var families []string
+	err := db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Distinct().Pluck("family_id", &families).Error
+	return families, err
+}
+
+// CreateRevokedToken 將 access token 的 jti 加入黑名單，重複加入時不做任何事
+func (r *authTokenRepository) CreateRevokedToken(token *models.RevokedToken, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "jti"}}, DoNothing: true}).Create(token).Error
+}
+
+func (r *authTokenRepository) IsTokenRevoked(jti string) (bool, error) {
+	var count int64
+	err := r.DBClient.MasterDB.Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteExpired 刪除已過期的 refresh token 與黑名單紀錄
+func (r *authTokenRepository) DeleteExpired(now time.Time) error {
+	if err := r.DBClient.MasterDB.Where("expires_at <= ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+		return err
+	}
+	return r.DBClient.MasterDB.Where("expires_at <= ?", now).Delete(&models.RefreshToken{}).Error
+}
@@ -9,4 +9,9 @@ type IBalanceHistory interface {
 	CreateHistory(history *models.BalanceHistory, tx ...*gorm.DB) error
 	GetHistoryByUserID(userID uint) ([]models.BalanceHistory, error)
 	GetHistoryByWalletID(walletID uint) ([]models.BalanceHistory, error)
+	GetHistoryByTransactionID(transactionID uint) ([]models.BalanceHistory, error)
+	FindOrphanedHistories() ([]models.BalanceHistory, error)
+	FindChainByWalletID(walletID uint) ([]models.BalanceHistory, error)
+	FindChainHeads() ([]models.BalanceHistory, error)
+	FindChainHead(walletID uint, tx ...*gorm.DB) (*models.BalanceHistory, error)
 }
@@ -17,11 +17,20 @@ func NewBalanceHistoryRepository() IBalanceHistory {
 	return r
 }
 
+// CreateHistory 寫入餘額歷史並接在該錢包 hash chain 的最後
+// 呼叫端必須已鎖定錢包（SELECT ... FOR UPDATE），同一錢包的紀錄才會依序取得 sequence
 func (r *balanceHistoryRepository) CreateHistory(history *models.BalanceHistory, tx ...*gorm.DB) error {
 	var db *gorm.DB = r.DBClient.MasterDB
 	if len(tx) > 0 {
 		db = tx[0]
 	}
+
+	var prev models.BalanceHistory
+	if err := db.Where("wallet_id = ? AND sequence > 0", history.WalletID).Order("sequence desc").Limit(1).Find(&prev).Error; err != nil {
+		return err
+	}
+	history.LinkTo(&prev)
+
 	return db.Create(history).Error
 }
 
@@ -42,3 +51,58 @@ func (r *balanceHistoryRepository) GetHistoryByWalletID(walletID uint) ([]models
 		Find(&histories).Error
 	return histories, err
 }
+
+func (r *balanceHistoryRepository) GetHistoryByTransactionID(transactionID uint) ([]models.BalanceHistory, error) {
+	var histories []models.BalanceHistory
+	err := r.DBClient.MasterDB.
+		Where("transaction_id = ?", transactionID).
+		Order("id asc").
+		Find(&histories).Error
+	return histories, err
+}
+
+// FindOrphanedHistories 找出指向不存在錢包或交易的歷史紀錄
+func (r *balanceHistoryRepository) FindOrphanedHistories() ([]models.BalanceHistory, error) {
+	var histories []models.BalanceHistory
+	err := r.DBClient.MasterDB.
+		Joins("LEFT JOIN wallets ON wallets.id = balance_histories.wallet_id").
+		Joins("LEFT JOIN transactions ON transactions.id = balance_histories.transaction_id").
+		Where("wallets.id IS NULL OR transactions.id IS NULL").
+		Order("balance_histories.id asc").
+		Find(&histories).Error
+	return histories, err
+}
+
+// FindChainByWalletID 依 sequence 順序取得錢包 hash chain 上的所有紀錄
+func (r *balanceHistoryRepository) FindChainByWalletID(walletID uint) ([]models.BalanceHistory, error) {
+	var histories []models.BalanceHistory
+	err := r.DBClient.MasterDB.
+		Where("wallet_id = ? AND sequence > 0", walletID).
+		Order("sequence asc, id asc").
+		Find(&histories).Error
+	return histories, err
+}
+
+// FindChainHeads 取得每個錢包 hash chain 的最後一筆紀錄，依 wallet_id 排序
+func (r *balanceHistoryRepository) FindChainHeads() ([]models.BalanceHistory, error) {
+	var histories []models.BalanceHistory
+	err := r.DBClient.MasterDB.
+		Joins("JOIN (SELECT wallet_id, MAX(sequence) AS head FROM balance_histories WHERE sequence > 0 GROUP BY wallet_id) heads ON heads.wallet_id = balance_histories.wallet_id AND heads.head = balance_histories.sequence").
+		Order("balance_histories.wallet_id asc, balance_histories.id asc").
+		Find(&histories).Error
+	return histories, err
+}
+
+// FindChainHead 取得錢包 hash chain 的最後一筆紀錄，尚無紀錄時回傳 sequence 為 0 的空紀錄
+func (r *balanceHistoryRepository) FindChainHead(walletID uint, tx ...*gorm.DB) (*models.BalanceHistory, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var head models.BalanceHistory
+	if err := db.Where("wallet_id = ? AND sequence > 0", walletID).Order("sequence desc").Limit(1).Find(&head).Error; err != nil {
+		return nil, err
+	}
+	return &head, nil
+}
@@ -1,10 +1,19 @@
 package repositories
 
-import "mini-crypto-wallet-api/models"
+import (
+	"gorm.io/gorm"
+	"mini-crypto-wallet-api/models"
+)
 
 type ICurrency interface {
 	CreateCurrency(currency *models.Currency) error
 	GetCurrencyByCode(code string) (*models.Currency, error)
 	GetCurrencyByID(id uint) (*models.Currency, error)
 	GetAllCurrencies() ([]models.Currency, error)
+	FindCurrencyByID(id uint) (*models.Currency, error)
+	FindAllCurrencies() ([]models.Currency, error)
+	FindCurrencyByCode(code string) (*models.Currency, error)
+	FindCurrencyByIDWithTx(id uint, tx ...*gorm.DB) (*models.Currency, error)
+	FindCurrencyByIDForShareWithTx(id uint, tx ...*gorm.DB) (*models.Currency, error)
+	UpdateCurrency(currency *models.Currency, tx ...*gorm.DB) error
 }
@@ -1,6 +1,10 @@
 package repositories
 
 import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"mini-crypto-wallet-api/db_conn"
 	"mini-crypto-wallet-api/models"
 	"mini-crypto-wallet-api/repositories/entity"
@@ -16,8 +20,22 @@ func NewCurrencyRepository() ICurrency {
 	return r
 }
 
+// CreateCurrency 以單一 INSERT 新增幣種，完成後讀回完整的資料列
+// 以 map 寫入是因為 struct 的零值（decimals = 0、is_active = false）會被 GORM 換成欄位預設值
 func (r *currencyRepository) CreateCurrency(currency *models.Currency) error {
-	return r.DBClient.MasterDB.Create(currency).Error
+	now := time.Now()
+	if err := r.DBClient.MasterDB.Model(&models.Currency{}).Create(map[string]interface{}{
+		"code":       currency.Code,
+		"name":       currency.Name,
+		"symbol":     currency.Symbol,
+		"decimals":   currency.Decimals,
+		"is_active":  currency.IsActive,
+		"created_at": now,
+		"updated_at": now,
+	}).Error; err != nil {
+		return err
+	}
+	return r.DBClient.MasterDB.Where("code = ?", currency.Code).First(currency).Error
 }
 
 func (r *currencyRepository) GetCurrencyByCode(code string) (*models.Currency, error) {
@@ -41,3 +59,66 @@ func (r *currencyRepository) GetAllCurrencies() ([]models.Currency, error) {
 	err := r.DBClient.MasterDB.Where("is_active = ?", true).Find(&currencies).Error
 	return currencies, err
 }
+
+// FindCurrencyByID 取得幣種（包含已停用的幣種）
+func (r *currencyRepository) FindCurrencyByID(id uint) (*models.Currency, error) {
+	var currency models.Currency
+	if err := r.DBClient.MasterDB.First(&currency, id).Error; err != nil {
+		return nil, err
+	}
+	return &currency, nil
+}
+
+// FindAllCurrencies 取得所有幣種（包含已停用的幣種）
+func (r *currencyRepository) FindAllCurrencies() ([]models.Currency, error) {
+	var currencies []models.Currency
+	err := r.DBClient.MasterDB.Order("id asc").Find(&currencies).Error
+	return currencies, err
+}
+
+// FindCurrencyByCode 依代碼取得幣種（包含已停用的幣種）
+func (r *currencyRepository) FindCurrencyByCode(code string) (*models.Currency, error) {
+	var currency models.Currency
+	if err := r.DBClient.MasterDB.Where("code = ?", code).First(&currency).Error; err != nil {
+		return nil, err
+	}
+	return &currency, nil
+}
+
+// FindCurrencyByIDWithTx 以 SELECT ... FOR UPDATE 鎖定幣種（包含已停用的幣種），同一幣種的設定變更會依序進行
+func (r *currencyRepository) FindCurrencyByIDWithTx(id uint, tx ...*gorm.DB) (*models.Currency, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var currency models.Currency
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&currency, id).Error; err != nil {
+		return nil, err
+	}
+	return &currency, nil
+}
+
+// FindCurrencyByIDForShareWithTx 以 SELECT ... FOR SHARE 讀取幣種（包含已停用的幣種）
+// 轉帳等資金異動持有共享鎖直到交易結束，期間幣種的精度與啟用狀態不會被修改
+func (r *currencyRepository) FindCurrencyByIDForShareWithTx(id uint, tx ...*gorm.DB) (*models.Currency, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var currency models.Currency
+	if err := db.Clauses(clause.Locking{Strength: "SHARE"}).First(&currency, id).Error; err != nil {
+		return nil, err
+	}
+	return &currency, nil
+}
+
+func (r *currencyRepository) UpdateCurrency(currency *models.Currency, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Save(currency).Error
+}
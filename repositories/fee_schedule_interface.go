@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"mini-crypto-wallet-api/models"
+)
+
+type IFeeSchedule interface {
+	GetByCurrencyID(currencyID uint, tx ...*gorm.DB) (*models.FeeSchedule, error)
+	ReplaceSchedule(schedule *models.FeeSchedule, tx ...*gorm.DB) error
+	DeleteByCurrencyID(currencyID uint, tx ...*gorm.DB) error
+}
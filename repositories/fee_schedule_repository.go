@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories/entity"
+)
+
+type feeScheduleRepository struct {
+	entity.DBClient
+}
+
+func NewFeeScheduleRepository() IFeeSchedule {
+	r := new(feeScheduleRepository)
+	r.DBClient.MasterDB = db_conn.Conn_DB.MasterDB
+
+	return r
+}
+
+// GetByCurrencyID 取得幣種的手續費設定（含級距，依 min_amount 排序）
+func (r *feeScheduleRepository) GetByCurrencyID(currencyID uint, tx ...*gorm.DB) (*models.FeeSchedule, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var schedule models.FeeSchedule
+	if err := db.Preload("Tiers", func(db *gorm.DB) *gorm.DB {
+		return db.Order("min_amount asc")
+	}).Where("currency_id = ?", currencyID).First(&schedule).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// ReplaceSchedule 以新的設定取代幣種原有的手續費設定與級距
+func (r *feeScheduleRepository) ReplaceSchedule(schedule *models.FeeSchedule, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := r.DeleteByCurrencyID(schedule.CurrencyID, tx); err != nil {
+			return err
+		}
+		return tx.Create(schedule).Error
+	})
+}
+
+func (r *feeScheduleRepository) DeleteByCurrencyID(currencyID uint, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	subQuery := db.Model(&models.FeeSchedule{}).Select("id").Where("currency_id = ?", currencyID)
+	if err := db.Where("schedule_id IN (?)", subQuery).Delete(&models.FeeTier{}).Error; err != nil {
+		return err
+	}
+	return db.Where("currency_id = ?", currencyID).Delete(&models.FeeSchedule{}).Error
+}
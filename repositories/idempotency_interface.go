@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"mini-crypto-wallet-api/models"
+)
+
+type IIdempotency interface {
+	CreateKey(record *models.IdempotencyKey, tx ...*gorm.DB) error
+	FindByUserAndKey(userID uint, key string, now time.Time) (*models.IdempotencyKey, error)
+	DeleteExpiredKey(userID uint, key string, now time.Time, tx ...*gorm.DB) error
+}
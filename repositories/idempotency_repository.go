@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories/entity"
+)
+
+type idempotencyRepository struct {
+	entity.DBClient
+}
+
+func NewIdempotencyRepository() IIdempotency {
+	r := new(idempotencyRepository)
+	r.DBClient.MasterDB = db_conn.Conn_DB.MasterDB
+
+	return r
+}
+
+func (r *idempotencyRepository) CreateKey(record *models.IdempotencyKey, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Create(record).Error
+}
+
+// FindByUserAndKey 取得尚未過期的 idempotency key 紀錄
+func (r *idempotencyRepository) FindByUserAndKey(userID uint, key string, now time.Time) (*models.IdempotencyKey, error) {
+	var record models.IdempotencyKey
+	if err := r.DBClient.MasterDB.
+		Where("user_id = ? AND idempotency_key = ? AND expires_at > ?", userID, key, now).
+		First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// DeleteExpiredKey 刪除同一個 key 已過期的舊紀錄，讓 key 可以在保留期後重新使用
+func (r *idempotencyRepository) DeleteExpiredKey(userID uint, key string, now time.Time, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Where("user_id = ? AND idempotency_key = ? AND expires_at <= ?", userID, key, now).
+		Delete(&models.IdempotencyKey{}).Error
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"mini-crypto-wallet-api/models"
+)
+
+type ILedger interface {
+	FindAccountByCodeWithTx(code string, tx ...*gorm.DB) (*models.LedgerAccount, error)
+	CreateAccountIfNotExists(account *models.LedgerAccount, tx ...*gorm.DB) (bool, error)
+	UpdateAccountBalance(account *models.LedgerAccount, tx ...*gorm.DB) error
+	CreateJournalEntry(entry *models.JournalEntry, tx ...*gorm.DB) error
+	GetAccountsByWalletID(walletID uint) ([]models.LedgerAccount, error)
+	SumPostings(accountID uint) (decimal.Decimal, error)
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories/entity"
+)
+
+type ledgerRepository struct {
+	entity.DBClient
+}
+
+func NewLedgerRepository() ILedger {
+	r := new(ledgerRepository)
+	r.DBClient.MasterDB = db_conn.Conn_DB.MasterDB
+
+	return r
+}
+
+// FindAccountByCodeWithTx 以 SELECT ... FOR UPDATE 鎖定帳戶
+func (r *ledgerRepository) FindAccountByCodeWithTx(code string, tx ...*gorm.DB) (*models.LedgerAccount, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var account models.LedgerAccount
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// CreateAccountIfNotExists 建立帳戶，帳戶已存在時不做任何事並回傳 false
+func (r *ledgerRepository) CreateAccountIfNotExists(account *models.LedgerAccount, tx ...*gorm.DB) (bool, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	result := db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}).Create(account)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *ledgerRepository) UpdateAccountBalance(account *models.LedgerAccount, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Model(account).Update("balance", account.Balance).Error
+}
+
+// CreateJournalEntry 建立分錄與其所有過帳明細
+func (r *ledgerRepository) CreateJournalEntry(entry *models.JournalEntry, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Create(entry).Error
+}
+
+func (r *ledgerRepository) GetAccountsByWalletID(walletID uint) ([]models.LedgerAccount, error) {
+	var accounts []models.LedgerAccount
+	err := r.DBClient.MasterDB.Where("wallet_id = ?", walletID).Find(&accounts).Error
+	return accounts, err
+}
+
+// SumPostings 由過帳明細重新計算帳戶餘額（credit - debit）
+func (r *ledgerRepository) SumPostings(accountID uint) (decimal.Decimal, error) {
+	var postings []models.LedgerPosting
+	if err := r.DBClient.MasterDB.Where("account_id = ?", accountID).Find(&postings).Error; err != nil {
+		return decimal.Zero, err
+	}
+
+	balance := decimal.Zero
+	for _, posting := range postings {
+		if posting.Direction == models.PostingCredit {
+			balance = balance.Add(posting.Amount)
+		} else {
+			balance = balance.Sub(posting.Amount)
+		}
+	}
+	return balance, nil
+}
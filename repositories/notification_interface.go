@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"time"
+
+	"mini-crypto-wallet-api/models"
+)
+
+type INotification interface {
+	CreateIfAbsent(notification *models.Notification) (bool, error)
+	FindByUserID(userID uint, unreadOnly bool, offset, limit int) ([]models.Notification, int64, error)
+	FindByIDAndUserID(id, userID uint) (*models.Notification, error)
+	CountUnread(userID uint) (int64, error)
+	MarkRead(id, userID uint, readAt time.Time) error
+	MarkAllRead(userID uint, readAt time.Time) (int64, error)
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm/clause"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories/entity"
+)
+
+type notificationRepository struct {
+	entity.DBClient
+}
+
+func NewNotificationRepository() INotification {
+	r := new(notificationRepository)
+	r.DBClient.MasterDB = db_conn.Conn_DB.MasterDB
+	return r
+}
+
+// CreateIfAbsent 新增通知，同一用戶的同一事件已存在時不做任何事並回傳 false
+func (r *notificationRepository) CreateIfAbsent(notification *models.Notification) (bool, error) {
+	result := r.DBClient.MasterDB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "event_key"}},
+		DoNothing: true,
+	}).Create(notification)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// FindByUserID 分頁取得用戶的通知（新到舊），unreadOnly 時只回傳未讀
+func (r *notificationRepository) FindByUserID(userID uint, unreadOnly bool, offset, limit int) ([]models.Notification, int64, error) {
+	var notifications []models.Notification
+	var total int64
+
+	query := r.DBClient.MasterDB.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id desc").Offset(offset).Limit(limit).Find(&notifications).Error
+	return notifications, total, err
+}
+
+func (r *notificationRepository) FindByIDAndUserID(id, userID uint) (*models.Notification, error) {
+	var notification models.Notification
+	if err := r.DBClient.MasterDB.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+func (r *notificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.DBClient.MasterDB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead 將通知標記為已讀，已讀的通知保留原本的已讀時間
+func (r *notificationRepository) MarkRead(id, userID uint, readAt time.Time) error {
+	return r.DBClient.MasterDB.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		Update("read_at", readAt).Error
+}
+
+// MarkAllRead 將用戶所有未讀通知標記為已讀，回傳更新的數量
+func (r *notificationRepository) MarkAllRead(userID uint, readAt time.Time) (int64, error) {
+	result := r.DBClient.MasterDB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", readAt)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"mini-crypto-wallet-api/models"
+)
+
+type IOutbox interface {
+	CreateEvent(event *models.OutboxEvent, tx ...*gorm.DB) error
+	FetchPendingEvents(now time.Time, limit int, tx ...*gorm.DB) ([]models.OutboxEvent, error)
+	LeaseEvents(ids []uint, until time.Time, tx ...*gorm.DB) error
+	MarkPublished(id uint, publishedAt time.Time, tx ...*gorm.DB) (bool, error)
+	MarkFailed(id uint, attempts int, nextAttemptAt time.Time, lastError string, tx ...*gorm.DB) error
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories/entity"
+)
+
+type outboxRepository struct {
+	entity.DBClient
+}
+
+func NewOutboxRepository() IOutbox {
+	r := new(outboxRepository)
+	r.DBClient.MasterDB = db_conn.Conn_DB.MasterDB
+
+	return r
+}
+
+func (r *outboxRepository) CreateEvent(event *models.OutboxEvent, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	if event.Status == "" {
+		event.Status = models.OutboxStatusPending
+	}
+	if event.NextAttemptAt.IsZero() {
+		event.NextAttemptAt = time.Now()
+	}
+
+	return db.Create(event).Error
+}
+
+// FetchPendingEvents 取得到期待發送的事件
+// 使用 FOR UPDATE SKIP LOCKED，讓多個 relay 實例不會重複處理同一筆事件
+func (r *outboxRepository) FetchPendingEvents(now time.Time, limit int, tx ...*gorm.DB) ([]models.OutboxEvent, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var events []models.OutboxEvent
+	err := db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_attempt_at <= ?", models.OutboxStatusPending, now).
+		Order("id asc").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// LeaseEvents 把 next_attempt_at 推到 until，租約到期前其他 relay 不會再取到這些事件
+func (r *outboxRepository) LeaseEvents(ids []uint, until time.Time, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+	return db.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update("next_attempt_at", until).Error
+}
+
+// MarkPublished 將事件標記為已發送
+// 只會更新仍為 pending 的事件，回傳 false 代表事件已被其他 relay 標記
+func (r *outboxRepository) MarkPublished(id uint, publishedAt time.Time, tx ...*gorm.DB) (bool, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	result := db.Model(&models.OutboxEvent{}).
+		Where("id = ? AND status = ?", id, models.OutboxStatusPending).
+		Updates(map[string]interface{}{
+			"status":       models.OutboxStatusPublished,
+			"published_at": publishedAt,
+			"last_error":   "",
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *outboxRepository) MarkFailed(id uint, attempts int, nextAttemptAt time.Time, lastError string, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Model(&models.OutboxEvent{}).
+		Where("id = ? AND status = ?", id, models.OutboxStatusPending).
+		Updates(map[string]interface{}{
+			"attempts":        attempts,
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastError,
+		}).Error
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"mini-crypto-wallet-api/models"
+)
+
+type IReconciliation interface {
+	CreateRun(run *models.ReconciliationRun) error
+	UpdateRun(run *models.ReconciliationRun) error
+	GetRuns(limit int) ([]models.ReconciliationRun, error)
+	GetRunByID(id uint) (*models.ReconciliationRun, error)
+	CreateFindings(findings []models.ReconciliationFinding) error
+	GetFindingsByRunID(runID uint, findingType string) ([]models.ReconciliationFinding, error)
+}
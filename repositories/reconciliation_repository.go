@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories/entity"
+)
+
+const findingsBatchSize = 500
+
+type reconciliationRepository struct {
+	entity.DBClient
+}
+
+func NewReconciliationRepository() IReconciliation {
+	r := new(reconciliationRepository)
+	r.DBClient.MasterDB = db_conn.Conn_DB.MasterDB
+	return r
+}
+
+func (r *reconciliationRepository) CreateRun(run *models.ReconciliationRun) error {
+	return r.DBClient.MasterDB.Create(run).Error
+}
+
+func (r *reconciliationRepository) UpdateRun(run *models.ReconciliationRun) error {
+	return r.DBClient.MasterDB.Save(run).Error
+}
+
+// GetRuns 取得最近的對帳紀錄（新到舊）
+func (r *reconciliationRepository) GetRuns(limit int) ([]models.ReconciliationRun, error) {
+	var runs []models.ReconciliationRun
+	err := r.DBClient.MasterDB.Order("id desc").Limit(limit).Find(&runs).Error
+	return runs, err
+}
+
+func (r *reconciliationRepository) GetRunByID(id uint) (*models.ReconciliationRun, error) {
+	var run models.ReconciliationRun
+	if err := r.DBClient.MasterDB.First(&run, id).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *reconciliationRepository) CreateFindings(findings []models.ReconciliationFinding) error {
+	if len(findings) == 0 {
+		return nil
+	}
+	return r.DBClient.MasterDB.CreateInBatches(findings, findingsBatchSize).Error
+}
+
+// GetFindingsByRunID 取得某次對帳的異常，findingType 為空時回傳全部
+func (r *reconciliationRepository) GetFindingsByRunID(runID uint, findingType string) ([]models.ReconciliationFinding, error) {
+	var findings []models.ReconciliationFinding
+	query := r.DBClient.MasterDB.Where("run_id = ?", runID)
+	if findingType != "" {
+		query = query.Where("type = ?", findingType)
+	}
+	err := query.Order("id asc").Find(&findings).Error
+	return findings, err
+}
@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"mini-crypto-wallet-api/models"
+)
+
+type IScheduledTransfer interface {
+	CreateSchedule(schedule *models.ScheduledTransfer, tx ...*gorm.DB) error
+	FindScheduleByID(id uint) (*models.ScheduledTransfer, error)
+	FindScheduleByIDWithTx(id uint, tx ...*gorm.DB) (*models.ScheduledTransfer, error)
+	FindSchedulesByUserID(userID uint) ([]models.ScheduledTransfer, error)
+	UpdateSchedule(schedule *models.ScheduledTransfer, tx ...*gorm.DB) error
+	FindDueScheduleIDs(now time.Time, limit int) ([]uint, error)
+	FindRun(scheduleID uint, occurrence int, tx ...*gorm.DB) (*models.ScheduledTransferRun, error)
+	SaveRun(run *models.ScheduledTransferRun, tx ...*gorm.DB) error
+	FindRunsByScheduleID(scheduleID uint) ([]models.ScheduledTransferRun, error)
+}
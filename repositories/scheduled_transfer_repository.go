@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories/entity"
+)
+
+type scheduledTransferRepository struct {
+	entity.DBClient
+}
+
+func NewScheduledTransferRepository() IScheduledTransfer {
+	r := new(scheduledTransferRepository)
+	r.DBClient.MasterDB = db_conn.Conn_DB.MasterDB
+
+	return r
+}
+
+func (r *scheduledTransferRepository) CreateSchedule(schedule *models.ScheduledTransfer, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Create(schedule).Error
+}
+
+func (r *scheduledTransferRepository) FindScheduleByID(id uint) (*models.ScheduledTransfer, error) {
+	var schedule models.ScheduledTransfer
+	if err := r.DBClient.MasterDB.First(&schedule, id).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// FindScheduleByIDWithTx 以 SELECT ... FOR UPDATE 鎖定排程，同一排程同時只會有一個 worker 執行
+func (r *scheduledTransferRepository) FindScheduleByIDWithTx(id uint, tx ...*gorm.DB) (*models.ScheduledTransfer, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var schedule models.ScheduledTransfer
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&schedule, id).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// FindSchedulesByUserID 取得用戶未取消的排程
+func (r *scheduledTransferRepository) FindSchedulesByUserID(userID uint) ([]models.ScheduledTransfer, error) {
+	var schedules []models.ScheduledTransfer
+	err := r.DBClient.MasterDB.
+		Where("user_id = ? AND status <> ?", userID, models.ScheduleStatusCancelled).
+		Order("id desc").
+		Find(&schedules).Error
+	return schedules, err
+}
+
+func (r *scheduledTransferRepository) UpdateSchedule(schedule *models.ScheduledTransfer, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Save(schedule).Error
+}
+
+// FindDueScheduleIDs 取得已到執行時間的排程 ID
+func (r *scheduledTransferRepository) FindDueScheduleIDs(now time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.DBClient.MasterDB.Model(&models.ScheduledTransfer{}).
+		Where("status = ? AND next_attempt_at <= ?", models.ScheduleStatusActive, now).
+		Order("next_attempt_at asc").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *scheduledTransferRepository) FindRun(scheduleID uint, occurrence int, tx ...*gorm.DB) (*models.ScheduledTransferRun, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var run models.ScheduledTransferRun
+	if err := db.Where("schedule_id = ? AND occurrence = ?", scheduleID, occurrence).First(&run).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *scheduledTransferRepository) SaveRun(run *models.ScheduledTransferRun, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Save(run).Error
+}
+
+func (r *scheduledTransferRepository) FindRunsByScheduleID(scheduleID uint) ([]models.ScheduledTransferRun, error) {
+	var runs []models.ScheduledTransferRun
+	err := r.DBClient.MasterDB.Where("schedule_id = ?", scheduleID).Order("occurrence desc").Find(&runs).Error
+	return runs, err
+}
@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"mini-crypto-wallet-api/models"
+)
+
+type ISwap interface {
+	CreateQuote(quote *models.SwapQuote, tx ...*gorm.DB) error
+	FindQuoteByQuoteIDWithTx(quoteID string, tx ...*gorm.DB) (*models.SwapQuote, error)
+	UpdateQuote(quote *models.SwapQuote, tx ...*gorm.DB) error
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories/entity"
+)
+
+type swapRepository struct {
+	entity.DBClient
+}
+
+func NewSwapRepository() ISwap {
+	r := new(swapRepository)
+	r.DBClient.MasterDB = db_conn.Conn_DB.MasterDB
+
+	return r
+}
+
+func (r *swapRepository) CreateQuote(quote *models.SwapQuote, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Create(quote).Error
+}
+
+// FindQuoteByQuoteIDWithTx 以 SELECT ... FOR UPDATE 鎖定報價，避免同一報價被重複執行
+func (r *swapRepository) FindQuoteByQuoteIDWithTx(quoteID string, tx ...*gorm.DB) (*models.SwapQuote, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var quote models.SwapQuote
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("quote_id = ?", quoteID).
+		First(&quote).Error; err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+func (r *swapRepository) UpdateQuote(quote *models.SwapQuote, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Save(quote).Error
+}
@@ -1,6 +1,9 @@
 package repositories
 
 import (
+	"time"
+
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 	"mini-crypto-wallet-api/models"
 )
@@ -10,4 +13,9 @@ type ITransaction interface {
 	GetTransactionsByUserID(userID uint) ([]models.Transaction, error)
 	GetTransactionsByUserIDWithPagination(userID uint, offset, limit int) ([]models.Transaction, int64, error)
 	FindByHash(hash string) (*models.Transaction, error)
+	FindByHashWithTx(hash string, tx ...*gorm.DB) (*models.Transaction, error)
+	UpdateTransaction(transaction *models.Transaction, tx ...*gorm.DB) error
+	FindTransactionsInBatches(batchSize int, fn func([]models.Transaction) error) error
+	FindLegacyHashTransactions(limit int, tx ...*gorm.DB) ([]models.Transaction, error)
+	SumOutgoingTransfers(userID, currencyID uint, since time.Time, tx ...*gorm.DB) (decimal.Decimal, int64, error)
 }
@@ -1,7 +1,11 @@
 package repositories
 
 import (
+	"time"
+
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"mini-crypto-wallet-api/db_conn"
 	"mini-crypto-wallet-api/models"
 	"mini-crypto-wallet-api/repositories/entity"
@@ -59,10 +63,77 @@ func (r *transactionRepository) GetTransactionsByUserIDWithPagination(userID uin
 	return txs, total, err
 }
 
+// FindByHash 以 hash 查詢交易，遷移前的舊 hash 也能查到
 func (r *transactionRepository) FindByHash(hash string) (*models.Transaction, error) {
 	var tx models.Transaction
-	if err := r.DBClient.MasterDB.Where("hash = ?", hash).First(&tx).Error; err != nil {
+	if err := r.DBClient.MasterDB.Where("hash = ? OR (legacy_hash <> '' AND legacy_hash = ?)", hash, hash).First(&tx).Error; err != nil {
 		return nil, err
 	}
 	return &tx, nil
 }
+
+// FindByHashWithTx 以 SELECT ... FOR UPDATE 鎖定交易，用於狀態轉換
+func (r *transactionRepository) FindByHashWithTx(hash string, tx ...*gorm.DB) (*models.Transaction, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var transaction models.Transaction
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("hash = ? OR (legacy_hash <> '' AND legacy_hash = ?)", hash, hash).First(&transaction).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (r *transactionRepository) UpdateTransaction(transaction *models.Transaction, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Save(transaction).Error
+}
+
+// FindTransactionsInBatches 依 ID 順序分批走訪所有交易
+func (r *transactionRepository) FindTransactionsInBatches(batchSize int, fn func([]models.Transaction) error) error {
+	var transactions []models.Transaction
+	return r.DBClient.MasterDB.Order("id asc").FindInBatches(&transactions, batchSize, func(_ *gorm.DB, _ int) error {
+		return fn(transactions)
+	}).Error
+}
+
+// SumOutgoingTransfers 統計用戶自 since 起在該幣種轉出（未失敗）的金額總和與筆數
+func (r *transactionRepository) SumOutgoingTransfers(userID, currencyID uint, since time.Time, tx ...*gorm.DB) (decimal.Decimal, int64, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var result struct {
+		Total decimal.Decimal
+		Count int64
+	}
+	err := db.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(amount), 0) AS total, COUNT(*) AS count").
+		Where("type = ? AND from_user_id = ? AND currency_id = ? AND status <> ? AND created_at >= ?",
+			models.TxTypeTransfer, userID, currencyID, models.TxStatusFailed, since).
+		Scan(&result).Error
+	return result.Total, result.Count, err
+}
+
+// FindLegacyHashTransactions 以 SELECT ... FOR UPDATE 鎖定尚未遷移到 content-addressed hash 的交易
+func (r *transactionRepository) FindLegacyHashTransactions(limit int, tx ...*gorm.DB) ([]models.Transaction, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var transactions []models.Transaction
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("hash_version = ?", models.HashVersionLegacy).
+		Order("id asc").
+		Limit(limit).
+		Find(&transactions).Error
+	return transactions, err
+}
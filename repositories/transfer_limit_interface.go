@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"mini-crypto-wallet-api/models"
+)
+
+type ITransferLimit interface {
+	FindLimit(userID, currencyID uint, tx ...*gorm.DB) (*models.TransferLimit, error)
+	FindLimitsByUserID(userID uint) ([]models.TransferLimit, error)
+	UpsertLimit(limit *models.TransferLimit, tx ...*gorm.DB) error
+	DeleteLimit(userID, currencyID uint, tx ...*gorm.DB) (bool, error)
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories/entity"
+)
+
+type transferLimitRepository struct {
+	entity.DBClient
+}
+
+func NewTransferLimitRepository() ITransferLimit {
+	r := new(transferLimitRepository)
+	r.DBClient.MasterDB = db_conn.Conn_DB.MasterDB
+
+	return r
+}
+
+// FindLimit 取得指定用戶（0 為幣種預設）在該幣種的限額設定
+func (r *transferLimitRepository) FindLimit(userID, currencyID uint, tx ...*gorm.DB) (*models.TransferLimit, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var limit models.TransferLimit
+	if err := db.Where("user_id = ? AND currency_id = ?", userID, currencyID).First(&limit).Error; err != nil {
+		return nil, err
+	}
+	return &limit, nil
+}
+
+func (r *transferLimitRepository) FindLimitsByUserID(userID uint) ([]models.TransferLimit, error) {
+	var limits []models.TransferLimit
+	err := r.DBClient.MasterDB.Where("user_id = ?", userID).Order("currency_id asc").Find(&limits).Error
+	return limits, err
+}
+
+// UpsertLimit 新增或覆寫 (user_id, currency_id) 的限額設定
+func (r *transferLimitRepository) UpsertLimit(limit *models.TransferLimit, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "currency_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_single_amount", "daily_limit", "monthly_limit", "max_transfers_per_hour", "updated_at"}),
+	}).Create(limit).Error
+}
+
+// DeleteLimit 刪除限額設定，回傳是否有刪除資料
+func (r *transferLimitRepository) DeleteLimit(userID, currencyID uint, tx ...*gorm.DB) (bool, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	result := db.Where("user_id = ? AND currency_id = ?", userID, currencyID).Delete(&models.TransferLimit{})
+	return result.RowsAffected > 0, result.Error
+}
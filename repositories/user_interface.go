@@ -1,9 +1,13 @@
 package repositories
 
-import "mini-crypto-wallet-api/models"
+import (
+	"gorm.io/gorm"
+	"mini-crypto-wallet-api/models"
+)
 
 type IUser interface {
-	CreateUser(user *models.User) error
+	CreateUser(user *models.User, tx ...*gorm.DB) error
 	GetUserByUsername(username string) (*models.User, error)
 	GetUserByID(userID uint) (*models.User, error)
+	UpdateUser(user *models.User, tx ...*gorm.DB) error
 }
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"gorm.io/gorm"
 	"mini-crypto-wallet-api/db_conn"
 	"mini-crypto-wallet-api/models"
 	"mini-crypto-wallet-api/repositories/entity"
@@ -17,8 +18,12 @@ func NewUserRepository() IUser {
 	return r
 }
 
-func (r *userRepository) CreateUser(user *models.User) error {
-	return r.DBClient.MasterDB.Create(user).Error
+func (r *userRepository) CreateUser(user *models.User, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+	return db.Create(user).Error
 }
 
 func (r *userRepository) GetUserByUsername(username string) (*models.User, error) {
@@ -29,6 +34,14 @@ func (r *userRepository) GetUserByUsername(username string) (*models.User, error
 	return &user, nil
 }
 
+func (r *userRepository) UpdateUser(user *models.User, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+	return db.Save(user).Error
+}
+
 func (r *userRepository) GetUserByID(userID uint) (*models.User, error) {
 	var user models.User
 	if err := r.DBClient.MasterDB.Where("id = ?", userID).First(&user).Error; err != nil {
@@ -7,8 +7,13 @@ import (
 
 type IWallet interface {
 	GetWalletByUserID(userID uint) (*models.Wallet, error)
-	GetWalletByUserIDWithTx(userID uint, tx ...*gorm.DB) (*models.Wallet, error)
+	GetWalletsByUserID(userID uint) ([]models.Wallet, error)
 	GetWalletByUserIDAndCurrency(userID uint, currencyID uint) (*models.Wallet, error)
+	GetWalletByUserIDAndCurrencyWithTx(userID uint, currencyID uint, tx ...*gorm.DB) (*models.Wallet, error)
+	GetWalletByUserIDAndCurrencyForShareWithTx(userID uint, currencyID uint, tx ...*gorm.DB) (*models.Wallet, error)
 	CreateWallet(wallet *models.Wallet, tx ...*gorm.DB) error
+	CreateWalletIfAbsent(wallet *models.Wallet, tx ...*gorm.DB) (bool, error)
 	UpdateWallet(wallet *models.Wallet, tx ...*gorm.DB) error
+	FindWalletsInBatches(batchSize int, fn func([]models.Wallet) error) error
+	FindWalletsByCurrencyInBatches(currencyID uint, batchSize int, fn func([]models.Wallet) error, tx ...*gorm.DB) error
 }
@@ -19,15 +19,36 @@ func NewWalletRepository() IWallet {
 	return r
 }
 
+// GetWalletByUserID 取得用戶最早開立的錢包（預設錢包）
 func (r *walletRepository) GetWalletByUserID(userID uint) (*models.Wallet, error) {
 	var wallet models.Wallet
-	if err := r.DBClient.MasterDB.Where("user_id = ?", userID).First(&wallet).Error; err != nil {
+	if err := r.DBClient.MasterDB.Where("user_id = ?", userID).Order("id asc").First(&wallet).Error; err != nil {
 		return nil, err
 	}
 	return &wallet, nil
 }
 
-func (r *walletRepository) GetWalletByUserIDWithTx(userID uint, tx ...*gorm.DB) (*models.Wallet, error) {
+// GetWalletsByUserID 取得用戶所有幣種的錢包（含幣種資訊）
+func (r *walletRepository) GetWalletsByUserID(userID uint) ([]models.Wallet, error) {
+	var wallets []models.Wallet
+	err := r.DBClient.MasterDB.
+		Preload("Currency").
+		Where("user_id = ?", userID).
+		Order("id asc").
+		Find(&wallets).Error
+	return wallets, err
+}
+
+func (r *walletRepository) GetWalletByUserIDAndCurrency(userID uint, currencyID uint) (*models.Wallet, error) {
+	var wallet models.Wallet
+	if err := r.DBClient.MasterDB.Where("user_id = ? AND currency_id = ?", userID, currencyID).First(&wallet).Error; err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// GetWalletByUserIDAndCurrencyWithTx 以 SELECT ... FOR UPDATE 鎖定指定幣種的錢包
+func (r *walletRepository) GetWalletByUserIDAndCurrencyWithTx(userID uint, currencyID uint, tx ...*gorm.DB) (*models.Wallet, error) {
 	var db *gorm.DB = r.DBClient.MasterDB
 	if len(tx) > 0 {
 		db = tx[0]
@@ -35,18 +56,30 @@ func (r *walletRepository) GetWalletByUserIDWithTx(userID uint, tx ...*gorm.DB)
 
 	var wallet models.Wallet
 
-	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&wallet).Error; err != nil {
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND currency_id = ?", userID, currencyID).
+		First(&wallet).Error; err != nil {
 		return nil, err
 	}
 
 	return &wallet, nil
 }
 
-func (r *walletRepository) GetWalletByUserIDAndCurrency(userID uint, currencyID uint) (*models.Wallet, error) {
+// GetWalletByUserIDAndCurrencyForShareWithTx 以 SELECT ... FOR SHARE 讀取指定幣種的錢包，交易結束前餘額不會被改動
+func (r *walletRepository) GetWalletByUserIDAndCurrencyForShareWithTx(userID uint, currencyID uint, tx ...*gorm.DB) (*models.Wallet, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
 	var wallet models.Wallet
-	if err := r.DBClient.MasterDB.Where("user_id = ? AND currency_id = ?", userID, currencyID).First(&wallet).Error; err != nil {
+
+	if err := db.Clauses(clause.Locking{Strength: "SHARE"}).
+		Where("user_id = ? AND currency_id = ?", userID, currencyID).
+		First(&wallet).Error; err != nil {
 		return nil, err
 	}
+
 	return &wallet, nil
 }
 
@@ -59,6 +92,24 @@ func (r *walletRepository) CreateWallet(wallet *models.Wallet, tx ...*gorm.DB) e
 	return db.Create(wallet).Error
 }
 
+// CreateWalletIfAbsent 建立錢包，用戶已有同幣種錢包（idx_wallet_user_currency 衝突）時不寫入並回傳 false
+// 併發開立同幣種錢包時由唯一索引決定，不依賴事前查詢
+func (r *walletRepository) CreateWalletIfAbsent(wallet *models.Wallet, tx ...*gorm.DB) (bool, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	result := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "currency_id"}},
+		DoNothing: true,
+	}).Create(wallet)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
 func (r *walletRepository) UpdateWallet(wallet *models.Wallet, tx ...*gorm.DB) error {
 	var db *gorm.DB = r.DBClient.MasterDB
 	if len(tx) > 0 {
@@ -67,3 +118,24 @@ func (r *walletRepository) UpdateWallet(wallet *models.Wallet, tx ...*gorm.DB) e
 
 	return db.Save(wallet).Error
 }
+
+// FindWalletsInBatches 依 ID 順序分批走訪所有錢包
+func (r *walletRepository) FindWalletsInBatches(batchSize int, fn func([]models.Wallet) error) error {
+	var wallets []models.Wallet
+	return r.DBClient.MasterDB.Order("id asc").FindInBatches(&wallets, batchSize, func(_ *gorm.DB, _ int) error {
+		return fn(wallets)
+	}).Error
+}
+
+// FindWalletsByCurrencyInBatches 依 ID 順序分批走訪指定幣種的錢包
+func (r *walletRepository) FindWalletsByCurrencyInBatches(currencyID uint, batchSize int, fn func([]models.Wallet) error, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var wallets []models.Wallet
+	return db.Where("currency_id = ?", currencyID).Order("id asc").FindInBatches(&wallets, batchSize, func(_ *gorm.DB, _ int) error {
+		return fn(wallets)
+	}).Error
+}
@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"mini-crypto-wallet-api/models"
+)
+
+type IWebhook interface {
+	CreateEndpoint(endpoint *models.WebhookEndpoint) error
+	UpdateEndpoint(endpoint *models.WebhookEndpoint) error
+	DeleteEndpoint(id, userID uint) error
+	FindEndpointByIDAndUserID(id, userID uint) (*models.WebhookEndpoint, error)
+	FindEndpointsByUserID(userID uint) ([]models.WebhookEndpoint, error)
+	FindEndpointsByIDs(ids []uint, tx ...*gorm.DB) ([]models.WebhookEndpoint, error)
+	CountEndpoints(userID uint) (int64, error)
+
+	CreateDeliveryIfAbsent(delivery *models.WebhookDelivery) (bool, error)
+	FindDeliveriesByEndpointID(endpointID uint, offset, limit int) ([]models.WebhookDelivery, int64, error)
+	FindDeliveryByIDAndEndpointID(id, endpointID uint) (*models.WebhookDelivery, error)
+	FetchDueDeliveries(now time.Time, limit int, tx ...*gorm.DB) ([]models.WebhookDelivery, error)
+	LeaseDeliveries(ids []uint, until time.Time, token string, tx ...*gorm.DB) error
+	UpdateLeasedDelivery(delivery *models.WebhookDelivery, token string, tx ...*gorm.DB) (bool, error)
+	RequeueDelivery(id, endpointID uint, now time.Time, tx ...*gorm.DB) (bool, error)
+}
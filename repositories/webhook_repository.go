@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories/entity"
+)
+
+type webhookRepository struct {
+	entity.DBClient
+}
+
+func NewWebhookRepository() IWebhook {
+	r := new(webhookRepository)
+	r.DBClient.MasterDB = db_conn.Conn_DB.MasterDB
+	return r
+}
+
+func (r *webhookRepository) CreateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return r.DBClient.MasterDB.Create(endpoint).Error
+}
+
+func (r *webhookRepository) UpdateEndpoint(endpoint *models.WebhookEndpoint) error {
+	return r.DBClient.MasterDB.Save(endpoint).Error
+}
+
+// DeleteEndpoint 刪除 endpoint 與它的投遞紀錄
+func (r *webhookRepository) DeleteEndpoint(id, userID uint) error {
+	return r.DBClient.MasterDB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.WebhookEndpoint{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("endpoint_id = ?", id).Delete(&models.WebhookDelivery{}).Error
+	})
+}
+
+func (r *webhookRepository) FindEndpointByIDAndUserID(id, userID uint) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	if err := r.DBClient.MasterDB.Where("id = ? AND user_id = ?", id, userID).First(&endpoint).Error; err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (r *webhookRepository) FindEndpointsByUserID(userID uint) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	err := r.DBClient.MasterDB.Where("user_id = ?", userID).Order("id asc").Find(&endpoints).Error
+	return endpoints, err
+}
+
+func (r *webhookRepository) FindEndpointsByIDs(ids []uint, tx ...*gorm.DB) ([]models.WebhookEndpoint, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var endpoints []models.WebhookEndpoint
+	if len(ids) == 0 {
+		return endpoints, nil
+	}
+	err := db.Where("id IN ?", ids).Find(&endpoints).Error
+	return endpoints, err
+}
+
+func (r *webhookRepository) CountEndpoints(userID uint) (int64, error) {
+	var count int64
+	err := r.DBClient.MasterDB.Model(&models.WebhookEndpoint{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// CreateDeliveryIfAbsent 新增投遞紀錄，同一 endpoint 的同一事件已存在時不做任何事並回傳 false
+func (r *webhookRepository) CreateDeliveryIfAbsent(delivery *models.WebhookDelivery) (bool, error) {
+	result := r.DBClient.MasterDB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "endpoint_id"}, {Name: "event_id"}},
+		DoNothing: true,
+	}).Create(delivery)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// FindDeliveriesByEndpointID 分頁取得 endpoint 的投遞紀錄（新到舊）
+func (r *webhookRepository) FindDeliveriesByEndpointID(endpointID uint, offset, limit int) ([]models.WebhookDelivery, int64, error) {
+	var deliveries []models.WebhookDelivery
+	var total int64
+
+	query := r.DBClient.MasterDB.Model(&models.WebhookDelivery{}).Where("endpoint_id = ?", endpointID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id desc").Offset(offset).Limit(limit).Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+func (r *webhookRepository) FindDeliveryByIDAndEndpointID(id, endpointID uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := r.DBClient.MasterDB.Where("id = ? AND endpoint_id = ?", id, endpointID).First(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// FetchDueDeliveries 取得到期待投遞的紀錄
+// 使用 FOR UPDATE SKIP LOCKED，需在交易中搭配 LeaseDeliveries 使用，讓多個 dispatcher 實例不會重複投遞同一筆
+func (r *webhookRepository) FetchDueDeliveries(now time.Time, limit int, tx ...*gorm.DB) ([]models.WebhookDelivery, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	var deliveries []models.WebhookDelivery
+	err := db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+		Order("id asc").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// LeaseDeliveries 把 next_attempt_at 推到 until 並記下租約 token，租約到期前其他 dispatcher 不會再取到這些紀錄
+func (r *webhookRepository) LeaseDeliveries(ids []uint, until time.Time, token string, tx ...*gorm.DB) error {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+	return db.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"next_attempt_at": until,
+		"lease_token":     token,
+	}).Error
+}
+
+// UpdateLeasedDelivery 寫回投遞結果並釋放租約，租約已被其他 dispatcher 或重新投遞取走時不寫入並回傳 false
+func (r *webhookRepository) UpdateLeasedDelivery(delivery *models.WebhookDelivery, token string, tx ...*gorm.DB) (bool, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	result := db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND lease_token = ?", delivery.ID, token).
+		Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"response_status": delivery.ResponseStatus,
+			"last_error":      delivery.LastError,
+			"last_attempt_at": delivery.LastAttemptAt,
+			"delivered_at":    delivery.DeliveredAt,
+			"lease_token":     "",
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	delivery.LeaseToken = ""
+	return result.RowsAffected == 1, nil
+}
+
+// RequeueDelivery 把投遞重設為 pending 並在 now 到期，重試次數從頭計算
+// 投遞的租約尚未到期（正在送出）時不做任何事並回傳 false
+func (r *webhookRepository) RequeueDelivery(id, endpointID uint, now time.Time, tx ...*gorm.DB) (bool, error) {
+	var db *gorm.DB = r.DBClient.MasterDB
+	if len(tx) > 0 {
+		db = tx[0]
+	}
+
+	result := db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND endpoint_id = ?", id, endpointID).
+		Where("lease_token = '' OR next_attempt_at <= ?", now).
+		Updates(map[string]interface{}{
+			"status":          models.WebhookDeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"delivered_at":    nil,
+			"lease_token":     "",
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
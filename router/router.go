@@ -3,9 +3,9 @@ package router
 import (
 	"mini-crypto-wallet-api/handlers"
 	"mini-crypto-wallet-api/internal/auth"
-	"mini-crypto-wallet-api/internal/config"
-	"mini-crypto-wallet-api/kafka_client"
+	"mini-crypto-wallet-api/internal/rates"
 	"mini-crypto-wallet-api/middleware"
+	"mini-crypto-wallet-api/models"
 	"mini-crypto-wallet-api/repositories"
 	"mini-crypto-wallet-api/services"
 	"time"
@@ -13,19 +13,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(producer *kafka_client.KafkaProducer) *gin.Engine {
+func SetupRouter(jwtManager *auth.JWTManager, rateProvider rates.Provider, precision services.PrecisionPolicy, swapOptions services.SwapOptions, webhookService *services.WebhookService, streamHeartbeat time.Duration) *gin.Engine {
 	r := gin.Default()
 
 	// 添加追蹤中間件
 	r.Use(middleware.TraceMiddleware())
 
-	// Init JWT Manager
-	jwtSecret := config.Config.JWTSecret
-	if jwtSecret == "" {
-		jwtSecret = "default-secret-key-change-in-production-min-32-chars"
-	}
-	jwtManager := auth.NewJWTManager(jwtSecret, 24*time.Hour)
-
 	// Init repository
 	userRepo := repositories.NewUserRepository()
 	walletRepo := repositories.NewWalletRepository()
@@ -34,36 +27,134 @@ func SetupRouter(producer *kafka_client.KafkaProducer) *gin.Engine {
 
 	// Init service
 	userService := services.NewUserService(userRepo, walletRepo, currencyRepo)
-	walletService := services.NewWalletService(walletRepo)
-	txService := services.NewTransactionService(walletRepo, txRepo, producer)
+	authService := services.NewAuthService(jwtManager, userRepo)
+	adminService := services.NewAdminService(userRepo, walletRepo, txRepo, authService)
+	auditService := services.NewAuditService()
+	walletService := services.NewWalletService(walletRepo, currencyRepo)
+	txService := services.NewTransactionService(walletRepo, txRepo).WithPrecisionPolicy(precision)
 	currencyService := services.NewCurrencyService(currencyRepo)
+	fundingService := services.NewFundingService(walletRepo, txRepo).WithPrecisionPolicy(precision)
+	reconciliationService := services.NewReconciliationService(walletRepo, txRepo)
+	swapService := services.NewSwapService(walletRepo, txRepo, rateProvider, swapOptions)
+	feeService := services.NewFeeService().WithPrecisionPolicy(precision)
+	limitService := services.NewLimitService()
+	scheduledTransferService := services.NewScheduledTransferService(txService)
 
 	// Init handlers
-	userHandler := handlers.NewUserHandler(userService, jwtManager)
+	userHandler := handlers.NewUserHandler(userService, authService)
 	walletHandler := handlers.NewWalletHandler(walletService)
 	txHandler := handlers.NewTransactionHandler(txService)
 	currencyHandler := handlers.NewCurrencyHandler(currencyService)
+	fundingHandler := handlers.NewFundingHandler(fundingService)
+	swapHandler := handlers.NewSwapHandler(swapService)
+	scheduledTransferHandler := handlers.NewScheduledTransferHandler(scheduledTransferService)
+	reconciliationHandler := handlers.NewReconciliationHandler(reconciliationService)
+	auditChainHandler := handlers.NewAuditChainHandler(services.NewAuditChainService(walletRepo))
+	notificationHandler := handlers.NewNotificationHandler(services.NewNotificationService(repositories.NewNotificationRepository()))
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	streamHandler := handlers.NewStreamHandler(services.TransactionStream, streamHeartbeat)
+	adminHandler := handlers.NewAdminHandler(adminService, currencyService, fundingService, auditService, feeService, limitService)
 
 	// Health check routes
 	healthHandler := handlers.NewHealthHandler()
 	r.GET("/health", healthHandler.HealthCheck)
 	r.GET("/ready", healthHandler.ReadinessCheck)
 
+	// Public keys for verifying access tokens
+	jwksHandler := handlers.NewJWKSHandler(jwtManager)
+	r.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
 	// Public routes
 	r.POST("/users", userHandler.CreateUser)
 	r.POST("/auth/login", userHandler.Login)
+	r.POST("/auth/refresh", userHandler.Refresh)
 	r.GET("/currencies", currencyHandler.GetCurrencies)
 	r.GET("/currencies/:id", currencyHandler.GetCurrency)
 	r.GET("/tx/:hash", txHandler.GetTxByHash)
+	r.GET("/tx/:hash/verify", txHandler.VerifyTx)
 
 	// Protected routes - require authentication
-	authMiddleware := middleware.AuthMiddleware(jwtManager)
+	authMiddleware := middleware.AuthMiddleware(jwtManager, authService)
 	protected := r.Group("/")
 	protected.Use(authMiddleware)
 	{
+		protected.POST("/auth/logout", userHandler.Logout)
 		protected.GET("/wallet/:user_id", walletHandler.GetWallet)
+		protected.GET("/wallets", walletHandler.GetWallets)
+		protected.POST("/wallets", walletHandler.OpenWallet)
 		protected.POST("/wallet/transfer", txHandler.Transfer)
+		protected.POST("/wallet/transfer/quote", txHandler.QuoteTransfer)
+		protected.POST("/wallet/transfers/batch", txHandler.TransferBatch)
+		protected.POST("/wallet/transactions/:hash/refund", txHandler.Refund)
 		protected.GET("/transactions/:user_id", txHandler.GetTransactions)
+		protected.POST("/wallet/deposits", fundingHandler.CreateDeposit)
+		protected.POST("/wallet/deposits/:hash/cancel", fundingHandler.CancelDeposit)
+		protected.POST("/wallet/withdrawals", fundingHandler.CreateWithdrawal)
+		protected.POST("/wallet/withdrawals/:hash/cancel", fundingHandler.CancelWithdrawal)
+		protected.POST("/wallet/swaps/quote", swapHandler.Quote)
+		protected.POST("/wallet/swaps", swapHandler.Swap)
+		protected.POST("/wallet/scheduled-transfers", scheduledTransferHandler.Create)
+		protected.GET("/wallet/scheduled-transfers", scheduledTransferHandler.List)
+		protected.GET("/wallet/scheduled-transfers/:id", scheduledTransferHandler.Get)
+		protected.PATCH("/wallet/scheduled-transfers/:id", scheduledTransferHandler.Update)
+		protected.DELETE("/wallet/scheduled-transfers/:id", scheduledTransferHandler.Cancel)
+		protected.GET("/wallet/scheduled-transfers/:id/runs", scheduledTransferHandler.GetRuns)
+		protected.GET("/notifications", notificationHandler.List)
+		protected.POST("/notifications/read-all", notificationHandler.MarkAllRead)
+		protected.POST("/notifications/:id/read", notificationHandler.MarkRead)
+		protected.POST("/webhooks", webhookHandler.Create)
+		protected.GET("/webhooks", webhookHandler.List)
+		protected.GET("/webhooks/:id", webhookHandler.Get)
+		protected.PATCH("/webhooks/:id", webhookHandler.Update)
+		protected.DELETE("/webhooks/:id", webhookHandler.Delete)
+		protected.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+		protected.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+		protected.GET("/stream", streamHandler.Stream)
+	}
+
+	// Admin routes - staff only, every request is audited (including denied ones)
+	admin := r.Group("/admin")
+	admin.Use(authMiddleware, middleware.AuditAdminActions(auditService), middleware.RequireRole(models.RoleSupport, models.RoleAdmin))
+	{
+		admin.GET("/users/:id", adminHandler.GetUser)
+		admin.GET("/users/:id/wallets", adminHandler.GetUserWallets)
+		admin.GET("/users/:id/transactions", adminHandler.GetUserTransactions)
+		admin.GET("/users/:id/limits", adminHandler.GetUserLimits)
+		admin.POST("/users/:id/freeze", adminHandler.FreezeUser)
+		admin.POST("/users/:id/unfreeze", adminHandler.UnfreezeUser)
+		admin.POST("/transactions/:hash/reverse", txHandler.Reverse)
+	}
+
+	// Admin-only routes
+	adminOnly := admin.Group("")
+	adminOnly.Use(middleware.RequireRole(models.RoleAdmin))
+	{
+		adminOnly.PUT("/users/:id/role", adminHandler.UpdateUserRole)
+		adminOnly.PUT("/users/:id/limits/:currency_id", adminHandler.SetUserLimit)
+		adminOnly.DELETE("/users/:id/limits/:currency_id", adminHandler.DeleteUserLimit)
+		adminOnly.GET("/currencies", adminHandler.ListCurrencies)
+		adminOnly.POST("/currencies", adminHandler.CreateCurrency)
+		adminOnly.PATCH("/currencies/:id", adminHandler.UpdateCurrency)
+		adminOnly.POST("/currencies/:id/activate", adminHandler.ActivateCurrency)
+		adminOnly.POST("/currencies/:id/deactivate", adminHandler.DeactivateCurrency)
+		adminOnly.GET("/currencies/:id/fee-schedule", adminHandler.GetFeeSchedule)
+		adminOnly.PUT("/currencies/:id/fee-schedule", adminHandler.SetFeeSchedule)
+		adminOnly.DELETE("/currencies/:id/fee-schedule", adminHandler.DeleteFeeSchedule)
+		adminOnly.GET("/currencies/:id/limits", adminHandler.GetCurrencyLimit)
+		adminOnly.PUT("/currencies/:id/limits", adminHandler.SetCurrencyLimit)
+		adminOnly.DELETE("/currencies/:id/limits", adminHandler.DeleteCurrencyLimit)
+		adminOnly.POST("/funding/:hash/processing", adminHandler.MarkFundingProcessing)
+		adminOnly.POST("/funding/:hash/complete", adminHandler.CompleteFunding)
+		adminOnly.POST("/funding/:hash/fail", adminHandler.FailFunding)
+		adminOnly.GET("/audit-logs", adminHandler.GetAuditLogs)
+		adminOnly.POST("/transactions/migrate-hashes", txHandler.MigrateHashes)
+		adminOnly.POST("/reconciliation/runs", reconciliationHandler.RunReconciliation)
+		adminOnly.GET("/reconciliation/runs", reconciliationHandler.GetRuns)
+		adminOnly.GET("/reconciliation/runs/:id/findings", reconciliationHandler.GetFindings)
+		adminOnly.GET("/audit/chain/verify", auditChainHandler.Verify)
+		adminOnly.POST("/audit/checkpoints", auditChainHandler.CreateCheckpoint)
+		adminOnly.GET("/audit/checkpoints", auditChainHandler.GetCheckpoints)
+		adminOnly.GET("/audit/checkpoints/:id", auditChainHandler.GetCheckpoint)
 	}
 
 	return r
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"mini-crypto-wallet-api/internal/events"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"time"
+)
+
+var (
+	ErrInvalidRole      = errors.New("invalid role")
+	ErrCannotModifySelf = errors.New("staff cannot change their own role or freeze themselves")
+)
+
+// AdminService 提供客服與管理員的用戶查詢與帳戶管理
+type AdminService struct {
+	userRepo        repositories.IUser
+	walletRepo      repositories.IWallet
+	transactionRepo repositories.ITransaction
+	outboxRepo      repositories.IOutbox
+	authService     *AuthService
+	now             func() time.Time
+}
+
+func NewAdminService(userRepo repositories.IUser, walletRepo repositories.IWallet, txRepo repositories.ITransaction, authService *AuthService) *AdminService {
+	return &AdminService{
+		userRepo:        userRepo,
+		walletRepo:      walletRepo,
+		transactionRepo: txRepo,
+		outboxRepo:      repositories.NewOutboxRepository(),
+		authService:     authService,
+		now:             time.Now,
+	}
+}
+
+func (s *AdminService) GetUser(userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// GetUserWallets 取得任一用戶的所有錢包
+func (s *AdminService) GetUserWallets(userID uint) ([]models.Wallet, error) {
+	if _, err := s.GetUser(userID); err != nil {
+		return nil, err
+	}
+	return s.walletRepo.GetWalletsByUserID(userID)
+}
+
+// GetUserTransactions 分頁取得任一用戶的交易紀錄
+func (s *AdminService) GetUserTransactions(userID uint, offset, limit int) ([]models.Transaction, int64, error) {
+	if _, err := s.GetUser(userID); err != nil {
+		return nil, 0, err
+	}
+	return s.transactionRepo.GetTransactionsByUserIDWithPagination(userID, offset, limit)
+}
+
+// FreezeUser 凍結帳戶並撤銷其所有登入工作階段
+func (s *AdminService) FreezeUser(actorID, userID uint, reason string) (*models.User, error) {
+	if actorID == userID {
+		return nil, ErrCannotModifySelf
+	}
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsFrozen() {
+		now := s.now()
+		user.FrozenAt = &now
+	}
+	user.FrozenReason = reason
+	if err := updateUserWithEvent(s.userRepo, s.outboxRepo, user, events.TypeUserFrozen); err != nil {
+		return nil, err
+	}
+
+	if err := s.authService.RevokeUserSessions(user.ID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UnfreezeUser 解除帳戶凍結
+func (s *AdminService) UnfreezeUser(userID uint) (*models.User, error) {
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.FrozenAt = nil
+	user.FrozenReason = ""
+	if err := updateUserWithEvent(s.userRepo, s.outboxRepo, user, events.TypeUserUnfrozen); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateRole 變更用戶角色，並撤銷其工作階段讓新角色立即生效
+func (s *AdminService) UpdateRole(actorID, userID uint, role string) (*models.User, error) {
+	if !models.IsValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if actorID == userID {
+		return nil, ErrCannotModifySelf
+	}
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return user, nil
+	}
+
+	user.Role = role
+	if err := updateUserWithEvent(s.userRepo, s.outboxRepo, user, events.TypeUserRoleChanged); err != nil {
+		return nil, err
+	}
+
+	if err := s.authService.RevokeUserSessions(user.ID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
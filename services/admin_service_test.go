@@ -0,0 +1,90 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAdminService(authService *AuthService) *AdminService {
+	return NewAdminService(repositories.NewUserRepository(), repositories.NewWalletRepository(), repositories.NewTransactionRepository(), authService)
+}
+
+// TestFreezeUser_BlocksOutflowsAndSessions verifies a frozen account cannot send funds or refresh tokens
+func TestFreezeUser_BlocksOutflowsAndSessions(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	staff := test.CreateTestUser(db, "staff")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 1000)
+	assert.Equal(t, models.RoleUser, alice.Role)
+
+	authService := newTestAuthService()
+	pair, err := authService.IssueTokens(alice)
+	assert.NoError(t, err)
+
+	admin := newTestAdminService(authService)
+	frozen, err := admin.FreezeUser(staff.ID, alice.ID, "suspicious activity")
+	assert.NoError(t, err)
+	assert.True(t, frozen.IsFrozen())
+
+	// 原本的工作階段被撤銷
+	revoked, err := authService.IsTokenRevoked(pair.Access.JTI)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	_, _, err = authService.Refresh(pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	assert.ErrorIs(t, txService.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(10)), ErrAccountFrozen)
+
+	// 凍結帳戶仍可收款
+	assert.NoError(t, txService.Transfer(bob.ID, alice.ID, currency.ID, decimal.NewFromInt(10)))
+
+	funding := NewFundingService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	_, err = funding.CreateWithdrawal(alice.ID, currency.ID, decimal.NewFromInt(10), "addr")
+	assert.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = admin.UnfreezeUser(alice.ID)
+	assert.NoError(t, err)
+	assert.NoError(t, txService.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(10)))
+}
+
+// TestUpdateRole_RevokesSessions verifies role changes take effect on the next login
+func TestUpdateRole_RevokesSessions(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	admin := test.CreateTestUser(db, "admin")
+	alice := test.CreateTestUser(db, "alice")
+
+	authService := newTestAuthService()
+	pair, err := authService.IssueTokens(alice)
+	assert.NoError(t, err)
+
+	service := newTestAdminService(authService)
+	updated, err := service.UpdateRole(admin.ID, alice.ID, models.RoleSupport)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleSupport, updated.Role)
+
+	revoked, err := authService.IsTokenRevoked(pair.Access.JTI)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	_, err = service.UpdateRole(admin.ID, alice.ID, "superuser")
+	assert.ErrorIs(t, err, ErrInvalidRole)
+
+	_, err = service.UpdateRole(admin.ID, admin.ID, models.RoleUser)
+	assert.ErrorIs(t, err, ErrCannotModifySelf)
+
+	_, err = service.FreezeUser(admin.ID, 9999, "missing")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
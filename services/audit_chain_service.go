@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mini-crypto-wallet-api/internal/merkle"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const defaultAuditChainBatchSize = 200
+
+var ErrAuditCheckpointNotFound = errors.New("audit checkpoint not found")
+
+// AuditChainService 驗證每個錢包餘額歷史的 hash chain，並定期以 Merkle root 建立 checkpoint
+// chain 能發現被修改或刪除的中間紀錄；checkpoint 記錄當時每條 chain 的最後一筆，能發現被截斷的尾端
+type AuditChainService struct {
+	walletRepo         repositories.IWallet
+	balanceHistoryRepo repositories.IBalanceHistory
+	checkpointRepo     repositories.IAuditCheckpoint
+	batchSize          int
+}
+
+func NewAuditChainService(walletRepo repositories.IWallet) *AuditChainService {
+	return &AuditChainService{
+		walletRepo:         walletRepo,
+		balanceHistoryRepo: repositories.NewBalanceHistoryRepository(),
+		checkpointRepo:     repositories.NewAuditCheckpointRepository(),
+		batchSize:          defaultAuditChainBatchSize,
+	}
+}
+
+// RunScheduler 每隔 interval 建立一次 checkpoint，直到 ctx 被取消
+func (s *AuditChainService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := s.CreateCheckpoint(); err != nil {
+			log.Println("⚠️ Audit checkpoint error:", err)
+		}
+	}
+}
+
+// CreateCheckpoint 以所有錢包 chain 的最後一筆（依 wallet_id 排序）計算 Merkle root 並保存
+func (s *AuditChainService) CreateCheckpoint() (*models.AuditCheckpoint, error) {
+	heads, err := s.balanceHistoryRepo.FindChainHeads()
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint := &models.AuditCheckpoint{WalletCount: len(heads)}
+	leaves := make([]models.AuditCheckpointLeaf, len(heads))
+	for i, head := range heads {
+		leaves[i] = models.AuditCheckpointLeaf{
+			Position:  i,
+			WalletID:  head.WalletID,
+			Sequence:  head.Sequence,
+			EntryHash: head.EntryHash,
+		}
+		checkpoint.EntryCount += head.Sequence
+	}
+	checkpoint.MerkleRoot = merkleRoot(leaves)
+
+	if err := s.checkpointRepo.CreateCheckpoint(checkpoint, leaves); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// GetCheckpoints 取得最近的 checkpoint
+func (s *AuditChainService) GetCheckpoints(limit int) ([]models.AuditCheckpoint, error) {
+	return s.checkpointRepo.GetCheckpoints(limit)
+}
+
+// GetCheckpoint 取得 checkpoint 與它的葉節點，供匯出給外部稽核
+func (s *AuditChainService) GetCheckpoint(id uint) (*models.AuditCheckpoint, []models.AuditCheckpointLeaf, error) {
+	checkpoint, err := s.checkpointRepo.GetCheckpointByID(id)
+	if err != nil {
+		return nil, nil, ErrAuditCheckpointNotFound
+	}
+	leaves, err := s.checkpointRepo.GetLeavesByCheckpointID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return checkpoint, leaves, nil
+}
+
+// Verify 從頭驗證錢包的 hash chain 並與最新的 checkpoint 比對，每個錢包回報第一個斷點
+// walletID 為 0 時驗證所有錢包
+func (s *AuditChainService) Verify(walletID uint) (*models.AuditChainReport, error) {
+	report := &models.AuditChainReport{Breaks: []models.AuditChainBreak{}}
+
+	checkpointed := map[uint]models.AuditCheckpointLeaf{}
+	checkpoint, err := s.checkpointRepo.GetLatestCheckpoint()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if checkpoint != nil {
+		report.CheckpointID = &checkpoint.ID
+		leaves, err := s.checkpointRepo.GetLeavesByCheckpointID(checkpoint.ID)
+		if err != nil {
+			return nil, err
+		}
+		if merkleRoot(leaves) != checkpoint.MerkleRoot {
+			report.Breaks = append(report.Breaks, models.AuditChainBreak{
+				Reason: fmt.Sprintf("checkpoint %d leaves do not match its Merkle root", checkpoint.ID),
+			})
+		}
+		for _, leaf := range leaves {
+			checkpointed[leaf.WalletID] = leaf
+		}
+	}
+
+	verify := func(id uint) error {
+		chain, err := s.balanceHistoryRepo.FindChainByWalletID(id)
+		if err != nil {
+			return err
+		}
+		report.WalletsChecked++
+		report.EntriesChecked += len(chain)
+
+		var leaf *models.AuditCheckpointLeaf
+		if l, ok := checkpointed[id]; ok {
+			leaf = &l
+		}
+		if b := firstChainBreak(id, chain, leaf); b != nil {
+			report.Breaks = append(report.Breaks, *b)
+		}
+		return nil
+	}
+
+	if walletID != 0 {
+		err = verify(walletID)
+	} else {
+		err = s.walletRepo.FindWalletsInBatches(s.batchSize, func(wallets []models.Wallet) error {
+			for _, wallet := range wallets {
+				if err := verify(wallet.ID); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report.Intact = len(report.Breaks) == 0
+	return report, nil
+}
+
+// firstChainBreak 依序檢查 sequence 連續、prev_hash 指向前一筆、entry_hash 與內容一致，
+// 並確認 checkpoint 記錄的最後一筆仍在 chain 上；回傳最早的斷點，chain 完整時回傳 nil
+func firstChainBreak(walletID uint, chain []models.BalanceHistory, leaf *models.AuditCheckpointLeaf) *models.AuditChainBreak {
+	prevHash := ""
+	for i := range chain {
+		entry := &chain[i]
+		broken := func(reason string) *models.AuditChainBreak {
+			return &models.AuditChainBreak{WalletID: walletID, Sequence: entry.Sequence, HistoryID: entry.ID, Reason: reason}
+		}
+
+		if expected := uint64(i + 1); entry.Sequence != expected {
+			return &models.AuditChainBreak{
+				WalletID: walletID,
+				Sequence: expected,
+				Reason:   fmt.Sprintf("entry %d is missing (found sequence %d)", expected, entry.Sequence),
+			}
+		}
+		if entry.PrevHash != prevHash {
+			return broken("prev_hash does not match the previous entry")
+		}
+		if entry.ComputeEntryHash() != entry.EntryHash {
+			return broken("entry hash does not match its contents")
+		}
+		if leaf != nil && leaf.Sequence == entry.Sequence && leaf.EntryHash != entry.EntryHash {
+			return broken("entry differs from the one recorded in the checkpoint")
+		}
+		prevHash = entry.EntryHash
+	}
+
+	if leaf != nil && uint64(len(chain)) < leaf.Sequence {
+		return &models.AuditChainBreak{
+			WalletID: walletID,
+			Sequence: uint64(len(chain)) + 1,
+			Reason:   fmt.Sprintf("chain ends at %d but the checkpoint recorded %d entries", len(chain), leaf.Sequence),
+		}
+	}
+	return nil
+}
+
+// merkleRoot 依葉節點順序計算 hex 編碼的 Merkle root
+func merkleRoot(leaves []models.AuditCheckpointLeaf) string {
+	data := make([][]byte, len(leaves))
+	for i := range leaves {
+		data[i] = leaves[i].LeafData()
+	}
+	return hex.EncodeToString(merkle.Root(data))
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// historyEntry loads the wallet's balance history entry with the given sequence
+func historyEntry(t *testing.T, db *gorm.DB, walletID uint, sequence uint64) *models.BalanceHistory {
+	t.Helper()
+	var entry models.BalanceHistory
+	assert.NoError(t, db.Where("wallet_id = ? AND sequence = ?", walletID, sequence).First(&entry).Error)
+	return &entry
+}
+
+// TestAuditChain_IntactChainsAndCheckpoint verifies entries link up and a checkpoint covers every head
+func TestAuditChain_IntactChainsAndCheckpoint(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	// alice 轉帳三次給 bob，兩個錢包各有三筆串成 chain 的餘額歷史
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	aliceWallet := test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	bobWallet := test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	for _, amount := range []string{"10", "20", "30"} {
+		_, err := txService.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec(amount))
+		assert.NoError(t, err)
+	}
+	service := NewAuditChainService(repositories.NewWalletRepository())
+
+	first, second := historyEntry(t, db, bobWallet.ID, 1), historyEntry(t, db, bobWallet.ID, 2)
+	assert.Empty(t, first.PrevHash)
+	assert.Equal(t, first.EntryHash, second.PrevHash)
+
+	report, err := service.Verify(0)
+	assert.NoError(t, err)
+	assert.True(t, report.Intact)
+	assert.Equal(t, 2, report.WalletsChecked)
+	assert.Equal(t, 6, report.EntriesChecked)
+	assert.Nil(t, report.CheckpointID)
+
+	checkpoint, err := service.CreateCheckpoint()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, checkpoint.WalletCount)
+	assert.Equal(t, uint64(6), checkpoint.EntryCount)
+	assert.Len(t, checkpoint.MerkleRoot, 64)
+
+	_, leaves, err := service.GetCheckpoint(checkpoint.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, leaves, 2) {
+		assert.Equal(t, aliceWallet.ID, leaves[0].WalletID)
+		assert.Equal(t, historyEntry(t, db, bobWallet.ID, 3).EntryHash, leaves[1].EntryHash)
+	}
+
+	report, err = service.Verify(bobWallet.ID)
+	assert.NoError(t, err)
+	assert.True(t, report.Intact)
+	assert.Equal(t, &checkpoint.ID, report.CheckpointID)
+
+	_, _, err = service.GetCheckpoint(999)
+	assert.ErrorIs(t, err, ErrAuditCheckpointNotFound)
+}
+
+// TestAuditChain_SequenceIsUniquePerWallet verifies two entries can never claim the same position in a chain,
+// while entries written before the chain (sequence 0) are not constrained
+func TestAuditChain_SequenceIsUniquePerWallet(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	// alice 轉帳三次給 bob，兩個錢包各有三筆串成 chain 的餘額歷史
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	bobWallet := test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	for _, amount := range []string{"10", "20", "30"} {
+		_, err := txService.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec(amount))
+		assert.NoError(t, err)
+	}
+
+	forked := *historyEntry(t, db, bobWallet.ID, 2)
+	forked.ID = 0
+	assert.Error(t, db.Create(&forked).Error)
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, db.Create(&models.BalanceHistory{WalletID: bobWallet.ID, UserID: bobWallet.UserID}).Error)
+	}
+}
+
+// TestAuditChain_PointsToFirstBrokenLink verifies edited and deleted entries are located
+func TestAuditChain_PointsToFirstBrokenLink(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	// alice 轉帳三次給 bob，兩個錢包各有三筆串成 chain 的餘額歷史
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	bobWallet := test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	for _, amount := range []string{"10", "20", "30"} {
+		_, err := txService.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec(amount))
+		assert.NoError(t, err)
+	}
+	service := NewAuditChainService(repositories.NewWalletRepository())
+
+	edited := historyEntry(t, db, bobWallet.ID, 2)
+	assert.NoError(t, db.Model(edited).Update("amount", dec("2000")).Error)
+
+	report, err := service.Verify(0)
+	assert.NoError(t, err)
+	assert.False(t, report.Intact)
+	if assert.Len(t, report.Breaks, 1) {
+		assert.Equal(t, models.AuditChainBreak{
+			WalletID:  bobWallet.ID,
+			Sequence:  2,
+			HistoryID: edited.ID,
+			Reason:    "entry hash does not match its contents",
+		}, report.Breaks[0])
+	}
+
+	// 重新計算被修改紀錄的 hash 也會讓下一筆的 prev_hash 對不上
+	edited.Amount = dec("2000")
+	assert.NoError(t, db.Model(edited).Update("entry_hash", edited.ComputeEntryHash()).Error)
+	report, err = service.Verify(bobWallet.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, report.Breaks, 1) {
+		assert.Equal(t, uint64(3), report.Breaks[0].Sequence)
+		assert.Equal(t, "prev_hash does not match the previous entry", report.Breaks[0].Reason)
+	}
+
+	assert.NoError(t, db.Delete(historyEntry(t, db, bobWallet.ID, 1)).Error)
+	report, err = service.Verify(bobWallet.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, report.Breaks, 1) {
+		assert.Equal(t, uint64(1), report.Breaks[0].Sequence)
+		assert.Zero(t, report.Breaks[0].HistoryID)
+	}
+}
+
+// TestAuditChain_CheckpointDetectsTruncation verifies a deleted tail is only caught by the checkpoint
+func TestAuditChain_CheckpointDetectsTruncation(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	// alice 轉帳三次給 bob，兩個錢包各有三筆串成 chain 的餘額歷史
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	bobWallet := test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	for _, amount := range []string{"10", "20", "30"} {
+		_, err := txService.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec(amount))
+		assert.NoError(t, err)
+	}
+	service := NewAuditChainService(repositories.NewWalletRepository())
+
+	checkpoint, err := service.CreateCheckpoint()
+	assert.NoError(t, err)
+
+	assert.NoError(t, db.Delete(historyEntry(t, db, bobWallet.ID, 3)).Error)
+	report, err := service.Verify(0)
+	assert.NoError(t, err)
+	assert.False(t, report.Intact)
+	if assert.Len(t, report.Breaks, 1) {
+		assert.Equal(t, bobWallet.ID, report.Breaks[0].WalletID)
+		assert.Equal(t, uint64(3), report.Breaks[0].Sequence)
+		assert.Equal(t, "chain ends at 2 but the checkpoint recorded 3 entries", report.Breaks[0].Reason)
+	}
+
+	// 竄改 checkpoint 本身會讓 Merkle root 對不上
+	assert.NoError(t, db.Model(&models.AuditCheckpointLeaf{}).Where("checkpoint_id = ? AND wallet_id = ?", checkpoint.ID, bobWallet.ID).Update("sequence", 2).Error)
+	report, err = service.Verify(0)
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, report.Breaks) {
+		assert.Zero(t, report.Breaks[0].WalletID)
+		assert.Contains(t, report.Breaks[0].Reason, "Merkle root")
+	}
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+)
+
+// AuditService 保存與查詢管理操作的稽核紀錄
+type AuditService struct {
+	auditRepo repositories.IAdminAudit
+}
+
+func NewAuditService() *AuditService {
+	return &AuditService{
+		auditRepo: repositories.NewAdminAuditRepository(),
+	}
+}
+
+// RecordAdminAction 實作 middleware.AuditRecorder
+func (s *AuditService) RecordAdminAction(entry *models.AdminAuditLog) error {
+	return s.auditRepo.CreateLog(entry)
+}
+
+// GetLogs 分頁取得稽核紀錄，actorID 為 0 時回傳所有操作者
+func (s *AuditService) GetLogs(actorID uint, offset, limit int) ([]models.AdminAuditLog, int64, error) {
+	return s.auditRepo.GetLogs(actorID, offset, limit)
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/internal/auth"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"mini-crypto-wallet-api/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+)
+
+// TokenPair 登入或刷新後發給用戶端的 access token 與 refresh token
+type TokenPair struct {
+	Access           *auth.IssuedToken
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// AuthService 管理 access token 與可輪替的 refresh token
+// refresh token 只保存 SHA256 雜湊；已輪替的 token 被再次使用時，整個 token family 會被撤銷
+type AuthService struct {
+	jwtManager      *auth.JWTManager
+	userRepo        repositories.IUser
+	tokenRepo       repositories.IAuthToken
+	refreshDuration time.Duration
+	now             func() time.Time
+}
+
+func NewAuthService(jwtManager *auth.JWTManager, userRepo repositories.IUser) *AuthService {
+	return &AuthService{
+		jwtManager:      jwtManager,
+		userRepo:        userRepo,
+		tokenRepo:       repositories.NewAuthTokenRepository(),
+		refreshDuration: auth.DefaultRefreshTokenDuration,
+		now:             time.Now,
+	}
+}
+
+// IssueTokens 登入成功後簽發新的 token family
+func (s *AuthService) IssueTokens(user *models.User) (*TokenPair, error) {
+	familyID, err := auth.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	pair, err := s.issueInTx(tx, user, familyID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return nil, commitDB.Error
+	}
+
+	return pair, nil
+}
+
+// Refresh 以 refresh token 兌換新的 token pair，舊的 refresh token 立即失效
+func (s *AuthService) Refresh(rawToken string) (*TokenPair, *models.User, error) {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	current, err := s.tokenRepo.FindRefreshTokenByHashWithTx(hashRefreshToken(rawToken), tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	now := s.now()
+
+	// 已輪替的 token 再次出現，代表 token 可能外洩：撤銷整個 family 並提交
+	if current.RotatedAt != nil {
+		if err := s.revokeFamily(tx, current.FamilyID, now); err != nil {
+			tx.Rollback()
+			return nil, nil, err
+		}
+		if commitDB := tx.Commit(); commitDB.Error != nil {
+			return nil, nil, commitDB.Error
+		}
+		return nil, nil, ErrRefreshTokenReused
+	}
+
+	if !current.IsUsable(now) {
+		tx.Rollback()
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.GetUserByID(current.UserID)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if user.IsFrozen() {
+		tx.Rollback()
+		return nil, nil, ErrAccountFrozen
+	}
+
+	current.RotatedAt = &now
+	if err := s.tokenRepo.UpdateRefreshToken(current, tx); err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	pair, err := s.issueInTx(tx, user, current.FamilyID)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return nil, nil, commitDB.Error
+	}
+
+	return pair, user, nil
+}
+
+// Logout 撤銷目前的 access token；帶上 refresh token 時一併撤銷其 token family
+// 不屬於該用戶或不存在的 refresh token 會被忽略，讓登出可以重複呼叫
+func (s *AuthService) Logout(userID uint, jti string, accessExpiresAt time.Time, rawRefreshToken string) error {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	now := s.now()
+
+	if jti != "" {
+		if err := s.tokenRepo.CreateRevokedToken(&models.RevokedToken{JTI: jti, UserID: userID, ExpiresAt: accessExpiresAt}, tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if rawRefreshToken != "" {
+		token, err := s.tokenRepo.FindRefreshTokenByHashWithTx(hashRefreshToken(rawRefreshToken), tx)
+		if err == nil && token.UserID == userID {
+			if err := s.revokeFamily(tx, token.FamilyID, now); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return commitDB.Error
+	}
+	return nil
+}
+
+// RevokeUserSessions 撤銷用戶所有登入工作階段（凍結帳戶或變更角色後，舊 token 不再可用）
+func (s *AuthService) RevokeUserSessions(userID uint) error {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	now := s.now()
+	families, err := s.tokenRepo.GetActiveFamilies(userID, now, tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, familyID := range families {
+		if err := s.revokeFamily(tx, familyID, now); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return commitDB.Error
+	}
+	return nil
+}
+
+// IsTokenRevoked 查詢 access token 的 jti 是否在黑名單中
+func (s *AuthService) IsTokenRevoked(jti string) (bool, error) {
+	return s.tokenRepo.IsTokenRevoked(jti)
+}
+
+// PurgeExpiredTokens 定期清除過期的 refresh token 與黑名單紀錄，直到 ctx 被取消
+func PurgeExpiredTokens(ctx context.Context, tokenRepo repositories.IAuthToken, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := tokenRepo.DeleteExpired(time.Now()); err != nil {
+			log.Println("⚠️ Token cleanup error:", err)
+		}
+	}
+}
+
+func (s *AuthService) issueInTx(tx *gorm.DB, user *models.User, familyID string) (*TokenPair, error) {
+	access, err := s.jwtManager.IssueToken(user.ID, user.Username, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	rawToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := s.now().Add(s.refreshDuration)
+	if err := s.tokenRepo.CreateRefreshToken(&models.RefreshToken{
+		UserID:          user.ID,
+		FamilyID:        familyID,
+		TokenHash:       hashRefreshToken(rawToken),
+		AccessJTI:       access.JTI,
+		AccessExpiresAt: access.ExpiresAt,
+		ExpiresAt:       expiresAt,
+	}, tx); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{Access: access, RefreshToken: rawToken, RefreshExpiresAt: expiresAt}, nil
+}
+
+// revokeFamily 撤銷 family 中所有 refresh token，並將仍有效的 access token 加入黑名單
+func (s *AuthService) revokeFamily(tx *gorm.DB, familyID string, now time.Time) error {
+	tokens, err := s.tokenRepo.GetRefreshTokensByFamily(familyID, tx)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if token.AccessJTI == "" || !token.AccessExpiresAt.After(now) {
+			continue
+		}
+		if err := s.tokenRepo.CreateRevokedToken(&models.RevokedToken{
+			JTI:       token.AccessJTI,
+			UserID:    token.UserID,
+			ExpiresAt: token.AccessExpiresAt,
+		}, tx); err != nil {
+			return err
+		}
+	}
+
+	return s.tokenRepo.RevokeFamily(familyID, now, tx)
+}
+
+// newRefreshToken 產生 256-bit 隨機 refresh token
+func newRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashRefreshToken(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/internal/auth"
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAuthService() *AuthService {
+	jwtManager := auth.NewJWTManager("test-secret-key-for-unit-tests-only-32", auth.DefaultAccessTokenDuration)
+	return NewAuthService(jwtManager, repositories.NewUserRepository())
+}
+
+// TestRefresh_RotatesToken verifies a refresh token can be exchanged exactly once
+func TestRefresh_RotatesToken(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	user := test.CreateTestUser(db, "alice")
+	service := newTestAuthService()
+
+	first, err := service.IssueTokens(user)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, first.Access.JTI)
+
+	second, refreshedUser, err := service.Refresh(first.RefreshToken)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, refreshedUser.ID)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	assert.NotEqual(t, first.Access.JTI, second.Access.JTI)
+
+	// 只保存雜湊，不保存原始 token
+	var stored []models.RefreshToken
+	db.Order("id asc").Find(&stored)
+	assert.Len(t, stored, 2)
+	assert.Equal(t, stored[0].FamilyID, stored[1].FamilyID)
+	assert.NotEqual(t, first.RefreshToken, stored[0].TokenHash)
+	assert.NotNil(t, stored[0].RotatedAt)
+
+	_, _, err = service.Refresh("unknown-token")
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+// TestRefresh_ReuseRevokesFamily verifies replaying a rotated token revokes every token of the session
+func TestRefresh_ReuseRevokesFamily(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	user := test.CreateTestUser(db, "alice")
+	service := newTestAuthService()
+
+	first, err := service.IssueTokens(user)
+	assert.NoError(t, err)
+	second, _, err := service.Refresh(first.RefreshToken)
+	assert.NoError(t, err)
+
+	// 一個無關的登入不受影響
+	other, err := service.IssueTokens(user)
+	assert.NoError(t, err)
+
+	_, _, err = service.Refresh(first.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	_, _, err = service.Refresh(second.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	revoked, err := service.IsTokenRevoked(second.Access.JTI)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = service.IsTokenRevoked(other.Access.JTI)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	_, _, err = service.Refresh(other.RefreshToken)
+	assert.NoError(t, err)
+}
+
+// TestLogout_RevokesAccessAndRefreshTokens verifies logout denylists the jti and ends the session
+func TestLogout_RevokesAccessAndRefreshTokens(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	service := newTestAuthService()
+
+	pair, err := service.IssueTokens(alice)
+	assert.NoError(t, err)
+	bobPair, err := service.IssueTokens(bob)
+	assert.NoError(t, err)
+
+	// 不能撤銷別人的 refresh token
+	assert.NoError(t, service.Logout(alice.ID, pair.Access.JTI, pair.Access.ExpiresAt, bobPair.RefreshToken))
+	_, _, err = service.Refresh(bobPair.RefreshToken)
+	assert.NoError(t, err)
+
+	assert.NoError(t, service.Logout(alice.ID, pair.Access.JTI, pair.Access.ExpiresAt, pair.RefreshToken))
+
+	revoked, err := service.IsTokenRevoked(pair.Access.JTI)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	_, _, err = service.Refresh(pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	// 過期紀錄會被清除
+	tokenRepo := repositories.NewAuthTokenRepository()
+	assert.NoError(t, tokenRepo.DeleteExpired(time.Now().Add(auth.DefaultRefreshTokenDuration+time.Minute)))
+	revoked, err = service.IsTokenRevoked(pair.Access.JTI)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/utils"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// BatchTransferResult 批次轉帳中單一項目的結果
+// Transaction 只有在項目成功且批次已 commit 時才有值
+type BatchTransferResult struct {
+	Index       int
+	Item        models.BatchTransferItem
+	Status      string
+	Transaction *models.Transaction
+	Err         error
+}
+
+// TransferBatch 在同一個 DB 交易中由 fromID 轉帳給多位收款人
+// 轉出錢包與所有收款錢包依 user_id 順序只鎖定一次；每個項目仍各自寫入 Transaction 與 BalanceHistory
+// 每個項目在自己的 savepoint 中執行，失敗時只回復該項目：
+// best_effort 模式 commit 其餘成功的項目，all_or_nothing 模式在任一項目失敗時整批回復
+// 回傳的 error 只用於整批無法執行的情況（幣種、轉出錢包、凍結帳戶或資料庫錯誤）
+func (s *TransactionService) TransferBatch(fromID, currencyID uint, mode string, items []models.BatchTransferItem) ([]BatchTransferResult, bool, error) {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	results, err := s.transferBatchInTx(tx, fromID, currencyID, items)
+	if err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	if mode == models.BatchModeAllOrNothing && failed > 0 {
+		tx.Rollback()
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Status = models.BatchItemRolledBack
+				results[i].Transaction = nil
+			}
+		}
+		return results, false, nil
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return nil, false, commitDB.Error
+	}
+	for _, result := range results {
+		if result.Transaction != nil {
+			streamCommitted(s.walletRepo, s.balanceHistoryRepo, result.Transaction)
+		}
+	}
+	return results, true, nil
+}
+
+func (s *TransactionService) transferBatchInTx(tx *gorm.DB, fromID, currencyID uint, items []models.BatchTransferItem) ([]BatchTransferResult, error) {
+	currency, err := activeCurrency(s.currencyRepo, currencyID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 依 user_id 由小到大鎖定轉出方與所有收款方，與單筆轉帳的加鎖順序一致，避免死鎖
+	userIDs := []uint{fromID}
+	seen := map[uint]bool{fromID: true}
+	for _, item := range items {
+		if !seen[item.ToUserID] {
+			seen[item.ToUserID] = true
+			userIDs = append(userIDs, item.ToUserID)
+		}
+	}
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+
+	wallets := make(map[uint]*models.Wallet, len(userIDs))
+	for _, userID := range userIDs {
+		wallet, err := s.walletRepo.GetWalletByUserIDAndCurrencyWithTx(userID, currencyID, tx)
+		if err != nil {
+			if userID == fromID {
+				return nil, errors.New("from_user wallet not found for this currency")
+			}
+			continue // 收款方沒有錢包只影響該項目
+		}
+		wallets[userID] = wallet
+	}
+	fromWallet, ok := wallets[fromID]
+	if !ok {
+		return nil, errors.New("from_user wallet not found for this currency")
+	}
+
+	if err := ensureNotFrozen(s.userRepo, fromID); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchTransferResult, len(items))
+	for i, item := range items {
+		results[i] = BatchTransferResult{Index: i, Item: item}
+		transaction, itemErr, err := s.transferBatchItem(tx, currency, fromWallet, wallets[item.ToUserID], i, item)
+		if err != nil {
+			return nil, err
+		}
+		if itemErr != nil {
+			results[i].Status = models.BatchItemFailed
+			results[i].Err = itemErr
+			continue
+		}
+		results[i].Status = models.BatchItemCompleted
+		results[i].Transaction = transaction
+	}
+	return results, nil
+}
+
+// transferBatchItem 在 savepoint 中執行單一項目，失敗時回復到 savepoint 並還原記憶體中的錢包餘額
+// itemErr 為該項目失敗的原因；err 代表 savepoint 本身出錯，整批必須中止
+func (s *TransactionService) transferBatchItem(tx *gorm.DB, currency *models.Currency, fromWallet, toWallet *models.Wallet, index int, item models.BatchTransferItem) (*models.Transaction, error, error) {
+	if err := validateTransfer(fromWallet.UserID, item.ToUserID, item.Amount); err != nil {
+		return nil, err, nil
+	}
+	if toWallet == nil {
+		return nil, errors.New("to_user wallet not found for this currency"), nil
+	}
+
+	savepoint := fmt.Sprintf("batch_item_%d", index)
+	if err := tx.SavePoint(savepoint).Error; err != nil {
+		return nil, nil, err
+	}
+
+	fromBalance, toBalance := fromWallet.Balance, toWallet.Balance
+	transaction, itemErr := s.transferLocked(tx, currency, fromWallet, toWallet, item.Amount)
+	if itemErr != nil {
+		fromWallet.Balance, toWallet.Balance = fromBalance, toBalance
+		if err := tx.RollbackTo(savepoint).Error; err != nil {
+			return nil, nil, err
+		}
+		return nil, itemErr, nil
+	}
+	return transaction, nil, nil
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTransferBatch_BestEffortCommitsSuccessfulItems verifies per-item results and records
+func TestTransferBatch_BestEffortCommitsSuccessfulItems(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	carol := test.CreateTestUser(db, "carol")
+	dave := test.CreateTestUser(db, "dave") // 沒有 USDT 錢包
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+	test.CreateTestWallet(db, carol.ID, usdt.ID, 0)
+
+	walletRepo := repositories.NewWalletRepository()
+	service := NewTransactionService(walletRepo, repositories.NewTransactionRepository())
+
+	items := []models.BatchTransferItem{
+		{ToUserID: bob.ID, Amount: dec("30")},
+		{ToUserID: dave.ID, Amount: dec("10")}, // 收款方沒有錢包
+		{ToUserID: carol.ID, Amount: dec("50")},
+		{ToUserID: bob.ID, Amount: dec("40")},  // 前兩筆成功後餘額只剩 20
+		{ToUserID: alice.ID, Amount: dec("1")}, // 轉給自己
+		{ToUserID: carol.ID, Amount: dec("20")},
+	}
+
+	results, committed, err := service.TransferBatch(alice.ID, usdt.ID, models.BatchModeBestEffort, items)
+	assert.NoError(t, err)
+	assert.True(t, committed)
+
+	statuses := make([]string, len(results))
+	for i, result := range results {
+		statuses[i] = result.Status
+	}
+	assert.Equal(t, []string{
+		models.BatchItemCompleted,
+		models.BatchItemFailed,
+		models.BatchItemCompleted,
+		models.BatchItemFailed,
+		models.BatchItemFailed,
+		models.BatchItemCompleted,
+	}, statuses)
+	assert.ErrorIs(t, results[3].Err, ErrInsufficientBalance)
+
+	aliceWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, usdt.ID)
+	assert.Equal(t, "0", aliceWallet.Balance.String())
+	bobWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(bob.ID, usdt.ID)
+	assert.Equal(t, "30", bobWallet.Balance.String())
+	carolWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(carol.ID, usdt.ID)
+	assert.Equal(t, "70", carolWallet.Balance.String())
+
+	// 每個成功的項目都有自己的交易與兩筆餘額歷史
+	historyRepo := repositories.NewBalanceHistoryRepository()
+	hashes := map[string]bool{}
+	for _, result := range results {
+		if result.Transaction == nil {
+			continue
+		}
+		hashes[result.Transaction.Hash] = true
+		histories, err := historyRepo.GetHistoryByTransactionID(result.Transaction.ID)
+		assert.NoError(t, err)
+		assert.Len(t, histories, 2)
+	}
+	assert.Len(t, hashes, 3)
+
+	run, err := newTestReconciliationService().Run(models.ReconciliationTriggerManual)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, run.FindingCount)
+}
+
+// TestTransferBatch_AllOrNothingRollsBackOnFailure verifies one failure undoes every item
+func TestTransferBatch_AllOrNothingRollsBackOnFailure(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	carol := test.CreateTestUser(db, "carol")
+	dave := test.CreateTestUser(db, "dave") // 沒有 USDT 錢包
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+	test.CreateTestWallet(db, carol.ID, usdt.ID, 0)
+
+	walletRepo := repositories.NewWalletRepository()
+	service := NewTransactionService(walletRepo, repositories.NewTransactionRepository())
+
+	items := []models.BatchTransferItem{
+		{ToUserID: bob.ID, Amount: dec("30")},
+		{ToUserID: dave.ID, Amount: dec("10")}, // 收款方沒有錢包
+		{ToUserID: carol.ID, Amount: dec("50")},
+		{ToUserID: bob.ID, Amount: dec("40")},  // 前兩筆成功後餘額只剩 20
+		{ToUserID: alice.ID, Amount: dec("1")}, // 轉給自己
+		{ToUserID: carol.ID, Amount: dec("20")},
+	}
+
+	results, committed, err := service.TransferBatch(alice.ID, usdt.ID, models.BatchModeAllOrNothing, items)
+	assert.NoError(t, err)
+	assert.False(t, committed)
+	assert.Equal(t, models.BatchItemRolledBack, results[0].Status)
+	assert.Nil(t, results[0].Transaction)
+	assert.Equal(t, models.BatchItemFailed, results[1].Status)
+
+	aliceWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, usdt.ID)
+	assert.Equal(t, "100", aliceWallet.Balance.String())
+	bobWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(bob.ID, usdt.ID)
+	assert.Equal(t, "0", bobWallet.Balance.String())
+
+	var count int64
+	db.Model(&models.Transaction{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	// 全部項目都能成功時整批 commit
+	results, committed, err = service.TransferBatch(alice.ID, usdt.ID, models.BatchModeAllOrNothing, []models.BatchTransferItem{
+		{ToUserID: bob.ID, Amount: dec("60")},
+		{ToUserID: carol.ID, Amount: dec("40")},
+	})
+	assert.NoError(t, err)
+	assert.True(t, committed)
+	assert.Len(t, results, 2)
+	aliceWallet, _ = walletRepo.GetWalletByUserIDAndCurrency(alice.ID, usdt.ID)
+	assert.Equal(t, "0", aliceWallet.Balance.String())
+	bobWallet, _ = walletRepo.GetWalletByUserIDAndCurrency(bob.ID, usdt.ID)
+	assert.Equal(t, "60", bobWallet.Balance.String())
+	carolWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(carol.ID, usdt.ID)
+	assert.Equal(t, "40", carolWallet.Balance.String())
+}
+
+// TestTransferBatch_Fail_SenderProblemsAbortWholeBatch verifies sender-level errors are not reported per item
+func TestTransferBatch_Fail_SenderProblemsAbortWholeBatch(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	carol := test.CreateTestUser(db, "carol")
+	dave := test.CreateTestUser(db, "dave") // 沒有 USDT 錢包
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+	test.CreateTestWallet(db, carol.ID, usdt.ID, 0)
+
+	walletRepo := repositories.NewWalletRepository()
+	service := NewTransactionService(walletRepo, repositories.NewTransactionRepository())
+
+	items := []models.BatchTransferItem{{ToUserID: alice.ID, Amount: dec("1")}}
+
+	_, _, err := service.TransferBatch(dave.ID, usdt.ID, models.BatchModeBestEffort, items)
+	assert.EqualError(t, err, "from_user wallet not found for this currency")
+
+	_, _, err = service.TransferBatch(alice.ID, 999, models.BatchModeBestEffort, items)
+	assert.ErrorIs(t, err, ErrCurrencyNotFound)
+
+	assert.NoError(t, db.Model(&models.User{}).Where("id = ?", alice.ID).Update("frozen_at", time.Now()).Error)
+	_, _, err = service.TransferBatch(alice.ID, usdt.ID, models.BatchModeBestEffort, []models.BatchTransferItem{{ToUserID: bob.ID, Amount: dec("1")}})
+	assert.ErrorIs(t, err, ErrAccountFrozen)
+}
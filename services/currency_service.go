@@ -1,17 +1,46 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"mini-crypto-wallet-api/db_conn"
 	"mini-crypto-wallet-api/models"
 	"mini-crypto-wallet-api/repositories"
+	"mini-crypto-wallet-api/utils"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const currencyPrecisionBatchSize = 500
+
+var (
+	ErrCurrencyNotFound      = errors.New("currency not found")
+	ErrCurrencyAlreadyExists = errors.New("currency code already exists")
+	ErrCurrencyInactive      = errors.New("currency is inactive")
+	ErrPrecisionConflict     = errors.New("existing wallet balances have more decimal places than the new precision")
+	ErrAmountPrecision       = errors.New("amount has more decimal places than the currency allows")
+)
+
+// PrecisionPolicy 金額小數位數超過幣種精度時的處理方式，零值等同 reject
+// 由啟動設定決定，建立服務時傳入
+type PrecisionPolicy string
+
+const (
+	PrecisionPolicyReject PrecisionPolicy = "reject" // 拒絕請求
+	PrecisionPolicyRound  PrecisionPolicy = "round"  // 四捨五入到幣種精度
 )
 
 type CurrencyService struct {
 	currencyRepo repositories.ICurrency
+	walletRepo   repositories.IWallet
 }
 
 func NewCurrencyService(currencyRepo repositories.ICurrency) *CurrencyService {
 	return &CurrencyService{
 		currencyRepo: currencyRepo,
+		walletRepo:   repositories.NewWalletRepository(),
 	}
 }
 
@@ -19,6 +48,11 @@ func (s *CurrencyService) GetAllCurrencies() ([]models.Currency, error) {
 	return s.currencyRepo.GetAllCurrencies()
 }
 
+// ListCurrencies 取得所有幣種，包含已停用的幣種（管理用）
+func (s *CurrencyService) ListCurrencies() ([]models.Currency, error) {
+	return s.currencyRepo.FindAllCurrencies()
+}
+
 func (s *CurrencyService) GetCurrencyByCode(code string) (*models.Currency, error) {
 	return s.currencyRepo.GetCurrencyByCode(code)
 }
@@ -26,3 +60,130 @@ func (s *CurrencyService) GetCurrencyByCode(code string) (*models.Currency, erro
 func (s *CurrencyService) GetCurrencyByID(id uint) (*models.Currency, error) {
 	return s.currencyRepo.GetCurrencyByID(id)
 }
+
+// CreateCurrency 新增幣種，代碼一律轉為大寫且不可重複（包含已停用的幣種）
+func (s *CurrencyService) CreateCurrency(req models.CreateCurrencyRequest) (*models.Currency, error) {
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if _, err := s.currencyRepo.FindCurrencyByCode(code); err == nil {
+		return nil, ErrCurrencyAlreadyExists
+	}
+
+	active := req.IsActive == nil || *req.IsActive
+	decimals := *req.Decimals
+	currency := &models.Currency{
+		Code:     code,
+		Name:     strings.TrimSpace(req.Name),
+		Symbol:   strings.TrimSpace(req.Symbol),
+		Decimals: decimals,
+		IsActive: active,
+	}
+	if err := s.currencyRepo.CreateCurrency(currency); err != nil {
+		return nil, err
+	}
+	return currency, nil
+}
+
+// UpdateCurrency 修改幣種名稱、符號、精度與啟用狀態
+// 幣種在同一個 DB 交易中被鎖定、檢查並更新；降低精度前會確認所有錢包餘額都能以新精度表示
+func (s *CurrencyService) UpdateCurrency(id uint, req models.UpdateCurrencyRequest) (*models.Currency, error) {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	currency, err := s.currencyRepo.FindCurrencyByIDWithTx(id, tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, ErrCurrencyNotFound
+	}
+
+	if req.Name != nil {
+		currency.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Symbol != nil {
+		currency.Symbol = strings.TrimSpace(*req.Symbol)
+	}
+	if req.Decimals != nil && *req.Decimals != currency.Decimals {
+		if *req.Decimals < currency.Decimals {
+			if err := s.ensureBalancesFitPrecision(tx, currency.ID, *req.Decimals); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+		currency.Decimals = *req.Decimals
+	}
+	if req.IsActive != nil {
+		currency.IsActive = *req.IsActive
+	}
+
+	if err := s.currencyRepo.UpdateCurrency(currency, tx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return nil, commitDB.Error
+	}
+	return currency, nil
+}
+
+// SetActive 啟用或停用幣種，停用後不會出現在公開的幣種列表中，也不能再轉帳
+func (s *CurrencyService) SetActive(id uint, active bool) (*models.Currency, error) {
+	return s.UpdateCurrency(id, models.UpdateCurrencyRequest{IsActive: &active})
+}
+
+// ensureBalancesFitPrecision 檢查幣種所有錢包的可用與凍結餘額小數位數不超過 decimals
+func (s *CurrencyService) ensureBalancesFitPrecision(tx *gorm.DB, currencyID uint, decimals int) error {
+	target := models.Currency{Decimals: decimals}
+	return s.walletRepo.FindWalletsByCurrencyInBatches(currencyID, currencyPrecisionBatchSize, func(wallets []models.Wallet) error {
+		for _, wallet := range wallets {
+			if !target.FitsPrecision(wallet.Balance) || !target.FitsPrecision(wallet.HeldBalance) {
+				return fmt.Errorf("%w: wallet %d", ErrPrecisionConflict, wallet.ID)
+			}
+		}
+		return nil
+	}, tx)
+}
+
+// ParsePrecisionPolicy 解析設定中的精度策略，空字串代表預設的 reject
+func ParsePrecisionPolicy(policy string) (PrecisionPolicy, error) {
+	switch PrecisionPolicy(policy) {
+	case "":
+		return PrecisionPolicyReject, nil
+	case PrecisionPolicyReject, PrecisionPolicyRound:
+		return PrecisionPolicy(policy), nil
+	default:
+		return "", fmt.Errorf("unknown amount precision policy %q", policy)
+	}
+}
+
+// apply 依精度策略檢查或四捨五入金額，處理後的金額必須仍為正數
+func (p PrecisionPolicy) apply(currency *models.Currency, amount decimal.Decimal) (decimal.Decimal, error) {
+	if !currency.FitsPrecision(amount) {
+		if p != PrecisionPolicyRound {
+			return decimal.Zero, fmt.Errorf("%w: %s allows %d", ErrAmountPrecision, currency.Code, currency.Decimals)
+		}
+		amount = amount.Round(int32(currency.Decimals))
+	}
+	if !amount.IsPositive() {
+		return decimal.Zero, errors.New("amount must be positive")
+	}
+	return amount, nil
+}
+
+// activeCurrency 取得幣種，停用的幣種不能再轉帳
+// 傳入 tx 時以共享鎖讀取，精度檢查到 commit 之間幣種設定不會被 UpdateCurrency 修改
+func activeCurrency(currencyRepo repositories.ICurrency, currencyID uint, tx ...*gorm.DB) (*models.Currency, error) {
+	var currency *models.Currency
+	var err error
+	if len(tx) > 0 {
+		currency, err = currencyRepo.FindCurrencyByIDForShareWithTx(currencyID, tx[0])
+	} else {
+		currency, err = currencyRepo.FindCurrencyByID(currencyID)
+	}
+	if err != nil {
+		return nil, ErrCurrencyNotFound
+	}
+	if !currency.IsActive {
+		return nil, ErrCurrencyInactive
+	}
+	return currency, nil
+}
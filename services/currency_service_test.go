@@ -0,0 +1,223 @@
+package services
+
+import (
+	"fmt"
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func intPtr(v int) *int { return &v }
+
+func boolPtr(v bool) *bool { return &v }
+
+// TestCreateCurrency_UppercasesAndRejectsDuplicates verifies codes are normalized and unique across active and inactive currencies
+func TestCreateCurrency_UppercasesAndRejectsDuplicates(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	service := NewCurrencyService(repositories.NewCurrencyRepository())
+
+	currency, err := service.CreateCurrency(models.CreateCurrencyRequest{Code: "usdc", Name: "USD Coin", Symbol: "$", Decimals: intPtr(6), IsActive: boolPtr(false)})
+	assert.NoError(t, err)
+	assert.Equal(t, "USDC", currency.Code)
+	assert.False(t, currency.IsActive)
+
+	stored, err := repositories.NewCurrencyRepository().FindCurrencyByID(currency.ID)
+	assert.NoError(t, err)
+	assert.False(t, stored.IsActive)
+	assert.Equal(t, 6, stored.Decimals)
+
+	jpy, err := service.CreateCurrency(models.CreateCurrencyRequest{Code: "JPY", Name: "Yen", Symbol: "¥", Decimals: intPtr(0)})
+	assert.NoError(t, err)
+	stored, err = repositories.NewCurrencyRepository().FindCurrencyByID(jpy.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stored.Decimals)
+	assert.True(t, stored.IsActive)
+
+	_, err = service.CreateCurrency(models.CreateCurrencyRequest{Code: "USDC", Name: "Dup", Symbol: "$", Decimals: intPtr(2)})
+	assert.ErrorIs(t, err, ErrCurrencyAlreadyExists)
+}
+
+// TestUpdateCurrency_DecimalsValidatedAgainstBalances verifies precision can only be lowered when every balance still fits
+func TestUpdateCurrency_DecimalsValidatedAgainstBalances(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "BTC")
+	alice := test.CreateTestUser(db, "alice")
+	test.CreateTestWalletWithDecimal(db, alice.ID, currency.ID, decimal.RequireFromString("1.2345"))
+
+	service := NewCurrencyService(repositories.NewCurrencyRepository())
+
+	_, err := service.UpdateCurrency(currency.ID, models.UpdateCurrencyRequest{Decimals: intPtr(2)})
+	assert.ErrorIs(t, err, ErrPrecisionConflict)
+
+	name := "Bitcoin"
+	updated, err := service.UpdateCurrency(currency.ID, models.UpdateCurrencyRequest{Decimals: intPtr(4), Name: &name})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, updated.Decimals)
+	assert.Equal(t, "Bitcoin", updated.Name)
+
+	_, err = service.UpdateCurrency(currency.ID+100, models.UpdateCurrencyRequest{Decimals: intPtr(4)})
+	assert.ErrorIs(t, err, ErrCurrencyNotFound)
+}
+
+// TestTransfer_RefusedInInactiveCurrency verifies deactivating a currency stops transfers until it is re-activated
+func TestTransfer_RefusedInInactiveCurrency(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	currencyService := NewCurrencyService(repositories.NewCurrencyRepository())
+	_, err := currencyService.SetActive(currency.ID, false)
+	assert.NoError(t, err)
+
+	txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	err = txService.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(100))
+	assert.ErrorIs(t, err, ErrCurrencyInactive)
+
+	_, err = currencyService.SetActive(currency.ID, true)
+	assert.NoError(t, err)
+	assert.NoError(t, txService.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(100)))
+}
+
+// TestAmountPrecision_PerCurrency verifies transfers, deposits and withdrawals honour each currency's decimals
+func TestAmountPrecision_PerCurrency(t *testing.T) {
+	cases := []struct {
+		decimals int
+		fits     string // 剛好符合精度的金額
+		tooFine  string // 多一位小數的金額
+		rounded  string // round 策略下 tooFine 的結果
+		balance  string // 依精度顯示的餘額
+	}{
+		{decimals: 0, fits: "5", tooFine: "5.5", rounded: "6", balance: "1000"},
+		{decimals: 2, fits: "5.25", tooFine: "5.255", rounded: "5.26", balance: "1000.00"},
+		{decimals: 6, fits: "5.123456", tooFine: "5.1234564", rounded: "5.123456", balance: "1000.000000"},
+		{decimals: 8, fits: "5.12345678", tooFine: "5.123456785", rounded: "5.12345679", balance: "1000.00000000"},
+	}
+
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("%d_decimals", tc.decimals), func(t *testing.T) {
+			db := test.SetupTestDB()
+			defer test.CleanupTestDB(db)
+
+			currency := test.CreateTestCurrency(db, "C")
+			db.Model(currency).Update("decimals", tc.decimals)
+			alice := test.CreateTestUser(db, "alice")
+			bob := test.CreateTestUser(db, "bob")
+			test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+			test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+			txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+			funding := NewFundingService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+			fits := decimal.RequireFromString(tc.fits)
+			tooFine := decimal.RequireFromString(tc.tooFine)
+
+			wallet, err := NewWalletService(repositories.NewWalletRepository(), repositories.NewCurrencyRepository()).GetWalletByCurrency(alice.ID, currency.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.balance, models.ToWalletResponse(wallet).Balance)
+
+			// reject：超過精度的金額一律拒絕
+			_, err = txService.TransferWithResult(alice.ID, bob.ID, currency.ID, tooFine)
+			assert.ErrorIs(t, err, ErrAmountPrecision)
+			_, err = funding.CreateDeposit(alice.ID, currency.ID, tooFine, "ref")
+			assert.ErrorIs(t, err, ErrAmountPrecision)
+			_, err = funding.CreateWithdrawal(alice.ID, currency.ID, tooFine, "addr")
+			assert.ErrorIs(t, err, ErrAmountPrecision)
+
+			transfer, err := txService.TransferWithResult(alice.ID, bob.ID, currency.ID, fits)
+			assert.NoError(t, err)
+			assert.True(t, fits.Equal(transfer.Amount))
+			_, err = funding.CreateDeposit(alice.ID, currency.ID, fits, "ref")
+			assert.NoError(t, err)
+			_, err = funding.CreateWithdrawal(alice.ID, currency.ID, fits, "addr")
+			assert.NoError(t, err)
+
+			// round：四捨五入到幣種精度
+			txService.WithPrecisionPolicy(PrecisionPolicyRound)
+			funding.WithPrecisionPolicy(PrecisionPolicyRound)
+			transfer, err = txService.TransferWithResult(alice.ID, bob.ID, currency.ID, tooFine)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.rounded, transfer.Amount.String())
+			withdrawal, err := funding.CreateWithdrawal(alice.ID, currency.ID, tooFine, "addr")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.rounded, withdrawal.Amount.String())
+		})
+	}
+}
+
+// TestAmountPrecision_RoundingToZeroIsRejected verifies rounding cannot produce an empty transfer
+func TestAmountPrecision_RoundingToZeroIsRejected(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "JPY")
+	db.Model(currency).Update("decimals", 0)
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	policy, err := ParsePrecisionPolicy("round")
+	assert.NoError(t, err)
+	assert.Equal(t, PrecisionPolicyRound, policy)
+	_, err = ParsePrecisionPolicy("truncate")
+	assert.Error(t, err)
+
+	txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository()).WithPrecisionPolicy(policy)
+	err = txService.Transfer(alice.ID, bob.ID, currency.ID, decimal.RequireFromString("0.4"))
+	assert.EqualError(t, err, "amount must be positive")
+}
+
+// shareRecordingCurrencyRepo counts currency reads taken under a share lock and those taken without one
+type shareRecordingCurrencyRepo struct {
+	repositories.ICurrency
+	shared   int
+	unlocked int
+}
+
+func (r *shareRecordingCurrencyRepo) FindCurrencyByID(id uint) (*models.Currency, error) {
+	r.unlocked++
+	return r.ICurrency.FindCurrencyByID(id)
+}
+
+func (r *shareRecordingCurrencyRepo) FindCurrencyByIDForShareWithTx(id uint, tx ...*gorm.DB) (*models.Currency, error) {
+	r.shared++
+	return r.ICurrency.FindCurrencyByIDForShareWithTx(id, tx...)
+}
+
+// TestMoneyPaths_ReadCurrencyUnderShareLock verifies transfers and funding check precision against a share-locked currency
+func TestMoneyPaths_ReadCurrencyUnderShareLock(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	repo := &shareRecordingCurrencyRepo{ICurrency: repositories.NewCurrencyRepository()}
+	txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	txService.currencyRepo = repo
+	funding := NewFundingService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	funding.currencyRepo = repo
+
+	assert.NoError(t, txService.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(10)))
+	_, err := funding.CreateWithdrawal(alice.ID, currency.ID, decimal.NewFromInt(10), "addr")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, repo.shared)
+	assert.Zero(t, repo.unlocked)
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"mini-crypto-wallet-api/internal/events"
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/kafka_client"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// publishOutbox runs the outbox relay once into a fresh recorder and returns what it published
+func publishOutbox(t *testing.T) *test.EventRecorder {
+	t.Helper()
+	recorder := test.NewEventRecorder()
+	_, err := NewOutboxRelay(repositories.NewOutboxRepository(), recorder).ProcessOnce(context.Background())
+	assert.NoError(t, err)
+	return recorder
+}
+
+// TestEvents_ReversalEmitsTypedPayloads verifies a reversal publishes reversal.completed and the original's status change
+func TestEvents_ReversalEmitsTypedPayloads(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	service := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	transfer, err := service.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("40"))
+	assert.NoError(t, err)
+
+	original, reversal, err := service.Reverse(transfer.Hash, "fraud")
+	assert.NoError(t, err)
+
+	recorder := publishOutbox(t)
+	assert.Equal(t, []string{events.TypeTransferCompleted, events.TypeReversalCompleted}, recorder.Types(t, kafka_client.TopicTxCreated))
+
+	var payload events.ReversalPayload
+	recorder.Last(t, kafka_client.TopicTxCreated, events.TypeReversalCompleted, &payload)
+	assert.Equal(t, reversal.Hash, payload.Hash)
+	assert.Equal(t, original.Hash, payload.OriginalHash)
+	assert.Equal(t, bob.ID, payload.FromUserID)
+	assert.Equal(t, usdt.ID, payload.CurrencyID)
+	assert.Equal(t, "fraud", payload.Reason)
+	assert.Equal(t, models.TxStatusCompleted, payload.Status)
+
+	assert.Equal(t, []string{events.TypeTransferStatusChanged}, recorder.Types(t, kafka_client.TopicTxStatusChanged))
+	var status events.TransferPayload
+	recorder.Last(t, kafka_client.TopicTxStatusChanged, events.TypeTransferStatusChanged, &status)
+	assert.Equal(t, models.TxStatusCompleted, status.PreviousStatus)
+	assert.Equal(t, models.TxStatusReversed, status.Status)
+}
+
+// TestEvents_FundingStatusChanges verifies deposits publish deposit.status_changed with both statuses
+func TestEvents_FundingStatusChanges(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 0)
+
+	funding := NewFundingService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	deposit, err := funding.CreateDeposit(alice.ID, currency.ID, dec("250"), "bank-ref-001")
+	assert.NoError(t, err)
+	_, err = funding.MarkProcessing(deposit.Hash)
+	assert.NoError(t, err)
+
+	recorder := publishOutbox(t)
+	assert.Equal(t, []string{events.TypeDepositStatusChanged, events.TypeDepositStatusChanged}, recorder.Types(t, kafka_client.TopicTxStatusChanged))
+
+	var payload events.FundingPayload
+	recorder.Last(t, kafka_client.TopicTxStatusChanged, events.TypeDepositStatusChanged, &payload)
+	assert.Equal(t, deposit.Hash, payload.Hash)
+	assert.Equal(t, alice.ID, payload.UserID)
+	assert.Equal(t, "250", payload.Amount.String())
+	assert.Equal(t, "bank-ref-001", payload.Reference)
+	assert.Equal(t, models.TxStatusPending, payload.PreviousStatus)
+	assert.Equal(t, models.TxStatusProcessing, payload.Status)
+}
+
+// TestEvents_UserLifecycle verifies user creation, freezing and role changes publish user events
+func TestEvents_UserLifecycle(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	test.CreateTestCurrency(db, "USDT")
+	staff := test.CreateTestUser(db, "staff")
+	userService := NewUserService(repositories.NewUserRepository(), repositories.NewWalletRepository(), repositories.NewCurrencyRepository())
+	carol, err := userService.CreateUser(&models.UserCreateRequest{Username: "carol", Email: "carol@example.com", Password: "password123"})
+	assert.NoError(t, err)
+
+	admin := newTestAdminService(newTestAuthService())
+	_, err = admin.FreezeUser(staff.ID, carol.ID, "suspicious activity")
+	assert.NoError(t, err)
+	_, err = admin.UnfreezeUser(carol.ID)
+	assert.NoError(t, err)
+	_, err = admin.UpdateRole(staff.ID, carol.ID, models.RoleSupport)
+	assert.NoError(t, err)
+
+	recorder := publishOutbox(t)
+	envelopes := recorder.Envelopes(t, kafka_client.TopicUserEvents)
+	assert.Equal(t, []string{events.TypeUserCreated, events.TypeUserFrozen, events.TypeUserUnfrozen, events.TypeUserRoleChanged}, recorder.Types(t, kafka_client.TopicUserEvents))
+
+	var created, frozen, role events.UserPayload
+	assert.NoError(t, envelopes[0].DecodeInto(&created))
+	assert.NoError(t, envelopes[1].DecodeInto(&frozen))
+	assert.NoError(t, envelopes[3].DecodeInto(&role))
+	assert.Equal(t, carol.ID, created.UserID)
+	assert.Equal(t, "carol", created.Username)
+	assert.Equal(t, models.RoleUser, created.Role)
+	assert.True(t, frozen.Frozen)
+	assert.Equal(t, "suspicious activity", frozen.FrozenReason)
+	assert.Equal(t, models.RoleSupport, role.Role)
+	assert.False(t, role.Frozen)
+}
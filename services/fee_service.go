@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"mini-crypto-wallet-api/utils"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFeeScheduleNotFound = errors.New("fee schedule not found")
+	ErrInvalidFeeSchedule  = errors.New("invalid fee schedule")
+)
+
+// FeeService 管理各幣種的轉帳手續費設定並計算手續費
+// 手續費由轉出方在轉帳金額之外另行支付，計入該幣種的 system_fees 帳戶
+type FeeService struct {
+	feeRepo      repositories.IFeeSchedule
+	currencyRepo repositories.ICurrency
+	precision    PrecisionPolicy
+}
+
+func NewFeeService() *FeeService {
+	return &FeeService{
+		feeRepo:      repositories.NewFeeScheduleRepository(),
+		currencyRepo: repositories.NewCurrencyRepository(),
+	}
+}
+
+// WithPrecisionPolicy 設定試算時的精度策略，需與實際轉帳相同，回傳 s 方便串接
+func (s *FeeService) WithPrecisionPolicy(policy PrecisionPolicy) *FeeService {
+	s.precision = policy
+	return s
+}
+
+// GetSchedule 取得幣種的手續費設定
+func (s *FeeService) GetSchedule(currencyID uint) (*models.FeeSchedule, error) {
+	schedule, err := s.feeRepo.GetByCurrencyID(currencyID)
+	if err != nil {
+		return nil, ErrFeeScheduleNotFound
+	}
+	return schedule, nil
+}
+
+// SetSchedule 設定（取代）幣種的手續費設定
+func (s *FeeService) SetSchedule(currencyID uint, req models.FeeScheduleRequest) (*models.FeeSchedule, error) {
+	if _, err := s.currencyRepo.FindCurrencyByID(currencyID); err != nil {
+		return nil, ErrCurrencyNotFound
+	}
+
+	schedule := &models.FeeSchedule{
+		CurrencyID: currencyID,
+		Type:       req.Type,
+		FlatFee:    req.FlatFee,
+		Rate:       req.Rate,
+		MinFee:     req.MinFee,
+		MaxFee:     req.MaxFee,
+	}
+	for _, tier := range req.Tiers {
+		schedule.Tiers = append(schedule.Tiers, models.FeeTier{
+			MinAmount: tier.MinAmount,
+			FlatFee:   tier.FlatFee,
+			Rate:      tier.Rate,
+		})
+	}
+	if err := validateFeeSchedule(schedule); err != nil {
+		return nil, err
+	}
+
+	if err := s.feeRepo.ReplaceSchedule(schedule); err != nil {
+		return nil, err
+	}
+	return s.feeRepo.GetByCurrencyID(currencyID)
+}
+
+// DeleteSchedule 移除幣種的手續費設定，之後該幣種轉帳免手續費
+func (s *FeeService) DeleteSchedule(currencyID uint) error {
+	if _, err := s.feeRepo.GetByCurrencyID(currencyID); err != nil {
+		return ErrFeeScheduleNotFound
+	}
+	return s.feeRepo.DeleteByCurrencyID(currencyID)
+}
+
+// Quote 計算轉帳手續費，套用與實際轉帳相同的幣種與精度檢查
+func (s *FeeService) Quote(currencyID uint, amount decimal.Decimal) (*models.TransferQuoteResponse, error) {
+	if !utils.ValidatePositiveAmount(amount) {
+		return nil, errors.New("amount must be positive")
+	}
+
+	currency, err := activeCurrency(s.currencyRepo, currencyID)
+	if err != nil {
+		return nil, err
+	}
+	if amount, err = s.precision.apply(currency, amount); err != nil {
+		return nil, err
+	}
+
+	fee, err := s.feeFor(currency, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TransferQuoteResponse{
+		CurrencyID: currency.ID,
+		Amount:     amount,
+		Fee:        fee,
+		Total:      amount.Add(fee),
+	}, nil
+}
+
+// feeFor 計算幣種的轉帳手續費，沒有設定時為 0
+func (s *FeeService) feeFor(currency *models.Currency, amount decimal.Decimal, tx ...*gorm.DB) (decimal.Decimal, error) {
+	schedule, err := s.feeRepo.GetByCurrencyID(currency.ID, tx...)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return decimal.Zero, nil
+	}
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return schedule.Calculate(amount, currency.Decimals), nil
+}
+
+// validateFeeSchedule 檢查金額不為負、比例小於 1，且級距從 0 開始、不重複
+func validateFeeSchedule(schedule *models.FeeSchedule) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: %s", ErrInvalidFeeSchedule, reason)
+	}
+
+	for _, v := range []decimal.Decimal{schedule.FlatFee, schedule.Rate, schedule.MinFee, schedule.MaxFee} {
+		if v.IsNegative() {
+			return invalid("fees and rates must not be negative")
+		}
+	}
+	if schedule.Rate.GreaterThanOrEqual(decimal.NewFromInt(1)) {
+		return invalid("rate must be below 1")
+	}
+	if schedule.MaxFee.IsPositive() && schedule.MaxFee.LessThan(schedule.MinFee) {
+		return invalid("max_fee must not be below min_fee")
+	}
+
+	switch schedule.Type {
+	case models.FeeTypeFlat, models.FeeTypePercentage:
+		if len(schedule.Tiers) > 0 {
+			return invalid("tiers are only allowed for tiered schedules")
+		}
+	case models.FeeTypeTiered:
+		if len(schedule.Tiers) == 0 {
+			return invalid("tiered schedules need at least one tier")
+		}
+		hasZero := false
+		seen := make(map[string]bool)
+		for _, tier := range schedule.Tiers {
+			if tier.MinAmount.IsNegative() || tier.FlatFee.IsNegative() || tier.Rate.IsNegative() {
+				return invalid("tier values must not be negative")
+			}
+			if tier.Rate.GreaterThanOrEqual(decimal.NewFromInt(1)) {
+				return invalid("tier rate must be below 1")
+			}
+			key := tier.MinAmount.String()
+			if seen[key] {
+				return invalid("tier min_amount values must be unique")
+			}
+			seen[key] = true
+			hasZero = hasZero || tier.MinAmount.IsZero()
+		}
+		if !hasZero {
+			return invalid("the first tier must start at min_amount 0")
+		}
+	default:
+		return invalid("unknown type " + schedule.Type)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+// TestFeeSchedule_Calculate covers flat, percentage (with min / max) and tiered schedules
+func TestFeeSchedule_Calculate(t *testing.T) {
+	flat := &models.FeeSchedule{Type: models.FeeTypeFlat, FlatFee: dec("1.5")}
+	assert.Equal(t, "1.5", flat.Calculate(dec("10"), 2).String())
+
+	percentage := &models.FeeSchedule{Type: models.FeeTypePercentage, Rate: dec("0.001"), MinFee: dec("0.5"), MaxFee: dec("25")}
+	assert.Equal(t, "0.5", percentage.Calculate(dec("100"), 2).String())     // 0.1 → min
+	assert.Equal(t, "1", percentage.Calculate(dec("1000"), 2).String())      // 1
+	assert.Equal(t, "25", percentage.Calculate(dec("1000000"), 2).String())  // 1000 → max
+	assert.Equal(t, "1.24", percentage.Calculate(dec("1234.5"), 2).String()) // 1.2345 → 無條件進位
+
+	tiered := &models.FeeSchedule{Type: models.FeeTypeTiered, Tiers: []models.FeeTier{
+		{MinAmount: dec("1000"), Rate: dec("0.001")},
+		{MinAmount: dec("0"), FlatFee: dec("1")},
+	}}
+	assert.Equal(t, "1", tiered.Calculate(dec("999"), 8).String())
+	assert.Equal(t, "2", tiered.Calculate(dec("2000"), 8).String())
+}
+
+// TestTransfer_ChargesFeeIntoSystemFeesAccount verifies the sender pays amount + fee and the quote matches
+func TestTransfer_ChargesFeeIntoSystemFeesAccount(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	_, err := NewFeeService().SetSchedule(currency.ID, models.FeeScheduleRequest{
+		Type:   models.FeeTypePercentage,
+		Rate:   dec("0.01"),
+		MinFee: dec("0.5"),
+	})
+	assert.NoError(t, err)
+
+	txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	quote, err := txService.QuoteTransfer(currency.ID, dec("200"))
+	assert.NoError(t, err)
+	assert.Equal(t, "2", quote.Fee.String())
+	assert.Equal(t, "202", quote.Total.String())
+
+	transaction, err := txService.TransferWithResult(alice.ID, bob.ID, currency.ID, dec("200"))
+	assert.NoError(t, err)
+	assert.Equal(t, quote.Fee.String(), transaction.Fee.String())
+
+	walletRepo := repositories.NewWalletRepository()
+	aliceWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, currency.ID)
+	bobWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(bob.ID, currency.ID)
+	assert.Equal(t, "798", aliceWallet.Balance.String())
+	assert.Equal(t, "200", bobWallet.Balance.String())
+
+	fees, err := repositories.NewLedgerRepository().FindAccountByCodeWithTx(models.SystemAccountCode(models.LedgerKindFees, currency.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, "2", fees.Balance.String())
+
+	// 餘額不足以支付金額加手續費時拒絕
+	_, err = txService.TransferWithResult(alice.ID, bob.ID, currency.ID, dec("795"))
+	assert.Error(t, err)
+
+	run, err := newTestReconciliationService().Run(models.ReconciliationTriggerManual)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, run.FindingCount)
+}
+
+// TestSetFeeSchedule_Validation verifies invalid schedules are rejected and deleting makes transfers free
+func TestSetFeeSchedule_Validation(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	service := NewFeeService()
+
+	invalid := []models.FeeScheduleRequest{
+		{Type: models.FeeTypeFlat, FlatFee: dec("-1")},
+		{Type: models.FeeTypePercentage, Rate: dec("1")},
+		{Type: models.FeeTypePercentage, Rate: dec("0.01"), MinFee: dec("5"), MaxFee: dec("1")},
+		{Type: models.FeeTypeTiered},
+		{Type: models.FeeTypeTiered, Tiers: []models.FeeTierRequest{{MinAmount: dec("100"), FlatFee: dec("1")}}},
+		{Type: models.FeeTypeFlat, Tiers: []models.FeeTierRequest{{MinAmount: dec("0")}}},
+	}
+	for _, req := range invalid {
+		_, err := service.SetSchedule(currency.ID, req)
+		assert.ErrorIs(t, err, ErrInvalidFeeSchedule)
+	}
+
+	_, err := service.SetSchedule(999, models.FeeScheduleRequest{Type: models.FeeTypeFlat, FlatFee: dec("1")})
+	assert.ErrorIs(t, err, ErrCurrencyNotFound)
+
+	schedule, err := service.SetSchedule(currency.ID, models.FeeScheduleRequest{Type: models.FeeTypeTiered, Tiers: []models.FeeTierRequest{
+		{MinAmount: dec("0"), FlatFee: dec("1")},
+		{MinAmount: dec("1000"), Rate: dec("0.001")},
+	}})
+	assert.NoError(t, err)
+	assert.Len(t, schedule.Tiers, 2)
+
+	assert.NoError(t, service.DeleteSchedule(currency.ID))
+	assert.ErrorIs(t, service.DeleteSchedule(currency.ID), ErrFeeScheduleNotFound)
+
+	quote, err := service.Quote(currency.ID, dec("50"))
+	assert.NoError(t, err)
+	assert.True(t, quote.Fee.IsZero())
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"errors"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/internal/events"
+	"mini-crypto-wallet-api/kafka_client"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"mini-crypto-wallet-api/utils"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTransactionNotFound     = errors.New("transaction not found")
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
+)
+
+// FundingService 處理入金與出金
+// 兩者都依 pending → processing → completed / failed / cancelled 的生命週期推進
+// 出金建立時先凍結資金（Balance → HeldBalance），結算或失敗時才解除凍結
+type FundingService struct {
+	walletRepo         repositories.IWallet
+	transactionRepo    repositories.ITransaction
+	balanceHistoryRepo repositories.IBalanceHistory
+	outboxRepo         repositories.IOutbox
+	userRepo           repositories.IUser
+	currencyRepo       repositories.ICurrency
+	ledger             *LedgerService
+	precision          PrecisionPolicy
+}
+
+func NewFundingService(walletRepo repositories.IWallet, txRepo repositories.ITransaction) *FundingService {
+	return &FundingService{
+		walletRepo:         walletRepo,
+		transactionRepo:    txRepo,
+		balanceHistoryRepo: repositories.NewBalanceHistoryRepository(),
+		outboxRepo:         repositories.NewOutboxRepository(),
+		userRepo:           repositories.NewUserRepository(),
+		currencyRepo:       repositories.NewCurrencyRepository(),
+		ledger:             NewLedgerService(),
+	}
+}
+
+// WithPrecisionPolicy 設定入金與出金金額的精度策略，回傳 s 方便串接
+func (s *FundingService) WithPrecisionPolicy(policy PrecisionPolicy) *FundingService {
+	s.precision = policy
+	return s
+}
+
+// CreateDeposit 建立待入帳的入金，完成（completed）前不影響餘額
+func (s *FundingService) CreateDeposit(userID, currencyID uint, amount decimal.Decimal, reference string) (*models.Transaction, error) {
+	return s.create(models.TxTypeDeposit, userID, currencyID, amount, reference)
+}
+
+// CreateWithdrawal 建立出金並凍結對應金額
+func (s *FundingService) CreateWithdrawal(userID, currencyID uint, amount decimal.Decimal, address string) (*models.Transaction, error) {
+	return s.create(models.TxTypeWithdrawal, userID, currencyID, amount, address)
+}
+
+// MarkProcessing 將入金 / 出金標記為處理中
+func (s *FundingService) MarkProcessing(hash string) (*models.Transaction, error) {
+	return s.transition(hash, models.TxStatusProcessing, "", nil)
+}
+
+// Complete 完成入金（入帳）或出金（扣除凍結金額）
+func (s *FundingService) Complete(hash string) (*models.Transaction, error) {
+	return s.transition(hash, models.TxStatusCompleted, "", nil)
+}
+
+// Fail 將入金 / 出金標記為失敗，出金的凍結金額會退回可用餘額
+func (s *FundingService) Fail(hash string, reason string) (*models.Transaction, error) {
+	return s.transition(hash, models.TxStatusFailed, reason, nil)
+}
+
+// Cancel 由用戶取消自己尚在 pending 的入金 / 出金
+func (s *FundingService) Cancel(userID uint, txType string, hash string) (*models.Transaction, error) {
+	return s.transition(hash, models.TxStatusCancelled, "cancelled by user", func(transaction *models.Transaction) error {
+		if transaction.Type != txType || transaction.FromUserID != userID {
+			return ErrTransactionNotFound
+		}
+		return nil
+	})
+}
+
+func (s *FundingService) create(txType string, userID, currencyID uint, amount decimal.Decimal, reference string) (*models.Transaction, error) {
+	if !utils.ValidatePositiveAmount(amount) {
+		return nil, errors.New("amount must be positive")
+	}
+
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	transaction, err := s.createInTx(tx, txType, userID, currencyID, amount, reference)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return nil, commitDB.Error
+	}
+	streamCommitted(s.walletRepo, s.balanceHistoryRepo, transaction)
+
+	return transaction, nil
+}
+
+func (s *FundingService) createInTx(tx *gorm.DB, txType string, userID, currencyID uint, amount decimal.Decimal, reference string) (*models.Transaction, error) {
+	currency, err := activeCurrency(s.currencyRepo, currencyID, tx)
+	if err != nil {
+		return nil, err
+	}
+	if amount, err = s.precision.apply(currency, amount); err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.walletRepo.GetWalletByUserIDAndCurrencyWithTx(userID, currencyID, tx)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+
+	balanceBefore := wallet.Balance
+	changeType := models.ChangeTypeStatus
+
+	if txType == models.TxTypeWithdrawal {
+		if err := ensureNotFrozen(s.userRepo, userID); err != nil {
+			return nil, err
+		}
+		if wallet.Balance.LessThan(amount) {
+			return nil, ErrInsufficientBalance
+		}
+
+		// 凍結出金金額
+		wallet.Balance = wallet.Balance.Sub(amount)
+		wallet.HeldBalance = wallet.HeldBalance.Add(amount)
+		if err := s.walletRepo.UpdateWallet(wallet, tx); err != nil {
+			return nil, err
+		}
+		changeType = models.ChangeTypeHold
+	}
+
+	transaction := &models.Transaction{
+		Type:       txType,
+		FromUserID: userID,
+		ToUserID:   userID,
+		CurrencyID: currencyID,
+		Amount:     amount,
+		Status:     models.TxStatusPending,
+		Reference:  reference,
+	}
+	sealTransaction(transaction)
+
+	if err := s.transactionRepo.CreateTransaction(transaction, tx); err != nil {
+		return nil, err
+	}
+
+	if txType == models.TxTypeWithdrawal {
+		// 可用餘額轉入凍結帳戶
+		if err := s.ledger.Post(tx, transaction.ID, models.JournalWithdrawalHold,
+			walletDebit(wallet, amount),
+			heldCredit(wallet, amount),
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.recordChange(tx, wallet, transaction, changeType, balanceBefore); err != nil {
+		return nil, err
+	}
+
+	if err := s.enqueueStatusChanged(transaction, "", tx); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// transition 將交易推進到下一個狀態並套用對應的餘額變動
+// authorize 可在狀態檢查前驗證呼叫者是否有權操作此交易
+func (s *FundingService) transition(hash string, next string, reason string, authorize func(*models.Transaction) error) (*models.Transaction, error) {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	transaction, err := s.transitionInTx(tx, hash, next, reason, authorize)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return nil, commitDB.Error
+	}
+	streamCommitted(s.walletRepo, s.balanceHistoryRepo, transaction)
+
+	return transaction, nil
+}
+
+func (s *FundingService) transitionInTx(tx *gorm.DB, hash string, next string, reason string, authorize func(*models.Transaction) error) (*models.Transaction, error) {
+	transaction, err := s.transactionRepo.FindByHashWithTx(hash, tx)
+	if err != nil {
+		return nil, ErrTransactionNotFound
+	}
+	if transaction.Type != models.TxTypeDeposit && transaction.Type != models.TxTypeWithdrawal {
+		return nil, ErrTransactionNotFound
+	}
+	if authorize != nil {
+		if err := authorize(transaction); err != nil {
+			return nil, err
+		}
+	}
+	if !transaction.CanTransitionTo(next) {
+		return nil, ErrInvalidStatusTransition
+	}
+
+	wallet, err := s.walletRepo.GetWalletByUserIDAndCurrencyWithTx(transaction.FromUserID, transaction.CurrencyID, tx)
+	if err != nil {
+		return nil, ErrWalletNotFound
+	}
+
+	amount := transaction.Amount
+	balanceBefore := wallet.Balance
+	changeType := models.ChangeTypeStatus
+	var entryType string
+	var legs []ledgerLeg
+
+	switch {
+	case transaction.Type == models.TxTypeDeposit && next == models.TxStatusCompleted:
+		wallet.Balance = wallet.Balance.Add(amount)
+		changeType = models.ChangeTypeCredit
+		entryType = models.JournalDeposit
+		legs = []ledgerLeg{systemDebit(models.LedgerKindDeposits, wallet.CurrencyID, amount), walletCredit(wallet, amount)}
+	case transaction.Type == models.TxTypeWithdrawal && next == models.TxStatusCompleted:
+		wallet.HeldBalance = wallet.HeldBalance.Sub(amount)
+		changeType = models.ChangeTypeSettle
+		entryType = models.JournalWithdrawalSettle
+		legs = []ledgerLeg{heldDebit(wallet, amount), systemCredit(models.LedgerKindWithdrawals, wallet.CurrencyID, amount)}
+	case transaction.Type == models.TxTypeWithdrawal && (next == models.TxStatusFailed || next == models.TxStatusCancelled):
+		wallet.HeldBalance = wallet.HeldBalance.Sub(amount)
+		wallet.Balance = wallet.Balance.Add(amount)
+		changeType = models.ChangeTypeRelease
+		entryType = models.JournalWithdrawalRelease
+		legs = []ledgerLeg{heldDebit(wallet, amount), walletCredit(wallet, amount)}
+	}
+
+	if changeType != models.ChangeTypeStatus {
+		if err := s.walletRepo.UpdateWallet(wallet, tx); err != nil {
+			return nil, err
+		}
+		if err := s.ledger.Post(tx, transaction.ID, entryType, legs...); err != nil {
+			return nil, err
+		}
+	}
+
+	previousStatus := transaction.Status
+	transaction.Status = next
+	if reason != "" {
+		transaction.FailReason = reason
+	}
+	if err := s.transactionRepo.UpdateTransaction(transaction, tx); err != nil {
+		return nil, err
+	}
+
+	if err := s.recordChange(tx, wallet, transaction, changeType, balanceBefore); err != nil {
+		return nil, err
+	}
+
+	if err := s.enqueueStatusChanged(transaction, previousStatus, tx); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// recordChange 寫入一筆餘額變動歷史，每次狀態轉換都會留下紀錄
+func (s *FundingService) recordChange(tx *gorm.DB, wallet *models.Wallet, transaction *models.Transaction, changeType string, balanceBefore decimal.Decimal) error {
+	return s.balanceHistoryRepo.CreateHistory(&models.BalanceHistory{
+		UserID:        wallet.UserID,
+		WalletID:      wallet.ID,
+		TransactionID: transaction.ID,
+		ChangeType:    changeType,
+		Status:        transaction.Status,
+		Amount:        transaction.Amount,
+		BalanceBefore: balanceBefore,
+		BalanceAfter:  wallet.Balance,
+	}, tx)
+}
+
+// enqueueStatusChanged 將 tx.status_changed 事件寫入 outbox
+func (s *FundingService) enqueueStatusChanged(transaction *models.Transaction, previousStatus string, tx *gorm.DB) error {
+	return enqueueTxStatusChanged(s.outboxRepo, transaction, previousStatus, tx)
+}
+
+// enqueueTxStatusChanged 將 tx.status_changed 事件寫入 outbox
+// 入金 / 出金帶 FundingPayload，被沖正的轉帳帶 TransferPayload
+func enqueueTxStatusChanged(outboxRepo repositories.IOutbox, transaction *models.Transaction, previousStatus string, tx *gorm.DB) error {
+	var eventType string
+	var payload interface{}
+	switch transaction.Type {
+	case models.TxTypeDeposit, models.TxTypeWithdrawal:
+		eventType = events.TypeDepositStatusChanged
+		if transaction.Type == models.TxTypeWithdrawal {
+			eventType = events.TypeWithdrawalStatusChanged
+		}
+		payload = events.FundingPayload{
+			Hash:           transaction.Hash,
+			UserID:         transaction.FromUserID,
+			CurrencyID:     transaction.CurrencyID,
+			Amount:         transaction.Amount,
+			Reference:      transaction.Reference,
+			PreviousStatus: previousStatus,
+			Status:         transaction.Status,
+			FailReason:     transaction.FailReason,
+		}
+	default:
+		eventType = events.TypeTransferStatusChanged
+		payload = transferPayload(transaction, previousStatus)
+	}
+
+	return enqueueEvent(outboxRepo, kafka_client.TopicTxStatusChanged, transaction.Hash, eventType, payload, time.Now(), tx)
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/kafka_client"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDeposit_Lifecycle_CreditsOnCompletion verifies deposits only move money once completed
+func TestDeposit_Lifecycle_CreditsOnCompletion(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 100)
+
+	walletRepo := repositories.NewWalletRepository()
+	service := NewFundingService(walletRepo, repositories.NewTransactionRepository())
+
+	deposit, err := service.CreateDeposit(alice.ID, currency.ID, decimal.NewFromInt(250), "bank-ref-1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.TxTypeDeposit, deposit.Type)
+	assert.Equal(t, models.TxStatusPending, deposit.Status)
+
+	wallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, currency.ID)
+	assert.Equal(t, "100", wallet.Balance.String())
+
+	_, err = service.MarkProcessing(deposit.Hash)
+	assert.NoError(t, err)
+
+	completed, err := service.Complete(deposit.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TxStatusCompleted, completed.Status)
+
+	wallet, _ = walletRepo.GetWalletByUserIDAndCurrency(alice.ID, currency.ID)
+	assert.Equal(t, "350", wallet.Balance.String())
+
+	// One history entry per transition
+	var histories []models.BalanceHistory
+	db.Where("transaction_id = ?", deposit.ID).Order("id asc").Find(&histories)
+	assert.Len(t, histories, 3)
+	assert.Equal(t, models.ChangeTypeStatus, histories[0].ChangeType)
+	assert.Equal(t, models.TxStatusPending, histories[0].Status)
+	assert.Equal(t, models.ChangeTypeStatus, histories[1].ChangeType)
+	assert.Equal(t, models.TxStatusProcessing, histories[1].Status)
+	assert.Equal(t, models.ChangeTypeCredit, histories[2].ChangeType)
+	assert.Equal(t, "100", histories[2].BalanceBefore.String())
+	assert.Equal(t, "350", histories[2].BalanceAfter.String())
+
+	// One event per transition
+	var events []models.OutboxEvent
+	db.Where("topic = ?", kafka_client.TopicTxStatusChanged).Find(&events)
+	assert.Len(t, events, 3)
+}
+
+// TestWithdrawal_Lifecycle_HoldsAndSettles verifies withdrawals hold funds until they settle
+func TestWithdrawal_Lifecycle_HoldsAndSettles(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	walletRepo := repositories.NewWalletRepository()
+	txRepo := repositories.NewTransactionRepository()
+	service := NewFundingService(walletRepo, txRepo)
+
+	withdrawal, err := service.CreateWithdrawal(alice.ID, currency.ID, decimal.NewFromInt(600), "0xabc")
+	assert.NoError(t, err)
+
+	wallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, currency.ID)
+	assert.Equal(t, "400", wallet.Balance.String())
+	assert.Equal(t, "600", wallet.HeldBalance.String())
+
+	// Held funds cannot be transferred away
+	transfers := NewTransactionService(walletRepo, txRepo)
+	err = transfers.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(500))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "insufficient balance")
+
+	_, err = service.MarkProcessing(withdrawal.Hash)
+	assert.NoError(t, err)
+	_, err = service.Complete(withdrawal.Hash)
+	assert.NoError(t, err)
+
+	wallet, _ = walletRepo.GetWalletByUserIDAndCurrency(alice.ID, currency.ID)
+	assert.Equal(t, "400", wallet.Balance.String())
+	assert.Equal(t, "0", wallet.HeldBalance.String())
+
+	var histories []models.BalanceHistory
+	db.Where("transaction_id = ?", withdrawal.ID).Order("id asc").Find(&histories)
+	assert.Len(t, histories, 3)
+	assert.Equal(t, models.ChangeTypeHold, histories[0].ChangeType)
+	assert.Equal(t, models.ChangeTypeSettle, histories[2].ChangeType)
+}
+
+// TestWithdrawal_Fail_ReleasesHold verifies failed withdrawals return the held funds
+func TestWithdrawal_Fail_ReleasesHold(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+
+	walletRepo := repositories.NewWalletRepository()
+	service := NewFundingService(walletRepo, repositories.NewTransactionRepository())
+
+	withdrawal, err := service.CreateWithdrawal(alice.ID, currency.ID, decimal.NewFromInt(300), "0xabc")
+	assert.NoError(t, err)
+	_, err = service.MarkProcessing(withdrawal.Hash)
+	assert.NoError(t, err)
+
+	failed, err := service.Fail(withdrawal.Hash, "chain rejected")
+	assert.NoError(t, err)
+	assert.Equal(t, models.TxStatusFailed, failed.Status)
+	assert.Equal(t, "chain rejected", failed.FailReason)
+
+	wallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, currency.ID)
+	assert.Equal(t, "1000", wallet.Balance.String())
+	assert.Equal(t, "0", wallet.HeldBalance.String())
+
+	// Final states cannot move again
+	_, err = service.Complete(withdrawal.Hash)
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+}
+
+// TestWithdrawal_Cancel_OnlyOwnerWhilePending verifies users can only cancel their own pending withdrawals
+func TestWithdrawal_Cancel_OnlyOwnerWhilePending(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+
+	walletRepo := repositories.NewWalletRepository()
+	service := NewFundingService(walletRepo, repositories.NewTransactionRepository())
+
+	withdrawal, err := service.CreateWithdrawal(alice.ID, currency.ID, decimal.NewFromInt(300), "0xabc")
+	assert.NoError(t, err)
+
+	_, err = service.Cancel(bob.ID, models.TxTypeWithdrawal, withdrawal.Hash)
+	assert.ErrorIs(t, err, ErrTransactionNotFound)
+
+	_, err = service.Cancel(alice.ID, models.TxTypeDeposit, withdrawal.Hash)
+	assert.ErrorIs(t, err, ErrTransactionNotFound)
+
+	cancelled, err := service.Cancel(alice.ID, models.TxTypeWithdrawal, withdrawal.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TxStatusCancelled, cancelled.Status)
+
+	wallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, currency.ID)
+	assert.Equal(t, "1000", wallet.Balance.String())
+	assert.Equal(t, "0", wallet.HeldBalance.String())
+}
+
+// TestWithdrawal_Fail_InsufficientBalance verifies withdrawals cannot exceed the available balance
+func TestWithdrawal_Fail_InsufficientBalance(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 100)
+
+	service := NewFundingService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+
+	_, err := service.CreateWithdrawal(alice.ID, currency.ID, decimal.NewFromInt(101), "0xabc")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "insufficient balance")
+
+	var count int64
+	db.Model(&models.Transaction{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+// TestFunding_Fail_InactiveCurrency verifies deposits and withdrawals cannot be created in a deactivated currency
+func TestFunding_Fail_InactiveCurrency(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "OLD")
+	alice := test.CreateTestUser(db, "alice")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 100)
+	db.Model(currency).Update("is_active", false)
+
+	service := NewFundingService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	_, err := service.CreateDeposit(alice.ID, currency.ID, decimal.NewFromInt(10), "bank-ref-1")
+	assert.ErrorIs(t, err, ErrCurrencyInactive)
+	_, err = service.CreateWithdrawal(alice.ID, currency.ID, decimal.NewFromInt(10), "addr-1")
+	assert.ErrorIs(t, err, ErrCurrencyInactive)
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTransferIdempotent_ReplayReturnsOriginalResult verifies retries do not move money twice
+func TestTransferIdempotent_ReplayReturnsOriginalResult(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	walletRepo := repositories.NewWalletRepository()
+	service := NewTransactionService(walletRepo, repositories.NewTransactionRepository())
+	req := &models.TransferRequest{FromUserID: alice.ID, ToUserID: bob.ID, CurrencyID: currency.ID, Amount: decimal.NewFromInt(100)}
+
+	first, replayed, err := service.TransferIdempotent("key-1", req)
+	assert.NoError(t, err)
+	assert.False(t, replayed)
+
+	// Same amount written with a different precision is still the same request
+	retry := *req
+	retry.Amount = decimal.RequireFromString("100.00")
+	second, replayed, err := service.TransferIdempotent("key-1", &retry)
+	assert.NoError(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, first.Transaction.Hash, second.Transaction.Hash)
+
+	aliceWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, currency.ID)
+	assert.Equal(t, "900", aliceWallet.Balance.String())
+
+	var count int64
+	db.Model(&models.Transaction{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+// TestTransferIdempotent_Fail_KeyReusedWithDifferentBody verifies a key cannot be reused for another request
+func TestTransferIdempotent_Fail_KeyReusedWithDifferentBody(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	service := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	req := &models.TransferRequest{FromUserID: alice.ID, ToUserID: bob.ID, CurrencyID: currency.ID, Amount: decimal.NewFromInt(100)}
+
+	_, _, err := service.TransferIdempotent("key-1", req)
+	assert.NoError(t, err)
+
+	changed := *req
+	changed.Amount = decimal.NewFromInt(200)
+	_, _, err = service.TransferIdempotent("key-1", &changed)
+	assert.ErrorIs(t, err, ErrIdempotencyKeyMismatch)
+}
+
+// TestTransferIdempotent_FailedTransferCanBeRetried verifies failures do not consume the key
+func TestTransferIdempotent_FailedTransferCanBeRetried(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	aliceWallet := test.CreateTestWallet(db, alice.ID, currency.ID, 50)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	service := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	req := &models.TransferRequest{FromUserID: alice.ID, ToUserID: bob.ID, CurrencyID: currency.ID, Amount: decimal.NewFromInt(100)}
+
+	_, _, err := service.TransferIdempotent("key-1", req)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "insufficient balance")
+
+	db.Model(aliceWallet).Update("balance", decimal.NewFromInt(500))
+
+	response, replayed, err := service.TransferIdempotent("key-1", req)
+	assert.NoError(t, err)
+	assert.False(t, replayed)
+	assert.Equal(t, "completed", response.Transaction.Status)
+}
+
+// TestTransferIdempotent_ExpiredKeyCanBeReused verifies keys are only retained for the TTL
+func TestTransferIdempotent_ExpiredKeyCanBeReused(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	service := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	req := &models.TransferRequest{FromUserID: alice.ID, ToUserID: bob.ID, CurrencyID: currency.ID, Amount: decimal.NewFromInt(100)}
+
+	first, _, err := service.TransferIdempotent("key-1", req)
+	assert.NoError(t, err)
+
+	// Age the stored key past the retention window
+	db.Model(&models.IdempotencyKey{}).Where("user_id = ?", alice.ID).
+		Update("expires_at", time.Now().Add(-time.Minute))
+
+	second, replayed, err := service.TransferIdempotent("key-1", req)
+	assert.NoError(t, err)
+	assert.False(t, replayed)
+	assert.NotEqual(t, first.Transaction.Hash, second.Transaction.Hash)
+}
+
+// failingIdempotencyRepo simulates a database error while looking up a key
+type failingIdempotencyRepo struct {
+	repositories.IIdempotency
+}
+
+func (failingIdempotencyRepo) FindByUserAndKey(userID uint, key string, now time.Time) (*models.IdempotencyKey, error) {
+	return nil, errors.New("connection refused")
+}
+
+// TestTransferIdempotent_Fail_LookupErrorDoesNotTransfer verifies a failed key lookup is not mistaken for a new key
+func TestTransferIdempotent_Fail_LookupErrorDoesNotTransfer(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	service := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	service.idempotencyRepo = failingIdempotencyRepo{repositories.NewIdempotencyRepository()}
+	req := &models.TransferRequest{FromUserID: alice.ID, ToUserID: bob.ID, CurrencyID: currency.ID, Amount: decimal.NewFromInt(100)}
+
+	_, _, err := service.TransferIdempotent("key-1", req)
+	assert.EqualError(t, err, "connection refused")
+
+	var count int64
+	db.Model(&models.Transaction{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"sort"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnbalancedEntry = errors.New("ledger entry is not balanced")
+	ErrLedgerMismatch  = errors.New("wallet balance does not match ledger")
+)
+
+// ledgerLeg 描述分錄中的一筆借方或貸方
+// wallet 不為 nil 時過帳到錢包帳戶（held 決定可用或凍結帳戶），否則過帳到 system 帳戶
+type ledgerLeg struct {
+	wallet     *models.Wallet
+	held       bool
+	system     string
+	currencyID uint
+	direction  string
+	amount     decimal.Decimal
+}
+
+func walletDebit(wallet *models.Wallet, amount decimal.Decimal) ledgerLeg {
+	return ledgerLeg{wallet: wallet, currencyID: wallet.CurrencyID, direction: models.PostingDebit, amount: amount}
+}
+
+func walletCredit(wallet *models.Wallet, amount decimal.Decimal) ledgerLeg {
+	return ledgerLeg{wallet: wallet, currencyID: wallet.CurrencyID, direction: models.PostingCredit, amount: amount}
+}
+
+func heldDebit(wallet *models.Wallet, amount decimal.Decimal) ledgerLeg {
+	return ledgerLeg{wallet: wallet, held: true, currencyID: wallet.CurrencyID, direction: models.PostingDebit, amount: amount}
+}
+
+func heldCredit(wallet *models.Wallet, amount decimal.Decimal) ledgerLeg {
+	return ledgerLeg{wallet: wallet, held: true, currencyID: wallet.CurrencyID, direction: models.PostingCredit, amount: amount}
+}
+
+func systemDebit(kind string, currencyID uint, amount decimal.Decimal) ledgerLeg {
+	return ledgerLeg{system: kind, currencyID: currencyID, direction: models.PostingDebit, amount: amount}
+}
+
+func systemCredit(kind string, currencyID uint, amount decimal.Decimal) ledgerLeg {
+	return ledgerLeg{system: kind, currencyID: currencyID, direction: models.PostingCredit, amount: amount}
+}
+
+func (l ledgerLeg) code() string {
+	if l.wallet != nil {
+		return models.WalletAccountCode(l.wallet.ID, l.held)
+	}
+	return models.SystemAccountCode(l.system, l.currencyID)
+}
+
+// signed 回傳此筆對帳戶餘額的影響（credit 為正，debit 為負）
+func (l ledgerLeg) signed() decimal.Decimal {
+	if l.direction == models.PostingCredit {
+		return l.amount
+	}
+	return l.amount.Neg()
+}
+
+// LedgerService 複式記帳
+// 每筆分錄的借貸在每個幣種都必須平衡，錢包餘額在同一個 DB 交易中與帳戶餘額核對
+type LedgerService struct {
+	ledgerRepo repositories.ILedger
+}
+
+func NewLedgerService() *LedgerService {
+	return &LedgerService{
+		ledgerRepo: repositories.NewLedgerRepository(),
+	}
+}
+
+// Post 在既有的 DB 交易中過帳一筆分錄
+// 必須在錢包餘額更新之後呼叫：過帳完成後，涉及的錢包餘額必須與帳戶餘額一致，否則回傳 ErrLedgerMismatch
+func (s *LedgerService) Post(tx *gorm.DB, transactionID uint, entryType string, legs ...ledgerLeg) error {
+	if err := validateLegs(legs); err != nil {
+		return err
+	}
+
+	// 本次分錄對每個帳戶的淨影響，用於推算新帳戶的期初餘額
+	deltas := make(map[string]decimal.Decimal)
+	legByCode := make(map[string]ledgerLeg)
+	var codes []string
+	for _, leg := range legs {
+		code := leg.code()
+		if _, ok := legByCode[code]; !ok {
+			legByCode[code] = leg
+			codes = append(codes, code)
+		}
+		deltas[code] = deltas[code].Add(leg.signed())
+	}
+
+	// 依帳戶代碼排序後再鎖定，方向相反的分錄（例如 X→Y 與 Y→X 兌換）才不會互相等待造成 deadlock
+	sort.Strings(codes)
+	accounts := make(map[string]*models.LedgerAccount)
+	for _, code := range codes {
+		account, err := s.resolveAccount(tx, legByCode[code], deltas[code])
+		if err != nil {
+			return err
+		}
+		accounts[code] = account
+	}
+
+	entry := &models.JournalEntry{TransactionID: transactionID, EntryType: entryType}
+	for _, leg := range legs {
+		account := accounts[leg.code()]
+		entry.Postings = append(entry.Postings, models.LedgerPosting{
+			AccountID:  account.ID,
+			CurrencyID: leg.currencyID,
+			Direction:  leg.direction,
+			Amount:     leg.amount,
+		})
+	}
+	if err := s.ledgerRepo.CreateJournalEntry(entry, tx); err != nil {
+		return err
+	}
+
+	for _, code := range codes {
+		account := accounts[code]
+		account.Balance = account.Balance.Add(deltas[code])
+		if err := s.ledgerRepo.UpdateAccountBalance(account, tx); err != nil {
+			return err
+		}
+	}
+
+	// 核對錢包餘額
+	for _, leg := range legs {
+		if leg.wallet == nil {
+			continue
+		}
+		expected := leg.wallet.Balance
+		if leg.held {
+			expected = leg.wallet.HeldBalance
+		}
+		if !accounts[leg.code()].Balance.Equal(expected) {
+			return fmt.Errorf("%w: %s", ErrLedgerMismatch, leg.code())
+		}
+	}
+
+	return nil
+}
+
+// resolveAccount 鎖定帳戶，帳戶不存在時建立
+// 新的錢包帳戶會先過帳一筆期初分錄，讓帳戶餘額等於導入 ledger 前的錢包餘額
+func (s *LedgerService) resolveAccount(tx *gorm.DB, leg ledgerLeg, delta decimal.Decimal) (*models.LedgerAccount, error) {
+	code := leg.code()
+	if account, err := s.ledgerRepo.FindAccountByCodeWithTx(code, tx); err == nil {
+		return account, nil
+	}
+
+	account := &models.LedgerAccount{Code: code, CurrencyID: leg.currencyID, Balance: decimal.Zero}
+	if leg.wallet != nil {
+		walletID := leg.wallet.ID
+		account.WalletID = &walletID
+		account.Kind = models.LedgerKindWalletAvailable
+		if leg.held {
+			account.Kind = models.LedgerKindWalletHeld
+		}
+	} else {
+		account.Kind = leg.system
+	}
+
+	created, err := s.ledgerRepo.CreateAccountIfNotExists(account, tx)
+	if err != nil {
+		return nil, err
+	}
+	if created && leg.wallet != nil {
+		current := leg.wallet.Balance
+		if leg.held {
+			current = leg.wallet.HeldBalance
+		}
+		if err := s.postOpening(tx, leg, current.Sub(delta)); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.ledgerRepo.FindAccountByCodeWithTx(code, tx)
+}
+
+// postOpening 將錢包的期初餘額從 system_opening 帳戶轉入
+func (s *LedgerService) postOpening(tx *gorm.DB, leg ledgerLeg, opening decimal.Decimal) error {
+	if opening.IsZero() {
+		return nil
+	}
+
+	walletLeg := ledgerLeg{wallet: leg.wallet, held: leg.held, currencyID: leg.currencyID, direction: models.PostingCredit, amount: opening}
+	openingLeg := systemDebit(models.LedgerKindOpening, leg.currencyID, opening)
+	if opening.IsNegative() {
+		walletLeg.direction = models.PostingDebit
+		walletLeg.amount = opening.Neg()
+		openingLeg = systemCredit(models.LedgerKindOpening, leg.currencyID, opening.Neg())
+	}
+
+	walletAccount, err := s.ledgerRepo.FindAccountByCodeWithTx(walletLeg.code(), tx)
+	if err != nil {
+		return err
+	}
+	openingAccount, err := s.resolveAccount(tx, openingLeg, decimal.Zero)
+	if err != nil {
+		return err
+	}
+
+	entry := &models.JournalEntry{
+		EntryType: models.JournalOpening,
+		Postings: []models.LedgerPosting{
+			{AccountID: walletAccount.ID, CurrencyID: leg.currencyID, Direction: walletLeg.direction, Amount: walletLeg.amount},
+			{AccountID: openingAccount.ID, CurrencyID: leg.currencyID, Direction: openingLeg.direction, Amount: openingLeg.amount},
+		},
+	}
+	if err := s.ledgerRepo.CreateJournalEntry(entry, tx); err != nil {
+		return err
+	}
+
+	walletAccount.Balance = walletAccount.Balance.Add(walletLeg.signed())
+	if err := s.ledgerRepo.UpdateAccountBalance(walletAccount, tx); err != nil {
+		return err
+	}
+	openingAccount.Balance = openingAccount.Balance.Add(openingLeg.signed())
+	return s.ledgerRepo.UpdateAccountBalance(openingAccount, tx)
+}
+
+// WalletLedgerBalances 由過帳明細重新計算錢包的可用與凍結餘額
+func (s *LedgerService) WalletLedgerBalances(walletID uint) (available decimal.Decimal, held decimal.Decimal, err error) {
+	accounts, err := s.ledgerRepo.GetAccountsByWalletID(walletID)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+
+	for _, account := range accounts {
+		balance, err := s.ledgerRepo.SumPostings(account.ID)
+		if err != nil {
+			return decimal.Zero, decimal.Zero, err
+		}
+		if account.Kind == models.LedgerKindWalletHeld {
+			held = held.Add(balance)
+		} else {
+			available = available.Add(balance)
+		}
+	}
+	return available, held, nil
+}
+
+// validateLegs 檢查金額為正，且每個幣種的借方總額等於貸方總額
+func validateLegs(legs []ledgerLeg) error {
+	if len(legs) < 2 {
+		return ErrUnbalancedEntry
+	}
+
+	totals := make(map[uint]decimal.Decimal)
+	for _, leg := range legs {
+		if !leg.amount.IsPositive() {
+			return fmt.Errorf("%w: posting amount must be positive", ErrUnbalancedEntry)
+		}
+		totals[leg.currencyID] = totals[leg.currencyID].Add(leg.signed())
+	}
+	for currencyID, total := range totals {
+		if !total.IsZero() {
+			return fmt.Errorf("%w: currency %d is off by %s", ErrUnbalancedEntry, currencyID, total.String())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"sort"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// assertLedgerBalanced verifies every currency's postings sum to zero
+func assertLedgerBalanced(t *testing.T, db *gorm.DB) {
+	var postings []models.LedgerPosting
+	db.Find(&postings)
+	assert.NotEmpty(t, postings)
+
+	totals := make(map[uint]decimal.Decimal)
+	for _, posting := range postings {
+		if posting.Direction == models.PostingCredit {
+			totals[posting.CurrencyID] = totals[posting.CurrencyID].Add(posting.Amount)
+		} else {
+			totals[posting.CurrencyID] = totals[posting.CurrencyID].Sub(posting.Amount)
+		}
+	}
+	for currencyID, total := range totals {
+		assert.True(t, total.IsZero(), "currency %d postings sum to %s", currencyID, total)
+	}
+}
+
+// TestLedger_TransferPostsBalancedEntry verifies transfers write a debit and a credit posting
+func TestLedger_TransferPostsBalancedEntry(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	aliceWallet := test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+	bobWallet := test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	service := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	assert.NoError(t, service.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(300)))
+	assert.NoError(t, service.Transfer(bob.ID, alice.ID, currency.ID, decimal.NewFromInt(100)))
+
+	var entries []models.JournalEntry
+	db.Preload("Postings").Where("entry_type = ?", models.JournalTransfer).Find(&entries)
+	assert.Len(t, entries, 2)
+	assert.Len(t, entries[0].Postings, 2)
+
+	// Alice's pre-ledger balance was brought in with an opening entry
+	var openings []models.JournalEntry
+	db.Where("entry_type = ?", models.JournalOpening).Find(&openings)
+	assert.Len(t, openings, 1)
+
+	ledger := NewLedgerService()
+	available, held, err := ledger.WalletLedgerBalances(aliceWallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "800", available.String())
+	assert.True(t, held.IsZero())
+
+	available, _, err = ledger.WalletLedgerBalances(bobWallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "200", available.String())
+
+	assertLedgerBalanced(t, db)
+}
+
+// TestLedger_FundingFlowsUseSystemAccounts verifies deposits and withdrawals post against system accounts
+func TestLedger_FundingFlowsUseSystemAccounts(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	wallet := test.CreateTestWallet(db, alice.ID, currency.ID, 0)
+
+	service := NewFundingService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+
+	deposit, err := service.CreateDeposit(alice.ID, currency.ID, decimal.NewFromInt(500), "ref")
+	assert.NoError(t, err)
+	_, err = service.MarkProcessing(deposit.Hash)
+	assert.NoError(t, err)
+	_, err = service.Complete(deposit.Hash)
+	assert.NoError(t, err)
+
+	withdrawal, err := service.CreateWithdrawal(alice.ID, currency.ID, decimal.NewFromInt(200), "0xabc")
+	assert.NoError(t, err)
+
+	ledger := NewLedgerService()
+	available, held, err := ledger.WalletLedgerBalances(wallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "300", available.String())
+	assert.Equal(t, "200", held.String())
+
+	_, err = service.MarkProcessing(withdrawal.Hash)
+	assert.NoError(t, err)
+	_, err = service.Complete(withdrawal.Hash)
+	assert.NoError(t, err)
+
+	available, held, err = ledger.WalletLedgerBalances(wallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "300", available.String())
+	assert.True(t, held.IsZero())
+
+	var deposits, withdrawals models.LedgerAccount
+	db.Where("code = ?", models.SystemAccountCode(models.LedgerKindDeposits, currency.ID)).First(&deposits)
+	db.Where("code = ?", models.SystemAccountCode(models.LedgerKindWithdrawals, currency.ID)).First(&withdrawals)
+	assert.Equal(t, "-500", deposits.Balance.String())
+	assert.Equal(t, "200", withdrawals.Balance.String())
+
+	assertLedgerBalanced(t, db)
+}
+
+// TestLedger_Fail_WalletDriftDetected verifies a wallet edited outside the ledger blocks further postings
+func TestLedger_Fail_WalletDriftDetected(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	aliceWallet := test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	walletRepo := repositories.NewWalletRepository()
+	service := NewTransactionService(walletRepo, repositories.NewTransactionRepository())
+	assert.NoError(t, service.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(100)))
+
+	// Someone edits the balance directly in the database
+	db.Model(aliceWallet).Update("balance", decimal.NewFromInt(5000))
+
+	err := service.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(100))
+	assert.ErrorIs(t, err, ErrLedgerMismatch)
+
+	// The failed transfer was rolled back
+	wallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, currency.ID)
+	assert.Equal(t, "5000", wallet.Balance.String())
+	var count int64
+	db.Model(&models.Transaction{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+// TestLedger_Fail_UnbalancedEntry verifies entries whose debits and credits differ are rejected
+func TestLedger_Fail_UnbalancedEntry(t *testing.T) {
+	err := validateLegs([]ledgerLeg{
+		systemDebit(models.LedgerKindDeposits, 1, decimal.NewFromInt(100)),
+		systemCredit(models.LedgerKindFees, 1, decimal.NewFromInt(99)),
+	})
+	assert.ErrorIs(t, err, ErrUnbalancedEntry)
+
+	// Balanced amounts in different currencies do not offset each other
+	err = validateLegs([]ledgerLeg{
+		systemDebit(models.LedgerKindDeposits, 1, decimal.NewFromInt(100)),
+		systemCredit(models.LedgerKindDeposits, 2, decimal.NewFromInt(100)),
+	})
+	assert.ErrorIs(t, err, ErrUnbalancedEntry)
+
+	err = validateLegs([]ledgerLeg{
+		systemDebit(models.LedgerKindDeposits, 1, decimal.Zero),
+		systemCredit(models.LedgerKindFees, 1, decimal.Zero),
+	})
+	assert.ErrorIs(t, err, ErrUnbalancedEntry)
+
+	err = validateLegs([]ledgerLeg{
+		systemDebit(models.LedgerKindDeposits, 1, decimal.NewFromInt(100)),
+		systemCredit(models.LedgerKindFees, 1, decimal.NewFromInt(40)),
+		systemCredit(models.LedgerKindWithdrawals, 1, decimal.NewFromInt(60)),
+	})
+	assert.NoError(t, err)
+}
+
+// lockRecordingLedgerRepo records the order in which accounts are locked
+type lockRecordingLedgerRepo struct {
+	repositories.ILedger
+	locked []string
+}
+
+func (r *lockRecordingLedgerRepo) FindAccountByCodeWithTx(code string, tx ...*gorm.DB) (*models.LedgerAccount, error) {
+	r.locked = append(r.locked, code)
+	return r.ILedger.FindAccountByCodeWithTx(code, tx...)
+}
+
+// TestLedger_LocksAccountsInCodeOrder verifies accounts are locked sorted by code regardless of leg order
+func TestLedger_LocksAccountsInCodeOrder(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	repo := &lockRecordingLedgerRepo{ILedger: repositories.NewLedgerRepository()}
+	ledger := &LedgerService{ledgerRepo: repo}
+
+	// Legs arrive in the opposite order of their codes, like a swap crediting currency 2 before debiting currency 1
+	tx := db.Begin()
+	err := ledger.Post(tx, 0, models.JournalSwap,
+		systemCredit(models.LedgerKindExchange, 2, decimal.NewFromInt(100)),
+		systemDebit(models.LedgerKindDeposits, 2, decimal.NewFromInt(100)),
+		systemCredit(models.LedgerKindExchange, 1, decimal.NewFromInt(50)),
+		systemDebit(models.LedgerKindDeposits, 1, decimal.NewFromInt(50)),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Commit().Error)
+
+	assert.NotEmpty(t, repo.locked)
+	assert.True(t, sort.StringsAreSorted(repo.locked), "accounts locked in order %v", repo.locked)
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTransferLimitNotFound       = errors.New("transfer limit not found")
+	ErrInvalidTransferLimit        = errors.New("invalid transfer limit")
+	ErrSingleTransferLimitExceeded = errors.New("amount exceeds the single transfer limit")
+	ErrDailyLimitExceeded          = errors.New("daily transfer limit exceeded")
+	ErrMonthlyLimitExceeded        = errors.New("monthly transfer limit exceeded")
+	ErrVelocityLimitExceeded       = errors.New("too many transfers in the last hour")
+)
+
+// LimitService 管理轉帳限額並在轉帳時檢查
+// 幣種預設值存在 user_id = 0 的設定中，用戶專屬設定會整筆取代預設值
+type LimitService struct {
+	limitRepo    repositories.ITransferLimit
+	txRepo       repositories.ITransaction
+	userRepo     repositories.IUser
+	currencyRepo repositories.ICurrency
+	now          func() time.Time
+}
+
+func NewLimitService() *LimitService {
+	return &LimitService{
+		limitRepo:    repositories.NewTransferLimitRepository(),
+		txRepo:       repositories.NewTransactionRepository(),
+		userRepo:     repositories.NewUserRepository(),
+		currencyRepo: repositories.NewCurrencyRepository(),
+		now:          time.Now,
+	}
+}
+
+// GetCurrencyLimit 取得幣種的預設限額
+func (s *LimitService) GetCurrencyLimit(currencyID uint) (*models.TransferLimit, error) {
+	limit, err := s.limitRepo.FindLimit(0, currencyID)
+	if err != nil {
+		return nil, ErrTransferLimitNotFound
+	}
+	return limit, nil
+}
+
+// SetCurrencyLimit 設定幣種的預設限額
+func (s *LimitService) SetCurrencyLimit(currencyID uint, req models.TransferLimitRequest) (*models.TransferLimit, error) {
+	return s.setLimit(0, currencyID, req)
+}
+
+// DeleteCurrencyLimit 移除幣種的預設限額
+func (s *LimitService) DeleteCurrencyLimit(currencyID uint) error {
+	return s.deleteLimit(0, currencyID)
+}
+
+// GetUserLimits 取得用戶所有的專屬限額
+func (s *LimitService) GetUserLimits(userID uint) ([]models.TransferLimit, error) {
+	if _, err := s.userRepo.GetUserByID(userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.limitRepo.FindLimitsByUserID(userID)
+}
+
+// SetUserLimit 設定用戶在某幣種的專屬限額，取代幣種預設值
+func (s *LimitService) SetUserLimit(userID, currencyID uint, req models.TransferLimitRequest) (*models.TransferLimit, error) {
+	if _, err := s.userRepo.GetUserByID(userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.setLimit(userID, currencyID, req)
+}
+
+// DeleteUserLimit 移除用戶的專屬限額，恢復使用幣種預設值
+func (s *LimitService) DeleteUserLimit(userID, currencyID uint) error {
+	return s.deleteLimit(userID, currencyID)
+}
+
+func (s *LimitService) setLimit(userID, currencyID uint, req models.TransferLimitRequest) (*models.TransferLimit, error) {
+	if _, err := s.currencyRepo.FindCurrencyByID(currencyID); err != nil {
+		return nil, ErrCurrencyNotFound
+	}
+	for _, v := range []decimal.Decimal{req.MaxSingleAmount, req.DailyLimit, req.MonthlyLimit} {
+		if v.IsNegative() {
+			return nil, fmt.Errorf("%w: limits must not be negative", ErrInvalidTransferLimit)
+		}
+	}
+	if req.MaxTransfersPerHour < 0 {
+		return nil, fmt.Errorf("%w: max_transfers_per_hour must not be negative", ErrInvalidTransferLimit)
+	}
+
+	limit := &models.TransferLimit{
+		UserID:              userID,
+		CurrencyID:          currencyID,
+		MaxSingleAmount:     req.MaxSingleAmount,
+		DailyLimit:          req.DailyLimit,
+		MonthlyLimit:        req.MonthlyLimit,
+		MaxTransfersPerHour: req.MaxTransfersPerHour,
+	}
+	if err := s.limitRepo.UpsertLimit(limit); err != nil {
+		return nil, err
+	}
+	return s.limitRepo.FindLimit(userID, currencyID)
+}
+
+func (s *LimitService) deleteLimit(userID, currencyID uint) error {
+	deleted, err := s.limitRepo.DeleteLimit(userID, currencyID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrTransferLimitNotFound
+	}
+	return nil
+}
+
+// effectiveLimit 取得套用在用戶的限額：用戶專屬設定優先，其次為幣種預設，都沒有時回傳 nil
+func (s *LimitService) effectiveLimit(tx *gorm.DB, userID, currencyID uint) (*models.TransferLimit, error) {
+	for _, id := range []uint{userID, 0} {
+		limit, err := s.limitRepo.FindLimit(id, currencyID, tx)
+		if err == nil {
+			return limit, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// check 檢查這筆轉帳是否超過限額
+// 必須在鎖定轉出錢包之後呼叫，同一用戶同幣種的轉帳才會依序檢查，不會同時通過
+func (s *LimitService) check(tx *gorm.DB, userID, currencyID uint, amount decimal.Decimal) error {
+	limit, err := s.effectiveLimit(tx, userID, currencyID)
+	if err != nil || limit == nil {
+		return err
+	}
+
+	if limit.MaxSingleAmount.IsPositive() && amount.GreaterThan(limit.MaxSingleAmount) {
+		return fmt.Errorf("%w of %s", ErrSingleTransferLimitExceeded, limit.MaxSingleAmount)
+	}
+
+	now := s.now().UTC()
+
+	if limit.MaxTransfersPerHour > 0 {
+		_, count, err := s.txRepo.SumOutgoingTransfers(userID, currencyID, now.Add(-time.Hour), tx)
+		if err != nil {
+			return err
+		}
+		if count >= int64(limit.MaxTransfersPerHour) {
+			return fmt.Errorf("%w (max %d)", ErrVelocityLimitExceeded, limit.MaxTransfersPerHour)
+		}
+	}
+
+	windows := []struct {
+		cap   decimal.Decimal
+		since time.Time
+		err   error
+	}{
+		{limit.DailyLimit, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), ErrDailyLimitExceeded},
+		{limit.MonthlyLimit, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), ErrMonthlyLimitExceeded},
+	}
+	for _, w := range windows {
+		if !w.cap.IsPositive() {
+			continue
+		}
+		used, _, err := s.txRepo.SumOutgoingTransfers(userID, currencyID, w.since, tx)
+		if err != nil {
+			return err
+		}
+		if used.Add(amount).GreaterThan(w.cap) {
+			remaining := decimal.Max(w.cap.Sub(used), decimal.Zero)
+			return fmt.Errorf("%w: %s remaining", w.err, remaining)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// transferAt 執行轉帳並把交易時間改成 at，模擬過去的轉帳
+func transferAt(t *testing.T, db *gorm.DB, service *TransactionService, fromID, toID, currencyID uint, amount string, at time.Time) {
+	t.Helper()
+	transaction, err := service.TransferWithResult(fromID, toID, currencyID, dec(amount))
+	if assert.NoError(t, err) {
+		assert.NoError(t, db.Model(transaction).Update("created_at", at).Error)
+	}
+}
+
+// TestTransferLimits_SingleDailyAndMonthly verifies each amount cap with the currency defaults
+func TestTransferLimits_SingleDailyAndMonthly(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 10000)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	txService.limits.now = func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) }
+	_, err := txService.limits.SetCurrencyLimit(usdt.ID, models.TransferLimitRequest{
+		MaxSingleAmount: dec("1000"),
+		DailyLimit:      dec("1500"),
+		MonthlyLimit:    dec("3000"),
+	})
+	assert.NoError(t, err)
+
+	_, err = txService.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("1000.5"))
+	assert.ErrorIs(t, err, ErrSingleTransferLimitExceeded)
+
+	// 本月較早的轉帳只計入月限額
+	transferAt(t, db, txService, alice.ID, bob.ID, usdt.ID, "1000", time.Date(2026, 10, 2, 9, 0, 0, 0, time.UTC))
+	transferAt(t, db, txService, alice.ID, bob.ID, usdt.ID, "1000", time.Date(2026, 10, 16, 23, 59, 0, 0, time.UTC))
+	// 上個月的轉帳不計入
+	transferAt(t, db, txService, alice.ID, bob.ID, usdt.ID, "1000", time.Date(2026, 9, 30, 23, 0, 0, 0, time.UTC))
+
+	transferAt(t, db, txService, alice.ID, bob.ID, usdt.ID, "900", time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC))
+
+	_, err = txService.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("700"))
+	assert.ErrorIs(t, err, ErrDailyLimitExceeded)
+
+	_, err = txService.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("200"))
+	assert.ErrorIs(t, err, ErrMonthlyLimitExceeded)
+
+	_, err = txService.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("100"))
+	assert.NoError(t, err)
+
+	// 被拒絕的轉帳不影響餘額
+	wallet, _ := repositories.NewWalletRepository().GetWalletByUserIDAndCurrency(alice.ID, usdt.ID)
+	assert.Equal(t, "6000", wallet.Balance.String())
+}
+
+// TestTransferLimits_Velocity verifies the rolling hourly transfer count
+func TestTransferLimits_Velocity(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 10000)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	txService.limits.now = func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) }
+	_, err := txService.limits.SetCurrencyLimit(usdt.ID, models.TransferLimitRequest{MaxTransfersPerHour: 2})
+	assert.NoError(t, err)
+
+	transferAt(t, db, txService, alice.ID, bob.ID, usdt.ID, "1", time.Date(2026, 10, 17, 10, 59, 0, 0, time.UTC)) // 超過一小時，不計入
+	transferAt(t, db, txService, alice.ID, bob.ID, usdt.ID, "1", time.Date(2026, 10, 17, 11, 10, 0, 0, time.UTC))
+	transferAt(t, db, txService, alice.ID, bob.ID, usdt.ID, "1", time.Date(2026, 10, 17, 11, 50, 0, 0, time.UTC))
+
+	_, err = txService.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("1"))
+	assert.ErrorIs(t, err, ErrVelocityLimitExceeded)
+
+	// 限額只套用在轉出方
+	_, err = txService.TransferWithResult(bob.ID, alice.ID, usdt.ID, dec("1"))
+	assert.NoError(t, err)
+}
+
+// TestTransferLimits_UserOverrideReplacesDefault verifies admin overrides and falling back after deletion
+func TestTransferLimits_UserOverrideReplacesDefault(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 10000)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	txService.limits.now = func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) }
+	_, err := txService.limits.SetCurrencyLimit(usdt.ID, models.TransferLimitRequest{MaxSingleAmount: dec("100")})
+	assert.NoError(t, err)
+
+	override, err := txService.limits.SetUserLimit(alice.ID, usdt.ID, models.TransferLimitRequest{MaxSingleAmount: dec("5000")})
+	assert.NoError(t, err)
+	assert.True(t, override.IsUserOverride())
+
+	// 再次設定會覆寫而不是新增
+	_, err = txService.limits.SetUserLimit(alice.ID, usdt.ID, models.TransferLimitRequest{MaxSingleAmount: dec("2000")})
+	assert.NoError(t, err)
+	userLimits, err := txService.limits.GetUserLimits(alice.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, userLimits, 1) {
+		assert.Equal(t, "2000", userLimits[0].MaxSingleAmount.String())
+	}
+
+	_, err = txService.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("1500"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, txService.limits.DeleteUserLimit(alice.ID, usdt.ID))
+	assert.ErrorIs(t, txService.limits.DeleteUserLimit(alice.ID, usdt.ID), ErrTransferLimitNotFound)
+
+	_, err = txService.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("1500"))
+	assert.ErrorIs(t, err, ErrSingleTransferLimitExceeded)
+
+	_, err = txService.limits.SetUserLimit(alice.ID, usdt.ID, models.TransferLimitRequest{DailyLimit: dec("-1")})
+	assert.ErrorIs(t, err, ErrInvalidTransferLimit)
+	_, err = txService.limits.SetUserLimit(999, usdt.ID, models.TransferLimitRequest{})
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mini-crypto-wallet-api/internal/events"
+	"mini-crypto-wallet-api/kafka_client"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"gorm.io/gorm"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NotificationService 將 tx.created 事件轉成每位用戶的通知，並提供查詢與已讀狀態
+type NotificationService struct {
+	notificationRepo repositories.INotification
+	now              func() time.Time
+}
+
+func NewNotificationService(notificationRepo repositories.INotification) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		now:              time.Now,
+	}
+}
+
+// HandleTxCreated 是 tx.created 的 consumer handler
+// 訊息可能重複投遞，同一筆交易對同一用戶只會建立一則通知；
+// 同時接受 events.Envelope 與導入 envelope 前的舊格式，無法解析的訊息會被標記為 poison 送到死信 topic
+func (s *NotificationService) HandleTxCreated(ctx context.Context, msg kafka.Message) error {
+	event, err := decodeTxCreated(msg.Value)
+	if err != nil {
+		return kafka_client.Poison(err)
+	}
+	if event.Hash == "" || event.FromUserID == 0 || event.ToUserID == 0 {
+		return kafka_client.Poison(errors.New("tx.created message is missing hash or users"))
+	}
+
+	for _, notification := range notificationsForTxCreated(event) {
+		if _, err := s.notificationRepo.CreateIfAbsent(notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeTxCreated 將 envelope 或舊格式的 tx.created 轉成相同的結構
+func decodeTxCreated(raw []byte) (*kafka_client.TxCreatedMessage, error) {
+	envelope, err := events.Parse(raw)
+	if errors.Is(err, events.ErrNotEnvelope) {
+		var legacy kafka_client.TxCreatedMessage
+		if err := json.Unmarshal(raw, &legacy); err != nil {
+			return nil, fmt.Errorf("decode tx.created: %w", err)
+		}
+		return &legacy, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode tx.created: %w", err)
+	}
+
+	payload, err := envelope.Decode()
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", envelope.Type, err)
+	}
+	switch p := payload.(type) {
+	case *events.TransferPayload:
+		return &kafka_client.TxCreatedMessage{Hash: p.Hash, Type: p.TransactionType, FromUserID: p.FromUserID, ToUserID: p.ToUserID, Amount: p.Amount, Fee: p.Fee}, nil
+	case *events.ReversalPayload:
+		txType := models.TxTypeReversal
+		if envelope.Type == events.TypeRefundCompleted {
+			txType = models.TxTypeRefund
+		}
+		return &kafka_client.TxCreatedMessage{Hash: p.Hash, Type: txType, OriginalHash: p.OriginalHash, FromUserID: p.FromUserID, ToUserID: p.ToUserID, Amount: p.Amount}, nil
+	default:
+		return nil, fmt.Errorf("unexpected %s event on tx.created", envelope.Type)
+	}
+}
+
+// notificationsForTxCreated 依交易類型決定雙方各自收到的通知
+func notificationsForTxCreated(event *kafka_client.TxCreatedMessage) []*models.Notification {
+	amount := event.Amount.String()
+	newNotification := func(userID uint, notificationType string, counterparty uint, message string) *models.Notification {
+		return &models.Notification{
+			UserID:             userID,
+			EventKey:           event.Hash,
+			Type:               notificationType,
+			TransactionHash:    event.Hash,
+			CounterpartyUserID: counterparty,
+			Amount:             event.Amount,
+			Message:            message,
+		}
+	}
+
+	if event.Type == models.TxTypeSwap || event.FromUserID == event.ToUserID {
+		return []*models.Notification{
+			newNotification(event.FromUserID, models.NotificationSwapCompleted, 0, fmt.Sprintf("Swap of %s completed", amount)),
+		}
+	}
+
+	from, to := event.FromUserID, event.ToUserID
+	switch event.Type {
+	case models.TxTypeRefund:
+		return []*models.Notification{
+			newNotification(to, models.NotificationRefundReceived, from, fmt.Sprintf("You were refunded %s by user %d", amount, from)),
+			newNotification(from, models.NotificationRefundSent, to, fmt.Sprintf("You refunded %s to user %d", amount, to)),
+		}
+	case models.TxTypeReversal:
+		return []*models.Notification{
+			newNotification(to, models.NotificationTransferReversed, from, fmt.Sprintf("A transfer to user %d was reversed, %s was returned to you", from, amount)),
+			newNotification(from, models.NotificationTransferReversed, to, fmt.Sprintf("A transfer from user %d was reversed, %s was deducted", to, amount)),
+		}
+	default:
+		return []*models.Notification{
+			newNotification(to, models.NotificationTransferReceived, from, fmt.Sprintf("You received %s from user %d", amount, from)),
+			newNotification(from, models.NotificationTransferSent, to, fmt.Sprintf("You sent %s to user %d", amount, to)),
+		}
+	}
+}
+
+// List 分頁取得用戶的通知，並回傳未讀數量
+func (s *NotificationService) List(userID uint, unreadOnly bool, offset, limit int) ([]models.Notification, int64, int64, error) {
+	notifications, total, err := s.notificationRepo.FindByUserID(userID, unreadOnly, offset, limit)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	unread, err := s.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return notifications, total, unread, nil
+}
+
+// MarkRead 將用戶的一則通知標記為已讀，已讀的通知不變
+func (s *NotificationService) MarkRead(userID, id uint) (*models.Notification, error) {
+	if _, err := s.notificationRepo.FindByIDAndUserID(id, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotificationNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.notificationRepo.MarkRead(id, userID, s.now()); err != nil {
+		return nil, err
+	}
+	return s.notificationRepo.FindByIDAndUserID(id, userID)
+}
+
+// MarkAllRead 將用戶所有未讀通知標記為已讀，回傳更新的數量
+func (s *NotificationService) MarkAllRead(userID uint) (int64, error) {
+	return s.notificationRepo.MarkAllRead(userID, s.now())
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/kafka_client"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// txCreatedFromOutbox turns the pending tx.created outbox events into Kafka messages
+func txCreatedFromOutbox(t *testing.T, db *gorm.DB) []kafka.Message {
+	t.Helper()
+	var events []models.OutboxEvent
+	assert.NoError(t, db.Where("topic = ?", kafka_client.TopicTxCreated).Order("id asc").Find(&events).Error)
+
+	msgs := make([]kafka.Message, len(events))
+	for i, event := range events {
+		msgs[i] = kafka.Message{Topic: event.Topic, Key: []byte(event.EventKey), Value: []byte(event.Payload)}
+	}
+	return msgs
+}
+
+// TestNotification_TransferCreatesOneNotificationPerUser verifies both sides are notified and redelivery is idempotent
+func TestNotification_TransferCreatesOneNotificationPerUser(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	transaction := setupTransfer(t, db)
+	service := NewNotificationService(repositories.NewNotificationRepository())
+
+	msgs := txCreatedFromOutbox(t, db)
+	assert.Len(t, msgs, 1)
+	assert.NoError(t, service.HandleTxCreated(context.Background(), msgs[0]))
+	assert.NoError(t, service.HandleTxCreated(context.Background(), msgs[0]), "redelivered messages are ignored")
+
+	received, total, unread, err := service.List(transaction.ToUserID, false, 0, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, int64(1), unread)
+	assert.Equal(t, models.NotificationTransferReceived, received[0].Type)
+	assert.Equal(t, transaction.Hash, received[0].TransactionHash)
+	assert.Equal(t, transaction.FromUserID, received[0].CounterpartyUserID)
+	assert.Equal(t, "100", received[0].Amount.String())
+
+	sent, total, _, err := service.List(transaction.FromUserID, false, 0, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, models.NotificationTransferSent, sent[0].Type)
+}
+
+// TestNotification_RefundNotifiesOriginalSender verifies the refund types are mapped to each side
+func TestNotification_RefundNotifiesOriginalSender(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	transaction := setupTransfer(t, db)
+	txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	amount := dec("40")
+	_, _, err := txService.Refund(transaction.ToUserID, transaction.Hash, &amount)
+	assert.NoError(t, err)
+
+	service := NewNotificationService(repositories.NewNotificationRepository())
+	for _, msg := range txCreatedFromOutbox(t, db) {
+		assert.NoError(t, service.HandleTxCreated(context.Background(), msg))
+	}
+
+	notifications, total, unread, err := service.List(transaction.FromUserID, false, 0, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, int64(2), unread)
+	assert.Equal(t, models.NotificationRefundReceived, notifications[0].Type)
+	assert.Equal(t, "40", notifications[0].Amount.String())
+	assert.Equal(t, models.NotificationTransferSent, notifications[1].Type)
+
+	notifications, _, _, err = service.List(transaction.ToUserID, false, 0, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, models.NotificationRefundSent, notifications[0].Type)
+}
+
+// TestNotification_InvalidMessageIsPoison verifies malformed events go straight to the dead letter topic
+func TestNotification_InvalidMessageIsPoison(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	service := NewNotificationService(repositories.NewNotificationRepository())
+
+	err := service.HandleTxCreated(context.Background(), kafka.Message{Value: []byte("not json")})
+	assert.True(t, kafka_client.IsPoison(err))
+
+	payload, _ := json.Marshal(kafka_client.TxCreatedMessage{Amount: dec("1")})
+	err = service.HandleTxCreated(context.Background(), kafka.Message{Value: payload})
+	assert.True(t, kafka_client.IsPoison(err))
+
+	var count int64
+	db.Model(&models.Notification{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+// TestNotification_ReadState verifies unread filtering and marking notifications as read
+func TestNotification_ReadState(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	transaction := setupTransfer(t, db)
+	txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	amount := dec("10")
+	_, _, err := txService.Refund(transaction.ToUserID, transaction.Hash, &amount)
+	assert.NoError(t, err)
+
+	service := NewNotificationService(repositories.NewNotificationRepository())
+	for _, msg := range txCreatedFromOutbox(t, db) {
+		assert.NoError(t, service.HandleTxCreated(context.Background(), msg))
+	}
+	userID := transaction.FromUserID
+
+	notifications, _, _, err := service.List(userID, true, 0, 20)
+	assert.NoError(t, err)
+	assert.Len(t, notifications, 2)
+
+	read, err := service.MarkRead(userID, notifications[0].ID)
+	assert.NoError(t, err)
+	assert.True(t, read.IsRead())
+	readAt := *read.ReadAt
+
+	again, err := service.MarkRead(userID, notifications[0].ID)
+	assert.NoError(t, err)
+	assert.True(t, readAt.Equal(*again.ReadAt), "marking twice keeps the first read time")
+
+	unreadOnly, total, unread, err := service.List(userID, true, 0, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, int64(1), unread)
+	assert.Equal(t, notifications[1].ID, unreadOnly[0].ID)
+
+	_, err = service.MarkRead(transaction.ToUserID, notifications[0].ID)
+	assert.ErrorIs(t, err, ErrNotificationNotFound, "users cannot touch other users' notifications")
+
+	updated, err := service.MarkAllRead(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), updated)
+
+	_, _, unread, err = service.List(userID, false, 0, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), unread)
+}
+
+// TestNotification_LegacyMessageStillHandled verifies tx.created messages written before the event envelope are still consumed
+func TestNotification_LegacyMessageStillHandled(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	service := NewNotificationService(repositories.NewNotificationRepository())
+	payload, _ := json.Marshal(kafka_client.TxCreatedMessage{Hash: "legacy-hash", FromUserID: 1, ToUserID: 2, Amount: dec("5")})
+	assert.NoError(t, service.HandleTxCreated(context.Background(), kafka.Message{Value: payload}))
+
+	notifications, _, _, err := service.List(2, false, 0, 20)
+	assert.NoError(t, err)
+	assert.Len(t, notifications, 1)
+	assert.Equal(t, models.NotificationTransferReceived, notifications[0].Type)
+	assert.Equal(t, "legacy-hash", notifications[0].TransactionHash)
+
+	// 未知版本的事件送到死信 topic，等 consumer 升級後再重放
+	future := []byte(`{"event_id":"1","type":"transfer.completed","schema_version":99,"data":{}}`)
+	assert.True(t, kafka_client.IsPoison(service.HandleTxCreated(context.Background(), kafka.Message{Value: future})))
+}
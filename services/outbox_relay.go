@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/internal/eventbus"
+	"mini-crypto-wallet-api/internal/events"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"mini-crypto-wallet-api/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultOutboxBatchSize    = 100
+	defaultOutboxPollInterval = time.Second
+	defaultOutboxBaseBackoff  = time.Second
+	defaultOutboxMaxBackoff   = 5 * time.Minute
+	defaultOutboxLease        = time.Minute
+	maxOutboxErrorLength      = 500
+)
+
+// OutboxRelay 將 outbox_events 中待發送的事件送到 event bus（Kafka、log 或測試用的 in-memory）
+// 發送失敗的事件會以指數退避重試，直到成功為止
+type OutboxRelay struct {
+	outboxRepo   repositories.IOutbox
+	publisher    eventbus.EventPublisher
+	batchSize    int
+	pollInterval time.Duration
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+	lease        time.Duration
+	now          func() time.Time
+}
+
+func NewOutboxRelay(outboxRepo repositories.IOutbox, publisher eventbus.EventPublisher) *OutboxRelay {
+	return &OutboxRelay{
+		outboxRepo:   outboxRepo,
+		publisher:    publisher,
+		batchSize:    defaultOutboxBatchSize,
+		pollInterval: defaultOutboxPollInterval,
+		baseBackoff:  defaultOutboxBaseBackoff,
+		maxBackoff:   defaultOutboxMaxBackoff,
+		lease:        defaultOutboxLease,
+		now:          time.Now,
+	}
+}
+
+// Run 定期處理待發送事件，直到 ctx 被取消
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.ProcessOnce(ctx); err != nil {
+			log.Println("⚠️ Outbox relay error:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessOnce 處理一批到期的事件，回傳成功發送的數量
+// 事件先在短交易中取得租約，發送在交易外進行，每筆結果各自標記；
+// 發送期間不持有 DB 交易與列鎖，租約到期前未處理完的事件會再被取出（at-least-once）
+func (r *OutboxRelay) ProcessOnce(ctx context.Context) (int, error) {
+	events, err := r.claimPending()
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	// 租約到期後事件可能被其他 relay 取走，不再繼續發送
+	ctx, cancel := context.WithTimeout(ctx, r.lease)
+	defer cancel()
+
+	published := 0
+	for _, event := range events {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if err := r.publisher.Publish(ctx, event.Topic, event.EventKey, []byte(event.Payload)); err != nil {
+			attempts := event.Attempts + 1
+			nextAttemptAt := r.now().Add(r.backoff(attempts))
+			if markErr := r.outboxRepo.MarkFailed(event.ID, attempts, nextAttemptAt, utils.TruncateString(err.Error(), maxOutboxErrorLength)); markErr != nil {
+				return published, markErr
+			}
+			continue
+		}
+
+		ok, err := r.outboxRepo.MarkPublished(event.ID, r.now())
+		if err != nil {
+			return published, err
+		}
+		if ok {
+			published++
+		}
+	}
+
+	return published, nil
+}
+
+// claimPending 鎖定到期的事件並把 next_attempt_at 推到租約到期時間後立即 commit
+func (r *OutboxRelay) claimPending() ([]models.OutboxEvent, error) {
+	now := r.now()
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	events, err := r.outboxRepo.FetchPendingEvents(now, r.batchSize, tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	if err := r.outboxRepo.LeaseEvents(ids, now.Add(r.lease), tx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return nil, commitDB.Error
+	}
+	return events, nil
+}
+
+// backoff 計算第 attempts 次失敗後的等待時間
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	return exponentialBackoff(r.baseBackoff, r.maxBackoff, attempts)
+}
+
+// exponentialBackoff 從 base 開始每次失敗加倍，最多等待 max
+func exponentialBackoff(base, max time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
+
+// enqueueEvent 將 payload 包成版本化的 events.Envelope 後寫入 outbox，必須與業務變更在同一個 DB 交易中呼叫
+func enqueueEvent(outboxRepo repositories.IOutbox, topic string, key string, eventType string, payload interface{}, occurredAt time.Time, tx *gorm.DB) error {
+	envelope, err := events.New(eventType, payload, events.WithOccurredAt(occurredAt))
+	if err != nil {
+		return err
+	}
+	return enqueueOutboxEvent(outboxRepo, topic, key, envelope, tx)
+}
+
+// enqueueOutboxEvent 將事件以 JSON 寫入 outbox，必須與業務變更在同一個 DB 交易中呼叫
+func enqueueOutboxEvent(outboxRepo repositories.IOutbox, topic string, key string, msg interface{}, tx *gorm.DB) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return outboxRepo.CreateEvent(&models.OutboxEvent{
+		Topic:    topic,
+		EventKey: key,
+		Payload:  string(payload),
+	}, tx)
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	appevents "mini-crypto-wallet-api/internal/events"
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/kafka_client"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakePublisher is an in-process MessagePublisher that can simulate broker outages
+type fakePublisher struct {
+	mu       sync.Mutex
+	down     bool
+	failure  error // returned while down, defaults to a broker outage
+	messages []fakeMessage
+}
+
+type fakeMessage struct {
+	Topic string
+	Key   string
+	Value []byte
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, topic string, key string, value []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.down {
+		if p.failure != nil {
+			return p.failure
+		}
+		return errors.New("kafka: broker not available")
+	}
+	p.messages = append(p.messages, fakeMessage{Topic: topic, Key: key, Value: value})
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	return nil
+}
+
+func (p *fakePublisher) setDown(down bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.down = down
+}
+
+func (p *fakePublisher) sent() []fakeMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]fakeMessage(nil), p.messages...)
+}
+
+// setupTransfer creates two funded users and performs one transfer between them
+func setupTransfer(t *testing.T, db *gorm.DB) *models.Transaction {
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	txRepo := repositories.NewTransactionRepository()
+	service := NewTransactionService(repositories.NewWalletRepository(), txRepo)
+	assert.NoError(t, service.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(100)))
+
+	txs, err := txRepo.GetTransactionsByUserID(alice.ID)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 1)
+	return &txs[0]
+}
+
+// TestTransfer_WritesOutboxEvent verifies the tx.created event is stored in the same DB transaction
+func TestTransfer_WritesOutboxEvent(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	transaction := setupTransfer(t, db)
+
+	var events []models.OutboxEvent
+	db.Find(&events)
+	assert.Len(t, events, 1)
+	assert.Equal(t, kafka_client.TopicTxCreated, events[0].Topic)
+	assert.Equal(t, transaction.Hash, events[0].EventKey)
+	assert.Equal(t, models.OutboxStatusPending, events[0].Status)
+
+	envelope, err := appevents.Parse([]byte(events[0].Payload))
+	assert.NoError(t, err)
+	assert.Equal(t, appevents.TypeTransferCompleted, envelope.Type)
+	assert.Equal(t, appevents.Producer, envelope.Producer)
+
+	var msg appevents.TransferPayload
+	assert.NoError(t, envelope.DecodeInto(&msg))
+	assert.Equal(t, transaction.Hash, msg.Hash)
+	assert.Equal(t, "100", msg.Amount.String())
+	assert.Equal(t, transaction.CurrencyID, msg.CurrencyID)
+	assert.Equal(t, models.TxStatusCompleted, msg.Status)
+}
+
+// TestTransfer_FailedTransferWritesNoOutboxEvent verifies rolled back transfers never reach the outbox
+func TestTransfer_FailedTransferWritesNoOutboxEvent(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 50)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	service := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	assert.Error(t, service.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(100)))
+
+	var count int64
+	db.Model(&models.OutboxEvent{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+// TestOutboxRelay_PublishesPendingEvents verifies the relay publishes and marks events
+func TestOutboxRelay_PublishesPendingEvents(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	transaction := setupTransfer(t, db)
+	publisher := &fakePublisher{}
+	relay := NewOutboxRelay(repositories.NewOutboxRepository(), publisher)
+
+	published, err := relay.ProcessOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, published)
+
+	sent := publisher.sent()
+	assert.Len(t, sent, 1)
+	assert.Equal(t, kafka_client.TopicTxCreated, sent[0].Topic)
+	assert.Equal(t, transaction.Hash, sent[0].Key)
+
+	var event models.OutboxEvent
+	db.First(&event)
+	assert.Equal(t, models.OutboxStatusPublished, event.Status)
+	assert.NotNil(t, event.PublishedAt)
+
+	// A second pass must not publish the same event again
+	published, err = relay.ProcessOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, published)
+	assert.Len(t, publisher.sent(), 1)
+}
+
+// TestOutboxRelay_RetriesWithBackoffDuringOutage verifies events survive a broker outage
+func TestOutboxRelay_RetriesWithBackoffDuringOutage(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	setupTransfer(t, db)
+	publisher := &fakePublisher{down: true}
+	relay := NewOutboxRelay(repositories.NewOutboxRepository(), publisher)
+	now := time.Now()
+	relay.now = func() time.Time { return now }
+
+	// First attempt fails and schedules a retry after the base backoff
+	published, err := relay.ProcessOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, published)
+
+	var event models.OutboxEvent
+	db.First(&event)
+	assert.Equal(t, models.OutboxStatusPending, event.Status)
+	assert.Equal(t, 1, event.Attempts)
+	assert.Contains(t, event.LastError, "broker not available")
+	assert.WithinDuration(t, now.Add(defaultOutboxBaseBackoff), event.NextAttemptAt, time.Millisecond)
+
+	// Not due yet: the relay must not retry before the backoff expires
+	published, err = relay.ProcessOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, published)
+	db.First(&event)
+	assert.Equal(t, 1, event.Attempts)
+
+	// Second failure doubles the backoff
+	now = now.Add(defaultOutboxBaseBackoff)
+	_, err = relay.ProcessOnce(context.Background())
+	assert.NoError(t, err)
+	db.First(&event)
+	assert.Equal(t, 2, event.Attempts)
+	assert.WithinDuration(t, now.Add(2*defaultOutboxBaseBackoff), event.NextAttemptAt, time.Millisecond)
+
+	// Broker recovers: the event is delivered exactly once
+	publisher.setDown(false)
+	now = now.Add(2 * defaultOutboxBaseBackoff)
+	published, err = relay.ProcessOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Len(t, publisher.sent(), 1)
+
+	db.First(&event)
+	assert.Equal(t, models.OutboxStatusPublished, event.Status)
+	assert.Empty(t, event.LastError)
+}
+
+// TestOutboxRelay_ClaimedEventsAreLeased verifies a claimed event is left alone until its lease expires
+func TestOutboxRelay_ClaimedEventsAreLeased(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	setupTransfer(t, db)
+	publisher := &fakePublisher{}
+	relay := NewOutboxRelay(repositories.NewOutboxRepository(), publisher)
+	now := time.Now()
+	relay.now = func() time.Time { return now }
+
+	claimed, err := relay.claimPending()
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+
+	published, err := relay.ProcessOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, published, "another relay skips leased events")
+
+	now = now.Add(defaultOutboxLease + time.Second)
+	published, err = relay.ProcessOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, published, "an expired lease is picked up again")
+	assert.Len(t, publisher.sent(), 1)
+}
+
+// TestOutboxRelay_TruncatesLongErrorsOnRuneBoundary verifies stored errors stay valid UTF-8
+func TestOutboxRelay_TruncatesLongErrorsOnRuneBoundary(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	setupTransfer(t, db)
+	publisher := &fakePublisher{down: true, failure: errors.New(strings.Repeat("錯", 200))}
+	relay := NewOutboxRelay(repositories.NewOutboxRepository(), publisher)
+
+	_, err := relay.ProcessOnce(context.Background())
+	assert.NoError(t, err)
+
+	var event models.OutboxEvent
+	db.First(&event)
+	assert.LessOrEqual(t, len(event.LastError), maxOutboxErrorLength)
+	assert.True(t, utf8.ValidString(event.LastError))
+	assert.Equal(t, strings.Repeat("錯", maxOutboxErrorLength/3), event.LastError)
+}
+
+// TestOutboxRelay_BackoffIsCapped verifies the retry delay never exceeds the maximum
+func TestOutboxRelay_BackoffIsCapped(t *testing.T) {
+	relay := NewOutboxRelay(nil, nil)
+
+	assert.Equal(t, defaultOutboxBaseBackoff, relay.backoff(1))
+	assert.Equal(t, 2*defaultOutboxBaseBackoff, relay.backoff(2))
+	assert.Equal(t, 4*defaultOutboxBaseBackoff, relay.backoff(3))
+	assert.Equal(t, defaultOutboxMaxBackoff, relay.backoff(50))
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultReconciliationBatchSize = 200
+	maxReconciliationErrorLength   = 500
+)
+
+var ErrReconciliationRunNotFound = errors.New("reconciliation run not found")
+
+// ReconciliationService 對帳
+// 走訪所有錢包與交易，檢查錢包餘額與餘額歷史、ledger 是否一致，並記錄孤立的歷史紀錄與單邊轉帳
+type ReconciliationService struct {
+	walletRepo         repositories.IWallet
+	transactionRepo    repositories.ITransaction
+	balanceHistoryRepo repositories.IBalanceHistory
+	ledgerRepo         repositories.ILedger
+	reconciliationRepo repositories.IReconciliation
+	batchSize          int
+	now                func() time.Time
+}
+
+func NewReconciliationService(walletRepo repositories.IWallet, txRepo repositories.ITransaction) *ReconciliationService {
+	return &ReconciliationService{
+		walletRepo:         walletRepo,
+		transactionRepo:    txRepo,
+		balanceHistoryRepo: repositories.NewBalanceHistoryRepository(),
+		ledgerRepo:         repositories.NewLedgerRepository(),
+		reconciliationRepo: repositories.NewReconciliationRepository(),
+		batchSize:          defaultReconciliationBatchSize,
+		now:                time.Now,
+	}
+}
+
+// RunScheduler 每隔 interval 執行一次對帳，直到 ctx 被取消
+func (s *ReconciliationService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		run, err := s.Run(models.ReconciliationTriggerScheduled)
+		if err != nil {
+			log.Println("⚠️ Reconciliation error:", err)
+			continue
+		}
+		if run.FindingCount > 0 {
+			log.Printf("⚠️ Reconciliation run %d found %d issue(s)", run.ID, run.FindingCount)
+		}
+	}
+}
+
+// Run 執行一次完整對帳並保存結果
+// 對帳失敗時 run 仍會以 failed 狀態保存
+func (s *ReconciliationService) Run(trigger string) (*models.ReconciliationRun, error) {
+	run := &models.ReconciliationRun{
+		Trigger:   trigger,
+		Status:    models.ReconciliationStatusRunning,
+		StartedAt: s.now(),
+	}
+	if err := s.reconciliationRepo.CreateRun(run); err != nil {
+		return nil, err
+	}
+
+	findings, err := s.collect(run)
+	if err == nil {
+		for i := range findings {
+			findings[i].RunID = run.ID
+		}
+		err = s.reconciliationRepo.CreateFindings(findings)
+	}
+
+	finishedAt := s.now()
+	run.FinishedAt = &finishedAt
+	if err != nil {
+		run.Status = models.ReconciliationStatusFailed
+		run.Error = err.Error()
+		if len(run.Error) > maxReconciliationErrorLength {
+			run.Error = run.Error[:maxReconciliationErrorLength]
+		}
+	} else {
+		run.Status = models.ReconciliationStatusCompleted
+		run.FindingCount = len(findings)
+	}
+
+	if updateErr := s.reconciliationRepo.UpdateRun(run); updateErr != nil && err == nil {
+		err = updateErr
+	}
+	return run, err
+}
+
+// GetRuns 取得最近的對帳紀錄
+func (s *ReconciliationService) GetRuns(limit int) ([]models.ReconciliationRun, error) {
+	return s.reconciliationRepo.GetRuns(limit)
+}
+
+// GetFindings 取得某次對帳的異常，findingType 為空時回傳全部
+func (s *ReconciliationService) GetFindings(runID uint, findingType string) (*models.ReconciliationRun, []models.ReconciliationFinding, error) {
+	run, err := s.reconciliationRepo.GetRunByID(runID)
+	if err != nil {
+		return nil, nil, ErrReconciliationRunNotFound
+	}
+
+	findings, err := s.reconciliationRepo.GetFindingsByRunID(runID, findingType)
+	if err != nil {
+		return nil, nil, err
+	}
+	return run, findings, nil
+}
+
+func (s *ReconciliationService) collect(run *models.ReconciliationRun) ([]models.ReconciliationFinding, error) {
+	var findings []models.ReconciliationFinding
+
+	err := s.walletRepo.FindWalletsInBatches(s.batchSize, func(wallets []models.Wallet) error {
+		for _, wallet := range wallets {
+			walletFindings, err := s.checkWallet(wallet)
+			if err != nil {
+				return err
+			}
+			findings = append(findings, walletFindings...)
+			run.WalletsChecked++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.transactionRepo.FindTransactionsInBatches(s.batchSize, func(transactions []models.Transaction) error {
+		for _, transaction := range transactions {
+			finding, err := s.checkTransfer(transaction)
+			if err != nil {
+				return err
+			}
+			if finding != nil {
+				findings = append(findings, *finding)
+			}
+			if finding := s.checkHash(transaction); finding != nil {
+				findings = append(findings, *finding)
+			}
+			run.TransactionsChecked++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	orphans, err := s.balanceHistoryRepo.FindOrphanedHistories()
+	if err != nil {
+		return nil, err
+	}
+	for _, history := range orphans {
+		findings = append(findings, models.ReconciliationFinding{
+			Type:          models.FindingOrphanedHistory,
+			WalletID:      history.WalletID,
+			TransactionID: history.TransactionID,
+			HistoryID:     history.ID,
+			Actual:        history.Amount,
+			Detail:        "balance history references a missing wallet or transaction",
+		})
+	}
+
+	return findings, nil
+}
+
+// checkWallet 核對單一錢包的餘額歷史與 ledger
+// 檢查期間鎖定錢包，避免進行中的轉帳造成誤報
+func (s *ReconciliationService) checkWallet(wallet models.Wallet) ([]models.ReconciliationFinding, error) {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer tx.Rollback()
+
+	locked, err := s.walletRepo.GetWalletByUserIDAndCurrencyWithTx(wallet.UserID, wallet.CurrencyID, tx)
+	if err != nil {
+		// 錢包在走訪期間被刪除
+		return nil, nil
+	}
+
+	var findings []models.ReconciliationFinding
+
+	histories, err := s.balanceHistoryRepo.GetHistoryByWalletID(locked.ID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(histories, func(i, j int) bool { return histories[i].ID < histories[j].ID })
+
+	if len(histories) > 0 {
+		// 第一筆歷史的 BalanceBefore 視為期初餘額
+		expected := histories[0].BalanceBefore
+		for i, history := range histories {
+			if i > 0 && !history.BalanceBefore.Equal(histories[i-1].BalanceAfter) {
+				findings = append(findings, models.ReconciliationFinding{
+					Type:          models.FindingBalanceDrift,
+					WalletID:      locked.ID,
+					TransactionID: history.TransactionID,
+					HistoryID:     history.ID,
+					Expected:      histories[i-1].BalanceAfter,
+					Actual:        history.BalanceBefore,
+					Detail:        "balance_before does not continue from the previous history entry",
+				})
+			}
+			expected = expected.Add(history.AvailableDelta())
+		}
+
+		if !expected.Equal(locked.Balance) {
+			findings = append(findings, models.ReconciliationFinding{
+				Type:     models.FindingBalanceDrift,
+				WalletID: locked.ID,
+				Expected: expected,
+				Actual:   locked.Balance,
+				Detail:   "wallet balance differs from the sum of its balance history",
+			})
+		}
+	}
+
+	accounts, err := s.ledgerRepo.GetAccountsByWalletID(locked.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		posted, err := s.ledgerRepo.SumPostings(account.ID)
+		if err != nil {
+			return nil, err
+		}
+		actual := locked.Balance
+		if account.Kind == models.LedgerKindWalletHeld {
+			actual = locked.HeldBalance
+		}
+		if !posted.Equal(actual) {
+			findings = append(findings, models.ReconciliationFinding{
+				Type:     models.FindingLedgerDrift,
+				WalletID: locked.ID,
+				Expected: posted,
+				Actual:   actual,
+				Detail:   fmt.Sprintf("wallet differs from ledger account %s", account.Code),
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// checkTransfer 檢查已完成轉帳的 debit / credit 歷史是否互相抵銷
+// 兌換兩邊的幣種不同，只檢查是否恰好各有一筆 debit 與 credit
+func (s *ReconciliationService) checkTransfer(transaction models.Transaction) (*models.ReconciliationFinding, error) {
+	switch transaction.Type {
+	case models.TxTypeTransfer, models.TxTypeSwap, models.TxTypeReversal, models.TxTypeRefund:
+	default:
+		return nil, nil
+	}
+	// 被沖正的轉帳仍保有原本的餘額歷史，沖正本身另有一筆交易
+	if transaction.Status != models.TxStatusCompleted && transaction.Status != models.TxStatusReversed {
+		return nil, nil
+	}
+
+	histories, err := s.balanceHistoryRepo.GetHistoryByTransactionID(transaction.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	debits, credits := 0, 0
+	net := decimal.Zero
+	for _, history := range histories {
+		switch history.ChangeType {
+		case models.ChangeTypeDebit:
+			debits++
+		case models.ChangeTypeCredit:
+			credits++
+		}
+		net = net.Add(history.AvailableDelta())
+	}
+
+	// 轉出方的 debit 包含手續費，手續費計入 system_fees 帳戶
+	net = net.Add(transaction.Fee)
+	balanced := debits > 0 && credits > 0 && net.IsZero()
+	if transaction.Type == models.TxTypeSwap {
+		balanced = debits == 1 && credits == 1
+	}
+	if balanced {
+		return nil, nil
+	}
+
+	return &models.ReconciliationFinding{
+		Type:          models.FindingOneSidedTransfer,
+		TransactionID: transaction.ID,
+		Expected:      decimal.Zero,
+		Actual:        net,
+		Detail:        fmt.Sprintf("%s %s has %d debit and %d credit histories", transaction.Type, transaction.Hash, debits, credits),
+	}, nil
+}
+
+// checkHash 由交易內容重新計算 hash 並與儲存的 hash 比對，尚未遷移的舊 hash 不檢查
+func (s *ReconciliationService) checkHash(transaction models.Transaction) *models.ReconciliationFinding {
+	if transaction.HashVersion == models.HashVersionLegacy || transaction.HashMatches() {
+		return nil
+	}
+
+	return &models.ReconciliationFinding{
+		Type:          models.FindingHashMismatch,
+		TransactionID: transaction.ID,
+		Detail:        fmt.Sprintf("stored hash %s does not match recomputed %s", transaction.Hash, transaction.GenerateHash()),
+	}
+}
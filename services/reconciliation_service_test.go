@@ -0,0 +1,111 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReconciliationService() *ReconciliationService {
+	return NewReconciliationService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+}
+
+// TestReconciliation_CleanLedgerHasNoFindings verifies normal transfers and funding flows reconcile
+func TestReconciliation_CleanLedgerHasNoFindings(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	txService := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	assert.NoError(t, txService.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(300)))
+
+	funding := NewFundingService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	withdrawal, err := funding.CreateWithdrawal(bob.ID, currency.ID, decimal.NewFromInt(50), "addr")
+	assert.NoError(t, err)
+	_, err = funding.Fail(withdrawal.Hash, "rejected")
+	assert.NoError(t, err)
+
+	run, err := newTestReconciliationService().Run(models.ReconciliationTriggerManual)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReconciliationStatusCompleted, run.Status)
+	assert.Equal(t, 2, run.WalletsChecked)
+	assert.Equal(t, 2, run.TransactionsChecked)
+	assert.Equal(t, 0, run.FindingCount)
+	assert.NotNil(t, run.FinishedAt)
+}
+
+// TestReconciliation_DetectsBalanceAndLedgerDrift verifies a wallet edited outside the services is flagged
+func TestReconciliation_DetectsBalanceAndLedgerDrift(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	setupTransfer(t, db)
+
+	// 直接修改餘額，模擬未經服務層的變更
+	var wallet models.Wallet
+	db.Order("id asc").First(&wallet)
+	db.Model(&wallet).Update("balance", decimal.NewFromInt(5000))
+
+	service := newTestReconciliationService()
+	run, err := service.Run(models.ReconciliationTriggerManual)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, run.FindingCount)
+
+	_, drift, err := service.GetFindings(run.ID, models.FindingBalanceDrift)
+	assert.NoError(t, err)
+	if assert.Len(t, drift, 1) {
+		assert.Equal(t, wallet.ID, drift[0].WalletID)
+		assert.Equal(t, "900", drift[0].Expected.String())
+		assert.Equal(t, "5000", drift[0].Actual.String())
+	}
+
+	_, ledgerDrift, err := service.GetFindings(run.ID, models.FindingLedgerDrift)
+	assert.NoError(t, err)
+	assert.Len(t, ledgerDrift, 1)
+}
+
+// TestReconciliation_DetectsOneSidedTransferAndOrphans verifies missing and dangling histories are flagged
+func TestReconciliation_DetectsOneSidedTransferAndOrphans(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	transaction := setupTransfer(t, db)
+
+	// 刪除入帳方的歷史，留下單邊轉帳
+	db.Where("transaction_id = ? AND change_type = ?", transaction.ID, models.ChangeTypeCredit).Delete(&models.BalanceHistory{})
+	// 指向不存在交易的歷史
+	db.Create(&models.BalanceHistory{WalletID: 999, TransactionID: 999, ChangeType: models.ChangeTypeCredit, Amount: decimal.NewFromInt(1)})
+
+	service := newTestReconciliationService()
+	run, err := service.Run(models.ReconciliationTriggerManual)
+	assert.NoError(t, err)
+
+	_, oneSided, err := service.GetFindings(run.ID, models.FindingOneSidedTransfer)
+	assert.NoError(t, err)
+	if assert.Len(t, oneSided, 1) {
+		assert.Equal(t, transaction.ID, oneSided[0].TransactionID)
+		assert.Equal(t, "-100", oneSided[0].Actual.String())
+	}
+
+	_, orphans, err := service.GetFindings(run.ID, models.FindingOrphanedHistory)
+	assert.NoError(t, err)
+	if assert.Len(t, orphans, 1) {
+		assert.Equal(t, uint(999), orphans[0].TransactionID)
+	}
+
+	runs, err := service.GetRuns(10)
+	assert.NoError(t, err)
+	assert.Len(t, runs, 1)
+
+	_, _, err = service.GetFindings(run.ID+1, "")
+	assert.ErrorIs(t, err, ErrReconciliationRunNotFound)
+}
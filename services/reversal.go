@@ -0,0 +1,227 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/utils"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTransactionNotReversible   = errors.New("only completed transfers can be reversed or refunded")
+	ErrTransactionAlreadyReversed = errors.New("transaction was already reversed")
+	ErrRefundExceedsRemaining     = errors.New("refund amount exceeds the refundable amount")
+	ErrNotTransferRecipient       = errors.New("only the recipient can refund this transfer")
+)
+
+// Reverse 由客服沖正一筆已完成的轉帳
+// 建立一筆由收款方轉回轉出方的 reversal 交易，金額為尚未退款的部分，手續費不退還
+// 收款方目前的餘額不足時拒絕沖正，不會讓餘額變成負數
+func (s *TransactionService) Reverse(hash, reason string) (*models.Transaction, *models.Transaction, error) {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	original, reversal, err := s.reverseInTx(tx, hash, reason)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, nil, err
+	}
+	streamTransaction(original)
+	streamCommitted(s.walletRepo, s.balanceHistoryRepo, reversal)
+	return original, reversal, nil
+}
+
+func (s *TransactionService) reverseInTx(tx *gorm.DB, hash, reason string) (*models.Transaction, *models.Transaction, error) {
+	original, err := s.lockCompensable(tx, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	amount := original.RefundableAmount()
+	if !amount.IsPositive() {
+		return nil, nil, fmt.Errorf("%w: transfer was fully refunded", ErrTransactionNotReversible)
+	}
+
+	reversal, err := s.compensate(tx, original, models.TxTypeReversal, models.JournalReversal, amount, reason)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	previousStatus := original.Status
+	original.Status = models.TxStatusReversed
+	if err := s.transactionRepo.UpdateTransaction(original, tx); err != nil {
+		return nil, nil, err
+	}
+	if err := enqueueTxStatusChanged(s.outboxRepo, original, previousStatus, tx); err != nil {
+		return nil, nil, err
+	}
+
+	return original, reversal, nil
+}
+
+// Refund 由收款方退還收到的轉帳，amount 為 nil 時退還所有尚未退款的金額
+// 可以多次部分退款，累計金額不能超過原始轉帳金額
+func (s *TransactionService) Refund(userID uint, hash string, amount *decimal.Decimal) (*models.Transaction, *models.Transaction, error) {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	original, refund, err := s.refundInTx(tx, userID, hash, amount)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, nil, err
+	}
+	streamTransaction(original)
+	streamCommitted(s.walletRepo, s.balanceHistoryRepo, refund)
+	return original, refund, nil
+}
+
+func (s *TransactionService) refundInTx(tx *gorm.DB, userID uint, hash string, amount *decimal.Decimal) (*models.Transaction, *models.Transaction, error) {
+	original, err := s.lockCompensable(tx, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if original.ToUserID != userID {
+		return nil, nil, ErrNotTransferRecipient
+	}
+	if err := ensureNotFrozen(s.userRepo, userID); err != nil {
+		return nil, nil, err
+	}
+
+	remaining := original.RefundableAmount()
+	refundAmount := remaining
+	if amount != nil {
+		currency, err := s.currencyRepo.FindCurrencyByIDForShareWithTx(original.CurrencyID, tx)
+		if err != nil {
+			return nil, nil, ErrCurrencyNotFound
+		}
+		if refundAmount, err = s.precision.apply(currency, *amount); err != nil {
+			return nil, nil, err
+		}
+	}
+	if !remaining.IsPositive() || refundAmount.GreaterThan(remaining) {
+		return nil, nil, fmt.Errorf("%w: %s remaining", ErrRefundExceedsRemaining, remaining)
+	}
+
+	refund, err := s.compensate(tx, original, models.TxTypeRefund, models.JournalRefund, refundAmount, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	original.RefundedAmount = original.RefundedAmount.Add(refundAmount)
+	if err := s.transactionRepo.UpdateTransaction(original, tx); err != nil {
+		return nil, nil, err
+	}
+
+	return original, refund, nil
+}
+
+// lockCompensable 鎖定原始轉帳，確保同一筆轉帳的沖正與退款依序處理
+func (s *TransactionService) lockCompensable(tx *gorm.DB, hash string) (*models.Transaction, error) {
+	original, err := s.transactionRepo.FindByHashWithTx(hash, tx)
+	if err != nil {
+		return nil, ErrTransactionNotFound
+	}
+	if original.Type != models.TxTypeTransfer {
+		return nil, ErrTransactionNotReversible
+	}
+	if original.Status == models.TxStatusReversed {
+		return nil, ErrTransactionAlreadyReversed
+	}
+	if original.Status != models.TxStatusCompleted {
+		return nil, ErrTransactionNotReversible
+	}
+	return original, nil
+}
+
+// compensate 建立由原收款方轉回原轉出方的補償交易，並寫入分錄、餘額歷史與 outbox
+func (s *TransactionService) compensate(tx *gorm.DB, original *models.Transaction, txType, journalType string, amount decimal.Decimal, reference string) (*models.Transaction, error) {
+	fromID, toID := original.ToUserID, original.FromUserID
+
+	fromWallet, toWallet, err := s.lockWalletPair(tx, fromID, toID, original.CurrencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if fromWallet.Balance.LessThan(amount) {
+		return nil, fmt.Errorf("%w: recipient balance %s is below %s", ErrInsufficientBalance, fromWallet.Balance, amount)
+	}
+
+	fromBalanceBefore := fromWallet.Balance
+	toBalanceBefore := toWallet.Balance
+
+	fromWallet.Balance = fromWallet.Balance.Sub(amount)
+	toWallet.Balance = toWallet.Balance.Add(amount)
+
+	if err := s.walletRepo.UpdateWallet(fromWallet, tx); err != nil {
+		return nil, err
+	}
+	if err := s.walletRepo.UpdateWallet(toWallet, tx); err != nil {
+		return nil, err
+	}
+
+	transaction := &models.Transaction{
+		Type:                  txType,
+		FromUserID:            fromID,
+		ToUserID:              toID,
+		CurrencyID:            original.CurrencyID,
+		Amount:                amount,
+		Status:                models.TxStatusCompleted,
+		Reference:             reference,
+		OriginalTransactionID: &original.ID,
+	}
+	sealTransaction(transaction)
+
+	if err := s.transactionRepo.CreateTransaction(transaction, tx); err != nil {
+		return nil, err
+	}
+
+	if err := s.ledger.Post(tx, transaction.ID, journalType, walletDebit(fromWallet, amount), walletCredit(toWallet, amount)); err != nil {
+		return nil, err
+	}
+
+	histories := []*models.BalanceHistory{
+		{
+			UserID:        fromID,
+			WalletID:      fromWallet.ID,
+			TransactionID: transaction.ID,
+			ChangeType:    models.ChangeTypeDebit,
+			Status:        transaction.Status,
+			Amount:        amount,
+			BalanceBefore: fromBalanceBefore,
+			BalanceAfter:  fromWallet.Balance,
+		},
+		{
+			UserID:        toID,
+			WalletID:      toWallet.ID,
+			TransactionID: transaction.ID,
+			ChangeType:    models.ChangeTypeCredit,
+			Status:        transaction.Status,
+			Amount:        amount,
+			BalanceBefore: toBalanceBefore,
+			BalanceAfter:  toWallet.Balance,
+		},
+	}
+	for _, history := range histories {
+		if err := s.balanceHistoryRepo.CreateHistory(history, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.enqueueTxCreatedMessage(transaction, original.Hash, tx); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
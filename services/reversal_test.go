@@ -0,0 +1,196 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/kafka_client"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertReconciled(t *testing.T) {
+	t.Helper()
+	run, err := newTestReconciliationService().Run(models.ReconciliationTriggerManual)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, run.FindingCount)
+}
+
+// TestReverse_CreatesLinkedCompensation verifies the reversal transaction, history, events and original status
+func TestReverse_CreatesLinkedCompensation(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	walletRepo := repositories.NewWalletRepository()
+	service := NewTransactionService(walletRepo, repositories.NewTransactionRepository())
+	transfer, err := service.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("40"))
+	assert.NoError(t, err)
+
+	original, reversal, err := service.Reverse(transfer.Hash, "fraud")
+	assert.NoError(t, err)
+	assert.Equal(t, models.TxStatusReversed, original.Status)
+	assert.Equal(t, models.TxTypeReversal, reversal.Type)
+	assert.Equal(t, bob.ID, reversal.FromUserID)
+	assert.Equal(t, alice.ID, reversal.ToUserID)
+	assert.Equal(t, "40", reversal.Amount.String())
+	assert.Equal(t, "fraud", reversal.Reference)
+	if assert.NotNil(t, reversal.OriginalTransactionID) {
+		assert.Equal(t, transfer.ID, *reversal.OriginalTransactionID)
+	}
+
+	aliceWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, usdt.ID)
+	assert.Equal(t, "100", aliceWallet.Balance.String())
+	bobWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(bob.ID, usdt.ID)
+	assert.Equal(t, "0", bobWallet.Balance.String())
+
+	histories, err := repositories.NewBalanceHistoryRepository().GetHistoryByTransactionID(reversal.ID)
+	assert.NoError(t, err)
+	assert.Len(t, histories, 2)
+
+	var events []models.OutboxEvent
+	assert.NoError(t, db.Order("id").Find(&events).Error)
+	topics := make([]string, len(events))
+	for i, event := range events {
+		topics[i] = event.Topic
+	}
+	assert.Equal(t, []string{kafka_client.TopicTxCreated, kafka_client.TopicTxCreated, kafka_client.TopicTxStatusChanged}, topics)
+
+	_, _, err = service.Reverse(transfer.Hash, "again")
+	assert.ErrorIs(t, err, ErrTransactionAlreadyReversed)
+	_, _, err = service.Reverse(reversal.Hash, "reverse the reversal")
+	assert.ErrorIs(t, err, ErrTransactionNotReversible)
+	_, _, err = service.Reverse("missing", "x")
+	assert.ErrorIs(t, err, ErrTransactionNotFound)
+
+	assertReconciled(t)
+}
+
+// TestReverse_Fail_RecipientBalanceTooLow verifies a reversal never drives the recipient negative
+func TestReverse_Fail_RecipientBalanceTooLow(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	walletRepo := repositories.NewWalletRepository()
+	service := NewTransactionService(walletRepo, repositories.NewTransactionRepository())
+	transfer, err := service.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("40"))
+	assert.NoError(t, err)
+
+	_, err = service.TransferWithResult(bob.ID, alice.ID, usdt.ID, dec("30"))
+	assert.NoError(t, err)
+
+	_, _, err = service.Reverse(transfer.Hash, "fraud")
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+	bobWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(bob.ID, usdt.ID)
+	assert.Equal(t, "10", bobWallet.Balance.String())
+
+	original, err := service.GetTransactionByHash(transfer.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TxStatusCompleted, original.Status)
+}
+
+// TestRefund_PartialThenFull verifies partial refunds accumulate and a reversal only undoes the rest
+func TestRefund_PartialThenFull(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	walletRepo := repositories.NewWalletRepository()
+	service := NewTransactionService(walletRepo, repositories.NewTransactionRepository())
+	transfer, err := service.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("40"))
+	assert.NoError(t, err)
+
+	amount := dec("15")
+	original, refund, err := service.Refund(bob.ID, transfer.Hash, &amount)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TxTypeRefund, refund.Type)
+	assert.Equal(t, "15", original.RefundedAmount.String())
+	assert.Equal(t, models.TxStatusCompleted, original.Status)
+
+	tooMuch := dec("25.5")
+	_, _, err = service.Refund(bob.ID, transfer.Hash, &tooMuch)
+	assert.ErrorIs(t, err, ErrRefundExceedsRemaining)
+
+	// 客服沖正只會轉回尚未退款的 25
+	_, reversal, err := service.Reverse(transfer.Hash, "dispute")
+	assert.NoError(t, err)
+	assert.Equal(t, "25", reversal.Amount.String())
+	aliceWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, usdt.ID)
+	assert.Equal(t, "100", aliceWallet.Balance.String())
+	bobWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(bob.ID, usdt.ID)
+	assert.Equal(t, "0", bobWallet.Balance.String())
+
+	assertReconciled(t)
+}
+
+// TestRefund_FullByDefault verifies omitting the amount refunds everything and closes the transfer
+func TestRefund_FullByDefault(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	walletRepo := repositories.NewWalletRepository()
+	service := NewTransactionService(walletRepo, repositories.NewTransactionRepository())
+	transfer, err := service.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("40"))
+	assert.NoError(t, err)
+
+	original, refund, err := service.Refund(bob.ID, transfer.Hash, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "40", refund.Amount.String())
+	assert.Equal(t, "40", original.RefundedAmount.String())
+	aliceWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, usdt.ID)
+	assert.Equal(t, "100", aliceWallet.Balance.String())
+
+	_, _, err = service.Refund(bob.ID, transfer.Hash, nil)
+	assert.ErrorIs(t, err, ErrRefundExceedsRemaining)
+	_, _, err = service.Reverse(transfer.Hash, "late")
+	assert.ErrorIs(t, err, ErrTransactionNotReversible)
+
+	assertReconciled(t)
+}
+
+// TestRefund_Fail_OnlyRecipientWhileNotFrozen verifies who may refund
+func TestRefund_Fail_OnlyRecipientWhileNotFrozen(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	service := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	transfer, err := service.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("40"))
+	assert.NoError(t, err)
+
+	_, _, err = service.Refund(alice.ID, transfer.Hash, nil)
+	assert.ErrorIs(t, err, ErrNotTransferRecipient)
+
+	assert.NoError(t, db.Model(&models.User{}).Where("id = ?", bob.ID).Update("frozen_at", time.Now()).Error)
+	_, _, err = service.Refund(bob.ID, transfer.Hash, nil)
+	assert.ErrorIs(t, err, ErrAccountFrozen)
+}
@@ -0,0 +1,434 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"mini-crypto-wallet-api/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultScheduleBatchSize       = 50
+	defaultScheduleMaxAttempts     = 3 // 同一期最多嘗試次數，之後跳過該期
+	defaultScheduleRetryBackoff    = 15 * time.Minute
+	defaultScheduleMaxRetryBackoff = 4 * time.Hour
+	defaultScheduleDisableAfter    = 3 // 連續餘額不足次數達到此值時停用排程
+	maxScheduleErrorLength         = 255
+)
+
+var (
+	ErrScheduledTransferNotFound = errors.New("scheduled transfer not found")
+	ErrInvalidScheduledTransfer  = errors.New("invalid scheduled transfer")
+	ErrScheduledTransferClosed   = errors.New("scheduled transfer is completed or cancelled")
+)
+
+// ScheduledTransferService 管理預約 / 週期轉帳，並由 worker 執行到期的排程
+// 每一期的轉帳與該期的執行紀錄在同一個 DB 交易中寫入，(schedule_id, occurrence) 唯一，同一期不會重複扣款
+type ScheduledTransferService struct {
+	scheduleRepo    repositories.IScheduledTransfer
+	userRepo        repositories.IUser
+	currencyRepo    repositories.ICurrency
+	txService       *TransactionService
+	batchSize       int
+	maxAttempts     int
+	retryBackoff    time.Duration
+	maxRetryBackoff time.Duration
+	disableAfter    int
+	now             func() time.Time
+}
+
+func NewScheduledTransferService(txService *TransactionService) *ScheduledTransferService {
+	return &ScheduledTransferService{
+		scheduleRepo:    repositories.NewScheduledTransferRepository(),
+		userRepo:        repositories.NewUserRepository(),
+		currencyRepo:    repositories.NewCurrencyRepository(),
+		txService:       txService,
+		batchSize:       defaultScheduleBatchSize,
+		maxAttempts:     defaultScheduleMaxAttempts,
+		retryBackoff:    defaultScheduleRetryBackoff,
+		maxRetryBackoff: defaultScheduleMaxRetryBackoff,
+		disableAfter:    defaultScheduleDisableAfter,
+		now:             time.Now,
+	}
+}
+
+// Create 建立排程，金額與幣種會先依轉帳規則檢查
+func (s *ScheduledTransferService) Create(userID uint, req models.CreateScheduledTransferRequest) (*models.ScheduledTransfer, error) {
+	if err := validateTransfer(userID, req.ToUserID, req.Amount); err != nil {
+		return nil, err
+	}
+	if _, err := s.userRepo.GetUserByID(req.ToUserID); err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	currency, err := activeCurrency(s.currencyRepo, req.CurrencyID)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := s.txService.precision.apply(currency, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.StartAt.Before(s.now()) {
+		return nil, fmt.Errorf("%w: start_at must not be in the past", ErrInvalidScheduledTransfer)
+	}
+	if req.EndAt != nil && req.EndAt.Before(req.StartAt) {
+		return nil, fmt.Errorf("%w: end_at must not be before start_at", ErrInvalidScheduledTransfer)
+	}
+
+	schedule := &models.ScheduledTransfer{
+		UserID:        userID,
+		ToUserID:      req.ToUserID,
+		CurrencyID:    currency.ID,
+		Amount:        amount,
+		Frequency:     req.Frequency,
+		StartAt:       req.StartAt,
+		EndAt:         req.EndAt,
+		Status:        models.ScheduleStatusActive,
+		NextRunAt:     req.StartAt,
+		NextAttemptAt: req.StartAt,
+	}
+	if schedule.Frequency == models.FrequencyOnce {
+		schedule.EndAt = nil
+	}
+	if err := s.scheduleRepo.CreateSchedule(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// List 取得用戶未取消的排程
+func (s *ScheduledTransferService) List(userID uint) ([]models.ScheduledTransfer, error) {
+	return s.scheduleRepo.FindSchedulesByUserID(userID)
+}
+
+// Get 取得用戶自己的排程
+func (s *ScheduledTransferService) Get(userID, id uint) (*models.ScheduledTransfer, error) {
+	schedule, err := s.scheduleRepo.FindScheduleByID(id)
+	if err != nil || schedule.UserID != userID || schedule.Status == models.ScheduleStatusCancelled {
+		return nil, ErrScheduledTransferNotFound
+	}
+	return schedule, nil
+}
+
+// Runs 取得排程每一期的執行紀錄
+func (s *ScheduledTransferService) Runs(userID, id uint) ([]models.ScheduledTransferRun, error) {
+	if _, err := s.Get(userID, id); err != nil {
+		return nil, err
+	}
+	return s.scheduleRepo.FindRunsByScheduleID(id)
+}
+
+// Update 修改金額、結束時間，或暫停 / 恢復排程
+// 恢復時會略過暫停期間錯過的期數，並清除連續失敗次數
+func (s *ScheduledTransferService) Update(userID, id uint, req models.UpdateScheduledTransferRequest) (*models.ScheduledTransfer, error) {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	schedule, err := s.updateInTx(tx, userID, id, req)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return nil, commitDB.Error
+	}
+	return schedule, nil
+}
+
+func (s *ScheduledTransferService) updateInTx(tx *gorm.DB, userID, id uint, req models.UpdateScheduledTransferRequest) (*models.ScheduledTransfer, error) {
+	schedule, err := s.lockOwned(tx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status == models.ScheduleStatusCompleted {
+		return nil, ErrScheduledTransferClosed
+	}
+
+	if req.Amount != nil {
+		if err := validateTransfer(schedule.UserID, schedule.ToUserID, *req.Amount); err != nil {
+			return nil, err
+		}
+		currency, err := s.currencyRepo.FindCurrencyByID(schedule.CurrencyID)
+		if err != nil {
+			return nil, ErrCurrencyNotFound
+		}
+		if schedule.Amount, err = s.txService.precision.apply(currency, *req.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.EndAt != nil && schedule.Frequency != models.FrequencyOnce {
+		if req.EndAt.Before(schedule.StartAt) {
+			return nil, fmt.Errorf("%w: end_at must not be before start_at", ErrInvalidScheduledTransfer)
+		}
+		schedule.EndAt = req.EndAt
+	}
+
+	if req.Active != nil {
+		switch {
+		case *req.Active && schedule.Status != models.ScheduleStatusActive:
+			schedule.Status = models.ScheduleStatusActive
+			schedule.ConsecutiveFailures = 0
+			s.skipMissed(schedule)
+		case !*req.Active && schedule.Status == models.ScheduleStatusActive:
+			schedule.Status = models.ScheduleStatusPaused
+		}
+	}
+
+	if !schedule.HasOccurrence(schedule.Occurrence) {
+		schedule.Status = models.ScheduleStatusCompleted
+	}
+
+	if err := s.scheduleRepo.UpdateSchedule(schedule, tx); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// Cancel 取消排程，已執行的紀錄會保留
+func (s *ScheduledTransferService) Cancel(userID, id uint) error {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	schedule, err := s.lockOwned(tx, userID, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	schedule.Status = models.ScheduleStatusCancelled
+	if err := s.scheduleRepo.UpdateSchedule(schedule, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// lockOwned 鎖定用戶自己的排程，避免與 worker 同時修改
+func (s *ScheduledTransferService) lockOwned(tx *gorm.DB, userID, id uint) (*models.ScheduledTransfer, error) {
+	schedule, err := s.scheduleRepo.FindScheduleByIDWithTx(id, tx)
+	if err != nil || schedule.UserID != userID || schedule.Status == models.ScheduleStatusCancelled {
+		return nil, ErrScheduledTransferNotFound
+	}
+	return schedule, nil
+}
+
+// RunScheduler 定期執行到期的排程，直到 ctx 被取消
+func (s *ScheduledTransferService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.ProcessDue(ctx); err != nil {
+			log.Println("⚠️ Scheduled transfer worker error:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue 執行一批到期的排程，回傳成功轉帳的期數
+// 個別排程的轉帳失敗會記錄在執行紀錄中並安排重試，不會中斷整批處理
+func (s *ScheduledTransferService) ProcessDue(ctx context.Context) (int, error) {
+	ids, err := s.scheduleRepo.FindDueScheduleIDs(s.now(), s.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+
+		ok, err := s.runOccurrence(id)
+		if err != nil {
+			log.Printf("⚠️ Scheduled transfer %d failed: %v", id, err)
+			continue
+		}
+		if ok {
+			completed++
+		}
+	}
+	return completed, nil
+}
+
+// runOccurrence 執行排程目前這一期的轉帳
+// 排程在交易中被鎖定並重新確認到期，轉帳、執行紀錄與下一期時間一起 commit
+func (s *ScheduledTransferService) runOccurrence(id uint) (bool, error) {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	schedule, err := s.scheduleRepo.FindScheduleByIDWithTx(id, tx)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	// 其他 worker 已處理，或排程已被暫停 / 取消
+	if schedule.Status != models.ScheduleStatusActive || schedule.NextAttemptAt.After(s.now()) {
+		tx.Rollback()
+		return false, nil
+	}
+
+	run, err := s.currentRun(tx, schedule)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	var transaction *models.Transaction
+	if run.Status != models.ScheduleRunCompleted {
+		var transferErr error
+		transaction, transferErr = s.txService.transferInTx(tx, schedule.UserID, schedule.ToUserID, schedule.CurrencyID, schedule.Amount)
+		if transferErr != nil {
+			tx.Rollback()
+			if err := s.recordFailure(id, transferErr); err != nil {
+				return false, err
+			}
+			return false, transferErr
+		}
+
+		run.Status = models.ScheduleRunCompleted
+		run.Attempts++
+		run.TransactionID = &transaction.ID
+		run.Error = ""
+		if err := s.scheduleRepo.SaveRun(run, tx); err != nil {
+			tx.Rollback()
+			return false, err
+		}
+	}
+
+	schedule.ConsecutiveFailures = 0
+	schedule.LastError = ""
+	s.advance(schedule)
+	if err := s.scheduleRepo.UpdateSchedule(schedule, tx); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return false, commitDB.Error
+	}
+	if transaction != nil {
+		streamCommitted(s.txService.walletRepo, s.txService.balanceHistoryRepo, transaction)
+	}
+	return true, nil
+}
+
+// recordFailure 記錄失敗並決定後續：稍後重試、跳過這一期，或在連續餘額不足時停用排程
+func (s *ScheduledTransferService) recordFailure(id uint, transferErr error) error {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	schedule, err := s.scheduleRepo.FindScheduleByIDWithTx(id, tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if schedule.Status != models.ScheduleStatusActive {
+		tx.Rollback()
+		return nil
+	}
+
+	run, err := s.currentRun(tx, schedule)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	message := utils.TruncateString(transferErr.Error(), maxScheduleErrorLength)
+	run.Status = models.ScheduleRunFailed
+	run.Attempts++
+	run.Error = message
+	schedule.LastError = message
+	if errors.Is(transferErr, ErrInsufficientBalance) {
+		schedule.ConsecutiveFailures++
+	}
+
+	switch {
+	case schedule.ConsecutiveFailures >= s.disableAfter:
+		schedule.Status = models.ScheduleStatusDisabled
+	case run.Attempts >= s.maxAttempts:
+		run.Status = models.ScheduleRunSkipped
+		s.advance(schedule)
+	default:
+		schedule.NextAttemptAt = s.now().Add(s.backoff(run.Attempts))
+	}
+
+	if err := s.scheduleRepo.SaveRun(run, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := s.scheduleRepo.UpdateSchedule(schedule, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// currentRun 取得排程目前這一期的執行紀錄，尚未執行過時回傳新的紀錄
+func (s *ScheduledTransferService) currentRun(tx *gorm.DB, schedule *models.ScheduledTransfer) (*models.ScheduledTransferRun, error) {
+	run, err := s.scheduleRepo.FindRun(schedule.ID, schedule.Occurrence, tx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.ScheduledTransferRun{
+			ScheduleID:   schedule.ID,
+			Occurrence:   schedule.Occurrence,
+			ScheduledFor: schedule.NextRunAt,
+		}, nil
+	}
+	return run, err
+}
+
+// advance 前進到下一期，沒有下一期時將排程標記為完成
+func (s *ScheduledTransferService) advance(schedule *models.ScheduledTransfer) {
+	schedule.Occurrence++
+	if !schedule.HasOccurrence(schedule.Occurrence) {
+		schedule.Status = models.ScheduleStatusCompleted
+		return
+	}
+	schedule.NextRunAt = schedule.OccurrenceAt(schedule.Occurrence)
+	schedule.NextAttemptAt = schedule.NextRunAt
+}
+
+// skipMissed 略過時間已過的期數，恢復排程時不補扣暫停期間的轉帳
+// 單次轉帳沒有下一期，恢復後立即執行
+func (s *ScheduledTransferService) skipMissed(schedule *models.ScheduledTransfer) {
+	now := s.now()
+	if schedule.Frequency == models.FrequencyOnce {
+		schedule.NextAttemptAt = schedule.NextRunAt
+		if schedule.NextAttemptAt.Before(now) {
+			schedule.NextAttemptAt = now
+		}
+		return
+	}
+	for schedule.HasOccurrence(schedule.Occurrence) && schedule.OccurrenceAt(schedule.Occurrence).Before(now) {
+		schedule.Occurrence++
+	}
+	schedule.NextRunAt = schedule.OccurrenceAt(schedule.Occurrence)
+	schedule.NextAttemptAt = schedule.NextRunAt
+}
+
+// backoff 計算第 attempts 次失敗後的重試間隔（指數退避，有上限）
+func (s *ScheduledTransferService) backoff(attempts int) time.Duration {
+	delay := s.retryBackoff
+	for i := 1; i < attempts && delay < s.maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, s.maxRetryBackoff)
+}
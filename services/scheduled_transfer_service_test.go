@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestScheduledTransfer_OccurrenceAt verifies monthly schedules clamp to the month end without drifting
+func TestScheduledTransfer_OccurrenceAt(t *testing.T) {
+	start := time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC)
+	monthly := &models.ScheduledTransfer{Frequency: models.FrequencyMonthly, StartAt: start}
+	assert.Equal(t, time.Date(2026, 2, 28, 9, 0, 0, 0, time.UTC), monthly.OccurrenceAt(1))
+	assert.Equal(t, time.Date(2026, 3, 31, 9, 0, 0, 0, time.UTC), monthly.OccurrenceAt(2))
+	assert.Equal(t, time.Date(2026, 4, 30, 9, 0, 0, 0, time.UTC), monthly.OccurrenceAt(3))
+
+	weekly := &models.ScheduledTransfer{Frequency: models.FrequencyWeekly, StartAt: start}
+	assert.Equal(t, time.Date(2026, 2, 14, 9, 0, 0, 0, time.UTC), weekly.OccurrenceAt(2))
+
+	end := time.Date(2026, 2, 2, 9, 0, 0, 0, time.UTC)
+	daily := &models.ScheduledTransfer{Frequency: models.FrequencyDaily, StartAt: start, EndAt: &end}
+	assert.True(t, daily.HasOccurrence(2))
+	assert.False(t, daily.HasOccurrence(3))
+
+	once := &models.ScheduledTransfer{Frequency: models.FrequencyOnce, StartAt: start}
+	assert.True(t, once.HasOccurrence(0))
+	assert.False(t, once.HasOccurrence(1))
+}
+
+// TestScheduledTransfer_RunsEachOccurrenceOnce verifies due schedules run once per occurrence and advance
+func TestScheduledTransfer_RunsEachOccurrenceOnce(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+	walletRepo := repositories.NewWalletRepository()
+
+	clock := time.Date(2026, 1, 30, 9, 0, 0, 0, time.UTC)
+	service := NewScheduledTransferService(NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository()))
+	service.now = func() time.Time { return clock }
+
+	schedule, err := service.Create(alice.ID, models.CreateScheduledTransferRequest{ToUserID: bob.ID, CurrencyID: usdt.ID, Amount: dec("100"), Frequency: models.FrequencyDaily, StartAt: clock.Add(time.Hour)})
+	assert.NoError(t, err)
+
+	// 尚未到期
+	completed, err := service.ProcessDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, completed)
+
+	clock = clock.Add(time.Hour)
+	completed, err = service.ProcessDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, completed)
+
+	// 同一期不會再次執行
+	completed, err = service.ProcessDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, completed)
+	ok, err := service.runOccurrence(schedule.ID)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	aliceWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, usdt.ID)
+	assert.Equal(t, "900", aliceWallet.Balance.String())
+	bobWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(bob.ID, usdt.ID)
+	assert.Equal(t, "100", bobWallet.Balance.String())
+
+	updated, err := service.Get(alice.ID, schedule.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, updated.Occurrence)
+	assert.Equal(t, time.Date(2026, 1, 31, 10, 0, 0, 0, time.UTC), updated.NextRunAt.UTC())
+
+	runs, err := service.Runs(alice.ID, schedule.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, runs, 1) {
+		assert.Equal(t, models.ScheduleRunCompleted, runs[0].Status)
+		assert.NotNil(t, runs[0].TransactionID)
+	}
+
+	// 其他用戶看不到這個排程
+	_, err = service.Get(bob.ID, schedule.ID)
+	assert.ErrorIs(t, err, ErrScheduledTransferNotFound)
+}
+
+// TestScheduledTransfer_OnceCompletes verifies one-off schedules complete after running
+func TestScheduledTransfer_OnceCompletes(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+	walletRepo := repositories.NewWalletRepository()
+
+	clock := time.Date(2026, 1, 30, 9, 0, 0, 0, time.UTC)
+	service := NewScheduledTransferService(NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository()))
+	service.now = func() time.Time { return clock }
+
+	schedule, err := service.Create(alice.ID, models.CreateScheduledTransferRequest{ToUserID: bob.ID, CurrencyID: usdt.ID, Amount: dec("250"), Frequency: models.FrequencyOnce, StartAt: clock})
+	assert.NoError(t, err)
+
+	completed, err := service.ProcessDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, completed)
+
+	updated, _ := service.Get(alice.ID, schedule.ID)
+	assert.Equal(t, models.ScheduleStatusCompleted, updated.Status)
+	aliceWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, usdt.ID)
+	assert.Equal(t, "750", aliceWallet.Balance.String())
+
+	_, err = service.Update(alice.ID, schedule.ID, models.UpdateScheduledTransferRequest{Amount: &schedule.Amount})
+	assert.ErrorIs(t, err, ErrScheduledTransferClosed)
+}
+
+// TestScheduledTransfer_RetriesThenDisablesOnInsufficientBalance verifies failures are recorded,
+// retried with backoff and disable the schedule after repeated insufficient-balance errors
+func TestScheduledTransfer_RetriesThenDisablesOnInsufficientBalance(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+	walletRepo := repositories.NewWalletRepository()
+
+	clock := time.Date(2026, 1, 30, 9, 0, 0, 0, time.UTC)
+	service := NewScheduledTransferService(NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository()))
+	service.now = func() time.Time { return clock }
+
+	schedule, err := service.Create(alice.ID, models.CreateScheduledTransferRequest{ToUserID: bob.ID, CurrencyID: usdt.ID, Amount: dec("5000"), Frequency: models.FrequencyMonthly, StartAt: clock})
+	assert.NoError(t, err)
+
+	_, err = service.ProcessDue(context.Background())
+	assert.NoError(t, err)
+
+	updated, _ := service.Get(alice.ID, schedule.ID)
+	assert.Equal(t, models.ScheduleStatusActive, updated.Status)
+	assert.Equal(t, 1, updated.ConsecutiveFailures)
+	assert.Equal(t, clock.Add(defaultScheduleRetryBackoff), updated.NextAttemptAt.UTC())
+	assert.Contains(t, updated.LastError, "insufficient balance")
+
+	// 還在退避期間不會重試
+	clock = clock.Add(time.Minute)
+	_, _ = service.ProcessDue(context.Background())
+	updated, _ = service.Get(alice.ID, schedule.ID)
+	assert.Equal(t, 1, updated.ConsecutiveFailures)
+
+	for i := 0; i < defaultScheduleDisableAfter-1; i++ {
+		clock = clock.Add(defaultScheduleMaxRetryBackoff)
+		_, _ = service.ProcessDue(context.Background())
+	}
+
+	updated, _ = service.Get(alice.ID, schedule.ID)
+	assert.Equal(t, models.ScheduleStatusDisabled, updated.Status)
+	runs, _ := service.Runs(alice.ID, schedule.ID)
+	if assert.Len(t, runs, 1) {
+		assert.Equal(t, models.ScheduleRunFailed, runs[0].Status)
+		assert.Equal(t, defaultScheduleDisableAfter, runs[0].Attempts)
+		assert.Nil(t, runs[0].TransactionID)
+	}
+	aliceWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, usdt.ID)
+	assert.Equal(t, "1000", aliceWallet.Balance.String())
+
+	// 停用後不再執行；降低金額並恢復後，錯過的這一期被略過，從下一期開始
+	clock = time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	active := true
+	lower := dec("100")
+	updated, err = service.Update(alice.ID, schedule.ID, models.UpdateScheduledTransferRequest{Amount: &lower, Active: &active})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ScheduleStatusActive, updated.Status)
+	assert.Equal(t, 0, updated.ConsecutiveFailures)
+	assert.Equal(t, time.Date(2026, 2, 28, 9, 0, 0, 0, time.UTC), updated.NextRunAt.UTC())
+
+	clock = updated.NextRunAt
+	completed, err := service.ProcessDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, completed)
+	aliceWallet, _ = walletRepo.GetWalletByUserIDAndCurrency(alice.ID, usdt.ID)
+	assert.Equal(t, "900", aliceWallet.Balance.String())
+}
+
+// TestScheduledTransfer_SkipsOccurrenceAfterMaxAttempts verifies non-balance failures eventually skip to the next occurrence
+func TestScheduledTransfer_SkipsOccurrenceAfterMaxAttempts(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	clock := time.Date(2026, 1, 30, 9, 0, 0, 0, time.UTC)
+	service := NewScheduledTransferService(NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository()))
+	service.now = func() time.Time { return clock }
+
+	schedule, err := service.Create(alice.ID, models.CreateScheduledTransferRequest{ToUserID: bob.ID, CurrencyID: usdt.ID, Amount: dec("100"), Frequency: models.FrequencyWeekly, StartAt: clock})
+	assert.NoError(t, err)
+	_, err = NewLimitService().SetCurrencyLimit(usdt.ID, models.TransferLimitRequest{MaxSingleAmount: dec("50")})
+	assert.NoError(t, err)
+
+	for i := 0; i < defaultScheduleMaxAttempts; i++ {
+		_, _ = service.ProcessDue(context.Background())
+		clock = clock.Add(defaultScheduleMaxRetryBackoff)
+	}
+
+	updated, _ := service.Get(alice.ID, schedule.ID)
+	assert.Equal(t, models.ScheduleStatusActive, updated.Status)
+	assert.Equal(t, 1, updated.Occurrence)
+	assert.Equal(t, 0, updated.ConsecutiveFailures)
+	assert.Equal(t, time.Date(2026, 2, 6, 9, 0, 0, 0, time.UTC), updated.NextRunAt.UTC())
+
+	runs, _ := service.Runs(alice.ID, schedule.ID)
+	if assert.Len(t, runs, 1) {
+		assert.Equal(t, models.ScheduleRunSkipped, runs[0].Status)
+		assert.Contains(t, runs[0].Error, "single transfer limit")
+	}
+}
+
+// TestScheduledTransfer_Validation verifies creation rules and cancellation
+func TestScheduledTransfer_Validation(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	clock := time.Date(2026, 1, 30, 9, 0, 0, 0, time.UTC)
+	service := NewScheduledTransferService(NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository()))
+	service.now = func() time.Time { return clock }
+
+	req := models.CreateScheduledTransferRequest{
+		ToUserID:   bob.ID,
+		CurrencyID: usdt.ID,
+		Amount:     dec("10"),
+		Frequency:  models.FrequencyDaily,
+		StartAt:    clock.Add(-time.Hour),
+	}
+	_, err := service.Create(alice.ID, req)
+	assert.ErrorIs(t, err, ErrInvalidScheduledTransfer)
+
+	req.StartAt = clock.Add(time.Hour)
+	endAt := clock
+	req.EndAt = &endAt
+	_, err = service.Create(alice.ID, req)
+	assert.ErrorIs(t, err, ErrInvalidScheduledTransfer)
+
+	req.EndAt = nil
+	req.ToUserID = 999
+	_, err = service.Create(alice.ID, req)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	req.ToUserID = alice.ID
+	_, err = service.Create(alice.ID, req)
+	assert.Error(t, err)
+
+	schedule, err := service.Create(alice.ID, models.CreateScheduledTransferRequest{ToUserID: bob.ID, CurrencyID: usdt.ID, Amount: dec("10"), Frequency: models.FrequencyDaily, StartAt: clock.Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, service.Cancel(bob.ID, schedule.ID), ErrScheduledTransferNotFound)
+	assert.NoError(t, service.Cancel(alice.ID, schedule.ID))
+
+	schedules, err := service.List(alice.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, schedules)
+
+	clock = clock.Add(2 * time.Hour)
+	completed, err := service.ProcessDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, completed)
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/internal/rates"
+	"mini-crypto-wallet-api/kafka_client"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"mini-crypto-wallet-api/utils"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultSwapSpreadBps      = 30
+	DefaultSwapQuoteTTL       = 30 * time.Second
+	DefaultSwapMaxSlippageBps = 50
+)
+
+var (
+	ErrSameCurrencySwap   = errors.New("cannot swap a currency into itself")
+	ErrSwapAmountTooSmall = errors.New("amount is too small to swap")
+	ErrQuoteNotFound      = errors.New("swap quote not found")
+	ErrQuoteExpired       = errors.New("swap quote expired")
+	ErrQuoteUsed          = errors.New("swap quote already executed")
+	ErrSlippageExceeded   = errors.New("rate moved beyond the allowed slippage")
+)
+
+var basisPoints = decimal.NewFromInt(10000)
+
+// SwapOptions 兌換的點差、報價有效期與精度策略，零值使用預設值
+type SwapOptions struct {
+	SpreadBps       int
+	QuoteTTL        time.Duration
+	PrecisionPolicy PrecisionPolicy // 兌換金額超過來源幣種精度時的處理方式，零值為 reject
+}
+
+// SwapService 同一用戶不同幣種錢包之間的兌換
+// 先取得報價（含有效期限），執行時以即時匯率計算，比報價差超過允許的滑價即拒絕
+// 點差手續費以來源幣種計入 system_fees 帳戶
+type SwapService struct {
+	walletRepo         repositories.IWallet
+	transactionRepo    repositories.ITransaction
+	balanceHistoryRepo repositories.IBalanceHistory
+	currencyRepo       repositories.ICurrency
+	swapRepo           repositories.ISwap
+	outboxRepo         repositories.IOutbox
+	userRepo           repositories.IUser
+	ledger             *LedgerService
+	rates              rates.Provider
+	spreadBps          int
+	quoteTTL           time.Duration
+	precision          PrecisionPolicy
+	now                func() time.Time
+}
+
+func NewSwapService(walletRepo repositories.IWallet, txRepo repositories.ITransaction, rateProvider rates.Provider, opts SwapOptions) *SwapService {
+	if opts.SpreadBps <= 0 {
+		opts.SpreadBps = DefaultSwapSpreadBps
+	}
+	if opts.QuoteTTL <= 0 {
+		opts.QuoteTTL = DefaultSwapQuoteTTL
+	}
+
+	return &SwapService{
+		walletRepo:         walletRepo,
+		transactionRepo:    txRepo,
+		balanceHistoryRepo: repositories.NewBalanceHistoryRepository(),
+		currencyRepo:       repositories.NewCurrencyRepository(),
+		swapRepo:           repositories.NewSwapRepository(),
+		outboxRepo:         repositories.NewOutboxRepository(),
+		userRepo:           repositories.NewUserRepository(),
+		ledger:             NewLedgerService(),
+		rates:              rateProvider,
+		spreadBps:          opts.SpreadBps,
+		quoteTTL:           opts.QuoteTTL,
+		precision:          opts.PrecisionPolicy,
+		now:                time.Now,
+	}
+}
+
+// Quote 為兌換報價並保存，報價在有效期內只能執行一次
+func (s *SwapService) Quote(userID uint, req models.SwapQuoteRequest) (*models.SwapQuote, error) {
+	if req.FromCurrencyID == req.ToCurrencyID {
+		return nil, ErrSameCurrencySwap
+	}
+	if !utils.ValidatePositiveAmount(req.Amount) {
+		return nil, errors.New("amount must be positive")
+	}
+
+	from, to, err := s.currencies(req.FromCurrencyID, req.ToCurrencyID)
+	if err != nil {
+		return nil, err
+	}
+	amountIn, err := s.precision.apply(from, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := s.rates.Rate(from.Code, to.Code)
+	if err != nil {
+		return nil, err
+	}
+	fee, amountOut := s.price(from, to, amountIn, rate)
+	if !amountOut.IsPositive() {
+		return nil, ErrSwapAmountTooSmall
+	}
+
+	quoteID, err := newQuoteID()
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &models.SwapQuote{
+		QuoteID:        quoteID,
+		UserID:         userID,
+		FromCurrencyID: from.ID,
+		ToCurrencyID:   to.ID,
+		AmountIn:       amountIn,
+		Rate:           rate,
+		Fee:            fee,
+		AmountOut:      amountOut,
+		ExpiresAt:      s.now().Add(s.quoteTTL),
+	}
+	if err := s.swapRepo.CreateQuote(quote); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+// Swap 執行報價，maxSlippageBps 為 nil 時使用預設值
+func (s *SwapService) Swap(userID uint, quoteID string, maxSlippageBps *int) (*models.SwapQuote, *models.Transaction, error) {
+	slippage := DefaultSwapMaxSlippageBps
+	if maxSlippageBps != nil {
+		slippage = *maxSlippageBps
+	}
+
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	quote, transaction, err := s.swapInTx(tx, userID, quoteID, slippage)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return nil, nil, commitDB.Error
+	}
+	streamCommitted(s.walletRepo, s.balanceHistoryRepo, transaction, quote.FromCurrencyID, quote.ToCurrencyID)
+
+	return quote, transaction, nil
+}
+
+func (s *SwapService) swapInTx(tx *gorm.DB, userID uint, quoteID string, slippageBps int) (*models.SwapQuote, *models.Transaction, error) {
+	quote, err := s.swapRepo.FindQuoteByQuoteIDWithTx(quoteID, tx)
+	if err != nil || quote.UserID != userID {
+		return nil, nil, ErrQuoteNotFound
+	}
+	if quote.ExecutedAt != nil {
+		return nil, nil, ErrQuoteUsed
+	}
+	now := s.now()
+	if quote.IsExpired(now) {
+		return nil, nil, ErrQuoteExpired
+	}
+
+	from, to, err := s.currencies(quote.FromCurrencyID, quote.ToCurrencyID, tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 以即時匯率計算，比報價少超過 slippageBps 即拒絕
+	rate, err := s.rates.Rate(from.Code, to.Code)
+	if err != nil {
+		return nil, nil, err
+	}
+	fee, amountOut := s.price(from, to, quote.AmountIn, rate)
+	minOut := quote.AmountOut.Mul(basisPoints.Sub(decimal.NewFromInt(int64(slippageBps)))).Div(basisPoints)
+	if !amountOut.IsPositive() || amountOut.LessThan(minOut) {
+		return nil, nil, ErrSlippageExceeded
+	}
+
+	fromWallet, toWallet, err := s.lockWallets(tx, userID, from.ID, to.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ensureNotFrozen(s.userRepo, userID); err != nil {
+		return nil, nil, err
+	}
+	if fromWallet.Balance.LessThan(quote.AmountIn) {
+		return nil, nil, ErrInsufficientBalance
+	}
+
+	fromBalanceBefore := fromWallet.Balance
+	toBalanceBefore := toWallet.Balance
+	fromWallet.Balance = fromWallet.Balance.Sub(quote.AmountIn)
+	toWallet.Balance = toWallet.Balance.Add(amountOut)
+
+	if err := s.walletRepo.UpdateWallet(fromWallet, tx); err != nil {
+		return nil, nil, err
+	}
+	if err := s.walletRepo.UpdateWallet(toWallet, tx); err != nil {
+		return nil, nil, err
+	}
+
+	transaction := &models.Transaction{
+		Type:       models.TxTypeSwap,
+		FromUserID: userID,
+		ToUserID:   userID,
+		CurrencyID: from.ID,
+		Amount:     quote.AmountIn,
+		Status:     models.TxStatusCompleted,
+		Reference:  quote.QuoteID,
+	}
+	sealTransaction(transaction)
+
+	if err := s.transactionRepo.CreateTransaction(transaction, tx); err != nil {
+		return nil, nil, err
+	}
+
+	// 複式記帳：來源幣種由兌換帳戶承接（扣除點差），目標幣種由兌換帳戶付出
+	legs := []ledgerLeg{
+		walletDebit(fromWallet, quote.AmountIn),
+		systemCredit(models.LedgerKindExchange, from.ID, quote.AmountIn.Sub(fee)),
+	}
+	if fee.IsPositive() {
+		legs = append(legs, systemCredit(models.LedgerKindFees, from.ID, fee))
+	}
+	legs = append(legs,
+		systemDebit(models.LedgerKindExchange, to.ID, amountOut),
+		walletCredit(toWallet, amountOut),
+	)
+	if err := s.ledger.Post(tx, transaction.ID, models.JournalSwap, legs...); err != nil {
+		return nil, nil, err
+	}
+
+	// 兩筆餘額歷史以同一個 TransactionID 互相對應
+	if err := s.balanceHistoryRepo.CreateHistory(&models.BalanceHistory{
+		UserID:        userID,
+		WalletID:      fromWallet.ID,
+		TransactionID: transaction.ID,
+		ChangeType:    models.ChangeTypeDebit,
+		Status:        transaction.Status,
+		Amount:        quote.AmountIn,
+		BalanceBefore: fromBalanceBefore,
+		BalanceAfter:  fromWallet.Balance,
+	}, tx); err != nil {
+		return nil, nil, err
+	}
+	if err := s.balanceHistoryRepo.CreateHistory(&models.BalanceHistory{
+		UserID:        userID,
+		WalletID:      toWallet.ID,
+		TransactionID: transaction.ID,
+		ChangeType:    models.ChangeTypeCredit,
+		Status:        transaction.Status,
+		Amount:        amountOut,
+		BalanceBefore: toBalanceBefore,
+		BalanceAfter:  toWallet.Balance,
+	}, tx); err != nil {
+		return nil, nil, err
+	}
+
+	quote.ExecutedAt = &now
+	quote.TransactionID = transaction.ID
+	quote.ExecutedRate = rate
+	quote.ExecutedOut = amountOut
+	if err := s.swapRepo.UpdateQuote(quote, tx); err != nil {
+		return nil, nil, err
+	}
+
+	eventType, payload := txCreatedEvent(transaction, "")
+	if err := enqueueEvent(s.outboxRepo, kafka_client.TopicTxCreated, transaction.Hash, eventType, payload, transaction.CreatedAt, tx); err != nil {
+		return nil, nil, err
+	}
+
+	return quote, transaction, nil
+}
+
+// price 計算點差手續費（來源幣種，無條件進位）與可得金額（目標幣種，無條件捨去）
+func (s *SwapService) price(from, to *models.Currency, amountIn, rate decimal.Decimal) (decimal.Decimal, decimal.Decimal) {
+	fee := amountIn.Mul(decimal.NewFromInt(int64(s.spreadBps))).Div(basisPoints).RoundUp(int32(from.Decimals))
+	amountOut := amountIn.Sub(fee).Mul(rate).RoundDown(int32(to.Decimals))
+	return fee, amountOut
+}
+
+// currencies 取得兌換的來源與目標幣種，兩者都必須為啟用狀態；傳入 tx 時以共享鎖讀取
+func (s *SwapService) currencies(fromID, toID uint, tx ...*gorm.DB) (*models.Currency, *models.Currency, error) {
+	from, err := activeCurrency(s.currencyRepo, fromID, tx...)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := activeCurrency(s.currencyRepo, toID, tx...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return from, to, nil
+}
+
+// lockWallets 鎖定用戶的來源與目標錢包
+// 一律依 currency_id 由小到大加鎖，避免兩筆反向兌換互相等待造成死鎖
+func (s *SwapService) lockWallets(tx *gorm.DB, userID, fromCurrencyID, toCurrencyID uint) (*models.Wallet, *models.Wallet, error) {
+	firstID, secondID := fromCurrencyID, toCurrencyID
+	if secondID < firstID {
+		firstID, secondID = secondID, firstID
+	}
+
+	first, err := s.walletRepo.GetWalletByUserIDAndCurrencyWithTx(userID, firstID, tx)
+	if err != nil {
+		return nil, nil, ErrWalletNotFound
+	}
+	second, err := s.walletRepo.GetWalletByUserIDAndCurrencyWithTx(userID, secondID, tx)
+	if err != nil {
+		return nil, nil, ErrWalletNotFound
+	}
+
+	if firstID == fromCurrencyID {
+		return first, second, nil
+	}
+	return second, first, nil
+}
+
+// newQuoteID 產生 128-bit 隨機報價 ID
+func newQuoteID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/internal/rates"
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSwap_DebitsCreditsAndPostsSpreadFee verifies balances, the linked history pair and the fee posting
+func TestSwap_DebitsCreditsAndPostsSpreadFee(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	btc := test.CreateTestCurrency(db, "BTC")
+	alice := test.CreateTestUser(db, "alice")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 1000)
+	test.CreateTestWallet(db, alice.ID, btc.ID, 0)
+
+	provider := rates.NewStaticProvider(map[string]decimal.Decimal{"BTC/USDT": decimal.NewFromInt(50000)})
+	service := NewSwapService(repositories.NewWalletRepository(), repositories.NewTransactionRepository(), provider, SwapOptions{})
+	quote, err := service.Quote(alice.ID, models.SwapQuoteRequest{FromCurrencyID: usdt.ID, ToCurrencyID: btc.ID, Amount: decimal.NewFromInt(100)})
+	assert.NoError(t, err)
+	assert.Equal(t, "0.3", quote.Fee.String())
+	assert.Equal(t, "0.001994", quote.AmountOut.String())
+
+	executed, transaction, err := service.Swap(alice.ID, quote.QuoteID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TxTypeSwap, transaction.Type)
+	assert.Equal(t, transaction.ID, executed.TransactionID)
+	assert.Equal(t, "0.001994", executed.ExecutedOut.String())
+
+	walletRepo := repositories.NewWalletRepository()
+	usdtWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, usdt.ID)
+	btcWallet, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, btc.ID)
+	assert.Equal(t, "900", usdtWallet.Balance.String())
+	assert.Equal(t, "0.001994", btcWallet.Balance.String())
+
+	histories, err := repositories.NewBalanceHistoryRepository().GetHistoryByTransactionID(transaction.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, histories, 2) {
+		byWallet := map[uint]models.BalanceHistory{histories[0].WalletID: histories[0], histories[1].WalletID: histories[1]}
+		assert.Equal(t, models.ChangeTypeDebit, byWallet[usdtWallet.ID].ChangeType)
+		assert.Equal(t, "100", byWallet[usdtWallet.ID].Amount.String())
+		assert.Equal(t, models.ChangeTypeCredit, byWallet[btcWallet.ID].ChangeType)
+		assert.Equal(t, "0.001994", byWallet[btcWallet.ID].Amount.String())
+	}
+
+	fees, err := repositories.NewLedgerRepository().FindAccountByCodeWithTx(models.SystemAccountCode(models.LedgerKindFees, usdt.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, "0.3", fees.Balance.String())
+
+	run, err := newTestReconciliationService().Run(models.ReconciliationTriggerManual)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, run.FindingCount)
+
+	// 報價只能執行一次
+	_, _, err = service.Swap(alice.ID, quote.QuoteID, nil)
+	assert.ErrorIs(t, err, ErrQuoteUsed)
+}
+
+// TestSwap_RejectsExpiredAndForeignQuotes verifies quotes expire and belong to the requesting user
+func TestSwap_RejectsExpiredAndForeignQuotes(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	btc := test.CreateTestCurrency(db, "BTC")
+	alice := test.CreateTestUser(db, "alice")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 1000)
+	test.CreateTestWallet(db, alice.ID, btc.ID, 0)
+
+	provider := rates.NewStaticProvider(map[string]decimal.Decimal{"BTC/USDT": decimal.NewFromInt(50000)})
+	service := NewSwapService(repositories.NewWalletRepository(), repositories.NewTransactionRepository(), provider, SwapOptions{})
+	bob := test.CreateTestUser(db, "bob")
+	quote, err := service.Quote(alice.ID, models.SwapQuoteRequest{FromCurrencyID: usdt.ID, ToCurrencyID: btc.ID, Amount: decimal.NewFromInt(100)})
+	assert.NoError(t, err)
+
+	_, _, err = service.Swap(bob.ID, quote.QuoteID, nil)
+	assert.ErrorIs(t, err, ErrQuoteNotFound)
+
+	service.now = func() time.Time { return time.Now().Add(DefaultSwapQuoteTTL) }
+	_, _, err = service.Swap(alice.ID, quote.QuoteID, nil)
+	assert.ErrorIs(t, err, ErrQuoteExpired)
+
+	_, err = service.Quote(alice.ID, models.SwapQuoteRequest{FromCurrencyID: usdt.ID, ToCurrencyID: usdt.ID, Amount: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, ErrSameCurrencySwap)
+
+	eth := test.CreateTestCurrency(db, "ETH")
+	_, err = service.Quote(alice.ID, models.SwapQuoteRequest{FromCurrencyID: usdt.ID, ToCurrencyID: eth.ID, Amount: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, rates.ErrRateUnavailable)
+}
+
+// TestSwap_Fail_InsufficientBalanceAndMissingWallet verifies the shared sentinels are returned so handlers can map them
+func TestSwap_Fail_InsufficientBalanceAndMissingWallet(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	btc := test.CreateTestCurrency(db, "BTC")
+	alice := test.CreateTestUser(db, "alice")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 1000)
+	test.CreateTestWallet(db, alice.ID, btc.ID, 0)
+
+	provider := rates.NewStaticProvider(map[string]decimal.Decimal{"BTC/USDT": decimal.NewFromInt(50000)})
+	service := NewSwapService(repositories.NewWalletRepository(), repositories.NewTransactionRepository(), provider, SwapOptions{})
+	quote, err := service.Quote(alice.ID, models.SwapQuoteRequest{FromCurrencyID: usdt.ID, ToCurrencyID: btc.ID, Amount: decimal.NewFromInt(2000)})
+	assert.NoError(t, err)
+	_, _, err = service.Swap(alice.ID, quote.QuoteID, nil)
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+
+	eth := test.CreateTestCurrency(db, "ETH")
+	provider.Set("ETH/USDT", decimal.NewFromInt(2500))
+	quote, err = service.Quote(alice.ID, models.SwapQuoteRequest{FromCurrencyID: usdt.ID, ToCurrencyID: eth.ID, Amount: decimal.NewFromInt(100)})
+	assert.NoError(t, err)
+	_, _, err = service.Swap(alice.ID, quote.QuoteID, nil)
+	assert.ErrorIs(t, err, ErrWalletNotFound)
+}
+
+// TestSwap_SlippageCheck verifies the live amount may only be worse than the quote within the allowed slippage
+func TestSwap_SlippageCheck(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	btc := test.CreateTestCurrency(db, "BTC")
+	alice := test.CreateTestUser(db, "alice")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 1000)
+	test.CreateTestWallet(db, alice.ID, btc.ID, 0)
+
+	provider := rates.NewStaticProvider(map[string]decimal.Decimal{"BTC/USDT": decimal.NewFromInt(50000)})
+	service := NewSwapService(repositories.NewWalletRepository(), repositories.NewTransactionRepository(), provider, SwapOptions{})
+	quote, err := service.Quote(alice.ID, models.SwapQuoteRequest{FromCurrencyID: usdt.ID, ToCurrencyID: btc.ID, Amount: decimal.NewFromInt(100)})
+	assert.NoError(t, err)
+
+	// BTC 漲 2%，同樣的 USDT 換到的 BTC 變少
+	provider.Set("BTC/USDT", decimal.NewFromInt(51000))
+
+	_, _, err = service.Swap(alice.ID, quote.QuoteID, nil)
+	assert.ErrorIs(t, err, ErrSlippageExceeded)
+
+	tolerance := 300
+	executed, _, err := service.Swap(alice.ID, quote.QuoteID, &tolerance)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.00195490", executed.ExecutedOut.StringFixed(8))
+	assert.True(t, executed.ExecutedOut.LessThan(quote.AmountOut))
+}
+
+// TestSwap_ConcurrentOppositeDirections verifies X→Y and Y→X swaps sharing the exchange accounts both complete
+func TestSwap_ConcurrentOppositeDirections(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	btc := test.CreateTestCurrency(db, "BTC")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 1000)
+	test.CreateTestWallet(db, alice.ID, btc.ID, 0)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+	test.CreateTestWallet(db, bob.ID, btc.ID, 1)
+
+	provider := rates.NewStaticProvider(map[string]decimal.Decimal{"BTC/USDT": decimal.NewFromInt(50000)})
+	service := NewSwapService(repositories.NewWalletRepository(), repositories.NewTransactionRepository(), provider, SwapOptions{})
+
+	const rounds = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*rounds)
+	swap := func(userID, fromID, toID uint, amount string) {
+		defer wg.Done()
+		quote, err := service.Quote(userID, models.SwapQuoteRequest{FromCurrencyID: fromID, ToCurrencyID: toID, Amount: decimal.RequireFromString(amount)})
+		if err == nil {
+			_, _, err = service.Swap(userID, quote.QuoteID, nil)
+		}
+		errs <- err
+	}
+	for i := 0; i < rounds; i++ {
+		wg.Add(2)
+		go swap(alice.ID, usdt.ID, btc.ID, "100")
+		go swap(bob.ID, btc.ID, usdt.ID, "0.001")
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	var count int64
+	db.Model(&models.Transaction{}).Where("type = ?", models.TxTypeSwap).Count(&count)
+	assert.Equal(t, int64(2*rounds), count)
+	assertLedgerBalanced(t, db)
+	assertReconciled(t)
+}
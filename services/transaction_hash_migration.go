@@ -0,0 +1,57 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/utils"
+)
+
+// hashMigrationBatchSize 每個 DB 交易遷移的交易筆數
+const hashMigrationBatchSize = 200
+
+// MigrateLegacyHashes 將舊的隨機 hash 改為 content-addressed hash，回傳遷移的筆數
+// 每筆交易補上 nonce 後重新計算 hash，created_at 維持原值；舊 hash 保留在 legacy_hash，原本的連結與外部引用仍可查到交易
+// 不以目前的金鑰重新簽章：舊交易從未被這把金鑰簽過，補簽等於替未驗證過的內容背書，驗證時會回報為無法驗證的舊簽章
+// 分批在各自的 DB 交易中進行，中斷後重新執行會從尚未遷移的交易繼續
+func (s *TransactionService) MigrateLegacyHashes() (int, error) {
+	migrated := 0
+	for {
+		n, err := s.migrateLegacyHashBatch(hashMigrationBatchSize)
+		migrated += n
+		if err != nil {
+			return migrated, err
+		}
+		if n < hashMigrationBatchSize {
+			return migrated, nil
+		}
+	}
+}
+
+func (s *TransactionService) migrateLegacyHashBatch(limit int) (int, error) {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	transactions, err := s.transactionRepo.FindLegacyHashTransactions(limit, tx)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	for i := range transactions {
+		transaction := &transactions[i]
+		transaction.LegacyHash = transaction.Hash
+		transaction.Nonce = newTransactionNonce()
+		transaction.HashVersion = models.HashVersionContent
+		transaction.Hash = transaction.GenerateHash()
+
+		if err := s.transactionRepo.UpdateTransaction(transaction, tx); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+	return len(transactions), nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTransactionHash_RecomputableFromStoredRow verifies hashes are content-addressed and tampering is reported
+func TestTransactionHash_RecomputableFromStoredRow(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	service := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	first, err := service.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("10"))
+	assert.NoError(t, err)
+	second, err := service.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("10"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Hash, second.Hash, "identical transfers differ by nonce")
+
+	stored, err := service.GetTransactionByHash(first.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, models.HashVersionContent, stored.HashVersion)
+	assert.Len(t, stored.Nonce, 32)
+	assert.True(t, stored.HashMatches())
+	assert.Equal(t, first.Hash, stored.GenerateHash())
+
+	run, err := newTestReconciliationService().Run(models.ReconciliationTriggerManual)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, run.FindingCount)
+
+	assert.NoError(t, db.Model(&models.Transaction{}).Where("id = ?", first.ID).Update("nonce", "00").Error)
+	run, err = newTestReconciliationService().Run(models.ReconciliationTriggerManual)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, run.FindingCount)
+	var finding models.ReconciliationFinding
+	assert.NoError(t, db.Where("run_id = ?", run.ID).First(&finding).Error)
+	assert.Equal(t, models.FindingHashMismatch, finding.Type)
+	assert.Equal(t, first.ID, finding.TransactionID)
+
+	// 簽章仍然有效，但 hash 無法由內容重算
+	result, err := service.VerifyTransaction(first.Hash)
+	assert.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.False(t, result.HashValid)
+	assert.Equal(t, "hash does not match the transaction record", result.Reason)
+}
+
+// TestMigrateLegacyHashes_RehashesAndKeepsOldHashResolvable verifies the migration path for legacy rows
+func TestMigrateLegacyHashes_RehashesAndKeepsOldHashResolvable(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+
+	legacyHashes := []string{"1111111111111111111111111111111111111111111111111111111111111111", "2222222222222222222222222222222222222222222222222222222222222222"}
+	for i, hash := range legacyHashes {
+		assert.NoError(t, db.Create(&models.Transaction{
+			Type:       models.TxTypeTransfer,
+			FromUserID: alice.ID,
+			ToUserID:   bob.ID,
+			CurrencyID: usdt.ID,
+			Amount:     dec("5"),
+			Hash:       hash,
+			Signature:  "SIG-1-5-0",
+			Status:     models.TxStatusCompleted,
+			CreatedAt:  time.Date(2025, 3, 1, 8, 0, i, 123456789, time.UTC),
+		}).Error)
+	}
+
+	service := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	result, err := service.VerifyTransaction(legacyHashes[0])
+	assert.NoError(t, err)
+	assert.False(t, result.Valid)
+
+	migrated, err := service.MigrateLegacyHashes()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, migrated)
+
+	for _, hash := range legacyHashes {
+		transaction, err := service.GetTransactionByHash(hash)
+		if !assert.NoError(t, err, "old hash still resolves") {
+			continue
+		}
+		assert.Equal(t, hash, transaction.LegacyHash)
+		assert.NotEqual(t, hash, transaction.Hash)
+		assert.True(t, transaction.HashMatches())
+		assert.Equal(t, "SIG-1-5-0", transaction.Signature, "legacy rows are not re-signed")
+		assert.Empty(t, transaction.SignatureKID)
+
+		result, err := service.VerifyTransaction(transaction.Hash)
+		assert.NoError(t, err)
+		assert.True(t, result.HashValid)
+		assert.False(t, result.Valid)
+		assert.Equal(t, "hash was migrated from a legacy transaction whose signature cannot be verified", result.Reason)
+	}
+
+	var stored models.Transaction
+	assert.NoError(t, db.Where("legacy_hash = ?", legacyHashes[1]).First(&stored).Error)
+	assert.True(t, stored.CreatedAt.Equal(time.Date(2025, 3, 1, 8, 0, 1, 123456789, time.UTC)), "created_at is not rewritten")
+
+	migrated, err = service.MigrateLegacyHashes()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, migrated)
+}
@@ -1,36 +1,195 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
 	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/internal/events"
 	"mini-crypto-wallet-api/kafka_client"
 	"mini-crypto-wallet-api/models"
 	"mini-crypto-wallet-api/repositories"
 	"mini-crypto-wallet-api/utils"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// IdempotencyKeyTTL 保存 Idempotency-Key 結果的時間
+const IdempotencyKeyTTL = 24 * time.Hour
+
+var (
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key was already used with a different request")
+	ErrInsufficientBalance    = errors.New("insufficient balance")
 )
 
 type TransactionService struct {
 	walletRepo         repositories.IWallet
 	transactionRepo    repositories.ITransaction
 	balanceHistoryRepo repositories.IBalanceHistory
-	kafkaProducer      *kafka_client.KafkaProducer
+	outboxRepo         repositories.IOutbox
+	idempotencyRepo    repositories.IIdempotency
+	userRepo           repositories.IUser
+	currencyRepo       repositories.ICurrency
+	ledger             *LedgerService
+	fees               *FeeService
+	limits             *LimitService
+	precision          PrecisionPolicy
 }
 
-func NewTransactionService(walletRepo repositories.IWallet, txRepo repositories.ITransaction, producer *kafka_client.KafkaProducer) *TransactionService {
+func NewTransactionService(walletRepo repositories.IWallet, txRepo repositories.ITransaction) *TransactionService {
 	return &TransactionService{
 		walletRepo:         walletRepo,
 		transactionRepo:    txRepo,
 		balanceHistoryRepo: repositories.NewBalanceHistoryRepository(),
-		kafkaProducer:      producer,
+		outboxRepo:         repositories.NewOutboxRepository(),
+		idempotencyRepo:    repositories.NewIdempotencyRepository(),
+		userRepo:           repositories.NewUserRepository(),
+		currencyRepo:       repositories.NewCurrencyRepository(),
+		ledger:             NewLedgerService(),
+		fees:               NewFeeService(),
+		limits:             NewLimitService(),
 	}
 }
 
+// WithPrecisionPolicy 設定轉帳、退款與手續費試算的精度策略，回傳 s 方便串接
+func (s *TransactionService) WithPrecisionPolicy(policy PrecisionPolicy) *TransactionService {
+	s.precision = policy
+	s.fees.WithPrecisionPolicy(policy)
+	return s
+}
+
 func (s *TransactionService) Transfer(fromID, toID uint, currencyID uint, amount decimal.Decimal) error {
+	_, err := s.TransferWithResult(fromID, toID, currencyID, amount)
+	return err
+}
+
+// QuoteTransfer 試算轉帳手續費，結果與實際轉帳收取的相同
+func (s *TransactionService) QuoteTransfer(currencyID uint, amount decimal.Decimal) (*models.TransferQuoteResponse, error) {
+	return s.fees.Quote(currencyID, amount)
+}
+
+// TransferWithResult 執行轉帳並回傳建立的交易紀錄
+func (s *TransactionService) TransferWithResult(fromID, toID uint, currencyID uint, amount decimal.Decimal) (*models.Transaction, error) {
+	if err := validateTransfer(fromID, toID, amount); err != nil {
+		return nil, err
+	}
+
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	transaction, err := s.transferInTx(tx, fromID, toID, currencyID, amount)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return nil, commitDB.Error
+	}
+	streamCommitted(s.walletRepo, s.balanceHistoryRepo, transaction)
+
+	return transaction, nil
+}
+
+// TransferIdempotent 以 Idempotency-Key 執行轉帳
+// 同一用戶在保留期內重送相同 key 與相同內容時，直接回傳第一次的結果（replayed = true）
+// 相同 key 但內容不同時回傳 ErrIdempotencyKeyMismatch
+// 轉帳失敗時不會保存 key，用戶可使用同一個 key 重試
+func (s *TransactionService) TransferIdempotent(key string, req *models.TransferRequest) (*models.TransferResponse, bool, error) {
+	fingerprint := transferFingerprint(req)
+	now := time.Now()
+
+	if response, found, err := s.replayIdempotent(req.FromUserID, key, fingerprint, now); found || err != nil {
+		return response, found, err
+	}
+
+	if err := validateTransfer(req.FromUserID, req.ToUserID, req.Amount); err != nil {
+		return nil, false, err
+	}
+
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	if err := s.idempotencyRepo.DeleteExpiredKey(req.FromUserID, key, now, tx); err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+
+	transaction, err := s.transferInTx(tx, req.FromUserID, req.ToUserID, req.CurrencyID, req.Amount)
+	if err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+
+	response := models.ToTransferResponse(transaction)
+	body, err := json.Marshal(response)
+	if err != nil {
+		tx.Rollback()
+		return nil, false, err
+	}
+
+	record := &models.IdempotencyKey{
+		UserID:       req.FromUserID,
+		Key:          key,
+		RequestHash:  fingerprint,
+		StatusCode:   http.StatusOK,
+		ResponseBody: string(body),
+		ExpiresAt:    now.Add(IdempotencyKeyTTL),
+	}
+	if err := s.idempotencyRepo.CreateKey(record, tx); err != nil {
+		// 同一個 key 的並發請求已先完成，改為回傳該請求的結果
+		tx.Rollback()
+		if response, found, replayErr := s.replayIdempotent(req.FromUserID, key, fingerprint, now); found || replayErr != nil {
+			return response, found, replayErr
+		}
+		return nil, false, err
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return nil, false, commitDB.Error
+	}
+	streamCommitted(s.walletRepo, s.balanceHistoryRepo, transaction)
+
+	return response, false, nil
+}
+
+// replayIdempotent 查詢已保存的 idempotency key，found 代表已有先前的結果
+// 只有確定沒有紀錄時才繼續轉帳，查詢失敗時回傳錯誤，避免 DB 異常時重複扣款
+func (s *TransactionService) replayIdempotent(userID uint, key, fingerprint string, now time.Time) (*models.TransferResponse, bool, error) {
+	record, err := s.idempotencyRepo.FindByUserAndKey(userID, key, now)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	if record.RequestHash != fingerprint {
+		return nil, false, ErrIdempotencyKeyMismatch
+	}
+
+	var response models.TransferResponse
+	if err := json.Unmarshal([]byte(record.ResponseBody), &response); err != nil {
+		return nil, false, err
+	}
+	return &response, true, nil
+}
+
+// transferFingerprint 計算轉帳請求的 SHA256 指紋
+func transferFingerprint(req *models.TransferRequest) string {
+	data := fmt.Sprintf("%d|%d|%d|%s", req.FromUserID, req.ToUserID, req.CurrencyID, req.Amount.String())
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])
+}
+
+// validateTransfer 檢查不需要查詢資料庫的轉帳參數
+func validateTransfer(fromID, toID uint, amount decimal.Decimal) error {
 	if fromID == toID {
 		return errors.New("cannot transfer to the same account")
 	}
@@ -40,62 +199,91 @@ func (s *TransactionService) Transfer(fromID, toID uint, currencyID uint, amount
 		return errors.New("amount must be positive")
 	}
 
-	tx := db_conn.Conn_DB.MasterDB.Begin()
-	defer utils.RollbackIfPanic(tx)
+	return nil
+}
 
-	// 使用幣種查詢錢包
-	fromWallet, err := s.walletRepo.GetWalletByUserIDAndCurrency(fromID, currencyID)
+// transferInTx 在既有的 DB 交易中執行轉帳，呼叫端負責 commit / rollback
+func (s *TransactionService) transferInTx(tx *gorm.DB, fromID, toID uint, currencyID uint, amount decimal.Decimal) (*models.Transaction, error) {
+	currency, err := activeCurrency(s.currencyRepo, currencyID, tx)
 	if err != nil {
-		return errors.New("from_user wallet not found for this currency")
+		return nil, err
 	}
-	toWallet, err := s.walletRepo.GetWalletByUserIDAndCurrency(toID, currencyID)
+
+	// 使用行鎖取得指定幣種的錢包
+	fromWallet, toWallet, err := s.lockWalletPair(tx, fromID, toID, currencyID)
 	if err != nil {
-		return errors.New("to_user wallet not found for this currency")
+		return nil, err
+	}
+
+	if err := ensureNotFrozen(s.userRepo, fromID); err != nil {
+		return nil, err
 	}
 
-	// 使用行鎖更新錢包
-	fromWalletLocked, err := s.walletRepo.GetWalletByUserIDWithTx(fromID, tx)
-	if err != nil || fromWalletLocked.CurrencyID != currencyID {
-		return errors.New("from_user wallet not found for this currency")
+	return s.transferLocked(tx, currency, fromWallet, toWallet, amount)
+}
+
+// transferLocked 在兩個錢包都已鎖定的情況下完成轉帳：檢查限額與餘額、更新錢包並寫入交易、分錄、歷史與 outbox
+func (s *TransactionService) transferLocked(tx *gorm.DB, currency *models.Currency, fromWallet, toWallet *models.Wallet, amount decimal.Decimal) (*models.Transaction, error) {
+	fromID, toID, currencyID := fromWallet.UserID, toWallet.UserID, currency.ID
+
+	amount, err := s.precision.apply(currency, amount)
+	if err != nil {
+		return nil, err
 	}
-	toWalletLocked, err := s.walletRepo.GetWalletByUserIDWithTx(toID, tx)
-	if err != nil || toWalletLocked.CurrencyID != currencyID {
-		return errors.New("to_user wallet not found for this currency")
+
+	// 手續費由轉出方另外支付
+	fee, err := s.fees.feeFor(currency, amount, tx)
+	if err != nil {
+		return nil, err
 	}
+	total := amount.Add(fee)
 
-	fromWallet = fromWalletLocked
-	toWallet = toWalletLocked
+	// 限額檢查需在鎖定轉出錢包後進行，避免併發轉帳同時通過
+	if err := s.limits.check(tx, fromID, currencyID, amount); err != nil {
+		return nil, err
+	}
 
 	// 使用 decimal 比較
-	if fromWallet.Balance.LessThan(amount) {
-		return errors.New("insufficient balance")
+	if fromWallet.Balance.LessThan(total) {
+		return nil, ErrInsufficientBalance
 	}
 
 	// 記錄變動前的餘額
 	fromBalanceBefore := fromWallet.Balance
 	toBalanceBefore := toWallet.Balance
 
-	fromWallet.Balance = fromWallet.Balance.Sub(amount)
+	fromWallet.Balance = fromWallet.Balance.Sub(total)
 	toWallet.Balance = toWallet.Balance.Add(amount)
 
 	if err := s.walletRepo.UpdateWallet(fromWallet, tx); err != nil {
-		return err
+		return nil, err
 	}
 	if err := s.walletRepo.UpdateWallet(toWallet, tx); err != nil {
-		return err
+		return nil, err
 	}
 
 	transaction := &models.Transaction{
+		Type:       models.TxTypeTransfer,
 		FromUserID: fromID,
 		ToUserID:   toID,
+		CurrencyID: currencyID,
 		Amount:     amount,
-		Status:     "completed",
+		Fee:        fee,
+		Status:     models.TxStatusCompleted,
 	}
-	transaction.Hash = transaction.GenerateHash()
-	transaction.Signature = transaction.GenerateSignature()
+	sealTransaction(transaction)
 
 	if err := s.transactionRepo.CreateTransaction(transaction, tx); err != nil {
-		return err
+		return nil, err
+	}
+
+	// 複式記帳：借記轉出錢包（含手續費）、貸記轉入錢包與手續費帳戶
+	legs := []ledgerLeg{walletDebit(fromWallet, total), walletCredit(toWallet, amount)}
+	if fee.IsPositive() {
+		legs = append(legs, systemCredit(models.LedgerKindFees, currencyID, fee))
+	}
+	if err := s.ledger.Post(tx, transaction.ID, models.JournalTransfer, legs...); err != nil {
+		return nil, err
 	}
 
 	// 記錄餘額變動歷史
@@ -103,46 +291,122 @@ func (s *TransactionService) Transfer(fromID, toID uint, currencyID uint, amount
 		UserID:        fromID,
 		WalletID:      fromWallet.ID,
 		TransactionID: transaction.ID,
-		ChangeType:    "debit",
-		Amount:        amount,
+		ChangeType:    models.ChangeTypeDebit,
+		Status:        transaction.Status,
+		Amount:        total,
 		BalanceBefore: fromBalanceBefore,
 		BalanceAfter:  fromWallet.Balance,
 	}
 	if err := s.balanceHistoryRepo.CreateHistory(fromHistory, tx); err != nil {
-		return err
+		return nil, err
 	}
 
 	toHistory := &models.BalanceHistory{
 		UserID:        toID,
 		WalletID:      toWallet.ID,
 		TransactionID: transaction.ID,
-		ChangeType:    "credit",
+		ChangeType:    models.ChangeTypeCredit,
+		Status:        transaction.Status,
 		Amount:        amount,
 		BalanceBefore: toBalanceBefore,
 		BalanceAfter:  toWallet.Balance,
 	}
 	if err := s.balanceHistoryRepo.CreateHistory(toHistory, tx); err != nil {
-		return err
+		return nil, err
 	}
 
-	if commitDB := tx.Commit(); commitDB.Error != nil {
-		return commitDB.Error
+	// 在同一個交易中寫入 outbox，由 OutboxRelay 負責發送到 Kafka
+	if err := s.enqueueTxCreated(transaction, tx); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// lockWalletPair 鎖定轉出與轉入錢包
+// 一律依 user_id 由小到大加鎖，避免兩筆反向轉帳互相等待造成死鎖
+func (s *TransactionService) lockWalletPair(tx *gorm.DB, fromID, toID uint, currencyID uint) (*models.Wallet, *models.Wallet, error) {
+	lock := func(userID uint) (*models.Wallet, error) {
+		wallet, err := s.walletRepo.GetWalletByUserIDAndCurrencyWithTx(userID, currencyID, tx)
+		if err != nil {
+			if userID == fromID {
+				return nil, errors.New("from_user wallet not found for this currency")
+			}
+			return nil, errors.New("to_user wallet not found for this currency")
+		}
+		return wallet, nil
+	}
+
+	firstID, secondID := fromID, toID
+	if secondID < firstID {
+		firstID, secondID = secondID, firstID
+	}
+
+	first, err := lock(firstID)
+	if err != nil {
+		return nil, nil, err
+	}
+	second, err := lock(secondID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if firstID == fromID {
+		return first, second, nil
 	}
+	return second, first, nil
+}
+
+// enqueueTxCreated 將 tx.created 事件寫入 outbox
+func (s *TransactionService) enqueueTxCreated(transaction *models.Transaction, tx *gorm.DB) error {
+	return s.enqueueTxCreatedMessage(transaction, "", tx)
+}
+
+// enqueueTxCreatedMessage 將 tx.created 事件寫入 outbox，沖正與退款會帶上原始交易的 hash
+func (s *TransactionService) enqueueTxCreatedMessage(transaction *models.Transaction, originalHash string, tx *gorm.DB) error {
+	eventType, payload := txCreatedEvent(transaction, originalHash)
+	return enqueueEvent(s.outboxRepo, kafka_client.TopicTxCreated, transaction.Hash, eventType, payload, transaction.CreatedAt, tx)
+}
 
-	// Send Kafka message
-	if s.kafkaProducer != nil {
-		msg := kafka_client.TxCreatedMessage{
-			Hash:       transaction.Hash,
-			FromUserID: transaction.FromUserID,
-			ToUserID:   transaction.ToUserID,
-			Amount:     transaction.Amount,
-			Timestamp:  transaction.CreatedAt.Format(time.RFC3339),
+// txCreatedEvent 依交易類型決定 tx.created 的事件類型與 payload
+func txCreatedEvent(transaction *models.Transaction, originalHash string) (string, interface{}) {
+	switch transaction.Type {
+	case models.TxTypeReversal, models.TxTypeRefund:
+		eventType := events.TypeReversalCompleted
+		if transaction.Type == models.TxTypeRefund {
+			eventType = events.TypeRefundCompleted
 		}
-		if err := s.kafkaProducer.SendTxCreated(msg); err != nil {
-			log.Println("⚠️ Kafka tx.created 發送失敗:", err)
+		return eventType, events.ReversalPayload{
+			Hash:         transaction.Hash,
+			OriginalHash: originalHash,
+			FromUserID:   transaction.FromUserID,
+			ToUserID:     transaction.ToUserID,
+			CurrencyID:   transaction.CurrencyID,
+			Amount:       transaction.Amount,
+			Reason:       transaction.Reference,
+			Status:       transaction.Status,
+			CreatedAt:    transaction.CreatedAt,
 		}
+	case models.TxTypeSwap:
+		return events.TypeSwapCompleted, transferPayload(transaction, "")
+	default:
+		return events.TypeTransferCompleted, transferPayload(transaction, "")
+	}
+}
+
+func transferPayload(transaction *models.Transaction, previousStatus string) events.TransferPayload {
+	return events.TransferPayload{
+		Hash:            transaction.Hash,
+		TransactionType: transaction.Type,
+		FromUserID:      transaction.FromUserID,
+		ToUserID:        transaction.ToUserID,
+		CurrencyID:      transaction.CurrencyID,
+		Amount:          transaction.Amount,
+		Fee:             transaction.Fee,
+		PreviousStatus:  previousStatus,
+		Status:          transaction.Status,
+		CreatedAt:       transaction.CreatedAt,
 	}
-	return nil
 }
 
 func (s *TransactionService) GetTransactions(userID uint) ([]models.Transaction, error) {
@@ -169,11 +433,11 @@ func (s *TransactionService) TransferWithLockOption(t *testing.T, fromID, toID u
 	}
 
 	// 模擬未加鎖（不安全寫法）
-	fromWallet, err := s.walletRepo.GetWalletByUserID(fromID)
+	fromWallet, err := s.walletRepo.GetWalletByUserIDAndCurrency(fromID, currencyID)
 	if err != nil {
 		return err
 	}
-	toWallet, err := s.walletRepo.GetWalletByUserID(toID)
+	toWallet, err := s.walletRepo.GetWalletByUserIDAndCurrency(toID, currencyID)
 	if err != nil {
 		return err
 	}
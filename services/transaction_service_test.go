@@ -26,7 +26,7 @@ func TestTransfer_Success_ValidTransfer(t *testing.T) {
 	// Create service
 	walletRepo := repositories.NewWalletRepository()
 	txRepo := repositories.NewTransactionRepository()
-	service := NewTransactionService(walletRepo, txRepo, nil)
+	service := NewTransactionService(walletRepo, txRepo)
 
 	// Execute
 	err := service.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(100))
@@ -64,7 +64,7 @@ func TestTransfer_Success_BalanceHistoryRecorded(t *testing.T) {
 	walletRepo := repositories.NewWalletRepository()
 	txRepo := repositories.NewTransactionRepository()
 	balanceHistoryRepo := repositories.NewBalanceHistoryRepository()
-	service := NewTransactionService(walletRepo, txRepo, nil)
+	service := NewTransactionService(walletRepo, txRepo)
 
 	// Execute
 	err := service.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(200))
@@ -110,7 +110,7 @@ func TestTransfer_Success_TransactionHashGenerated(t *testing.T) {
 	// Create service
 	walletRepo := repositories.NewWalletRepository()
 	txRepo := repositories.NewTransactionRepository()
-	service := NewTransactionService(walletRepo, txRepo, nil)
+	service := NewTransactionService(walletRepo, txRepo)
 
 	// Execute
 	err := service.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(50))
@@ -147,7 +147,7 @@ func TestTransfer_Fail_InsufficientBalance(t *testing.T) {
 	// Create service
 	walletRepo := repositories.NewWalletRepository()
 	txRepo := repositories.NewTransactionRepository()
-	service := NewTransactionService(walletRepo, txRepo, nil)
+	service := NewTransactionService(walletRepo, txRepo)
 
 	// Execute - try to transfer 200 (more than balance)
 	err := service.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(200))
@@ -180,7 +180,7 @@ func TestTransfer_Fail_SameAccountTransfer(t *testing.T) {
 	// Create service
 	walletRepo := repositories.NewWalletRepository()
 	txRepo := repositories.NewTransactionRepository()
-	service := NewTransactionService(walletRepo, txRepo, nil)
+	service := NewTransactionService(walletRepo, txRepo)
 
 	// Execute - try to transfer to same account
 	err := service.Transfer(alice.ID, alice.ID, currency.ID, decimal.NewFromInt(100))
@@ -209,7 +209,7 @@ func TestTransfer_Fail_NegativeAmount(t *testing.T) {
 	// Create service
 	walletRepo := repositories.NewWalletRepository()
 	txRepo := repositories.NewTransactionRepository()
-	service := NewTransactionService(walletRepo, txRepo, nil)
+	service := NewTransactionService(walletRepo, txRepo)
 
 	// Execute - try negative amount
 	err := service.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(-100))
@@ -234,7 +234,7 @@ func TestTransfer_Fail_ZeroAmount(t *testing.T) {
 	// Create service
 	walletRepo := repositories.NewWalletRepository()
 	txRepo := repositories.NewTransactionRepository()
-	service := NewTransactionService(walletRepo, txRepo, nil)
+	service := NewTransactionService(walletRepo, txRepo)
 
 	// Execute - try zero amount
 	err := service.Transfer(alice.ID, bob.ID, currency.ID, decimal.Zero)
@@ -257,7 +257,7 @@ func TestTransfer_Fail_FromWalletNotFound(t *testing.T) {
 	// Create service
 	walletRepo := repositories.NewWalletRepository()
 	txRepo := repositories.NewTransactionRepository()
-	service := NewTransactionService(walletRepo, txRepo, nil)
+	service := NewTransactionService(walletRepo, txRepo)
 
 	// Execute - try to transfer from non-existent user ID 999
 	err := service.Transfer(999, bob.ID, currency.ID, decimal.NewFromInt(100))
@@ -280,7 +280,7 @@ func TestTransfer_Fail_ToWalletNotFound(t *testing.T) {
 	// Create service
 	walletRepo := repositories.NewWalletRepository()
 	txRepo := repositories.NewTransactionRepository()
-	service := NewTransactionService(walletRepo, txRepo, nil)
+	service := NewTransactionService(walletRepo, txRepo)
 
 	// Execute - try to transfer to non-existent user ID 999
 	err := service.Transfer(alice.ID, 999, currency.ID, decimal.NewFromInt(100))
@@ -306,7 +306,7 @@ func TestTransfer_Fail_InvalidCurrency(t *testing.T) {
 	// Create service
 	walletRepo := repositories.NewWalletRepository()
 	txRepo := repositories.NewTransactionRepository()
-	service := NewTransactionService(walletRepo, txRepo, nil)
+	service := NewTransactionService(walletRepo, txRepo)
 
 	// Execute - try to transfer with mismatched currency (Alice USDT → Bob BTC)
 	err := service.Transfer(alice.ID, bob.ID, usdtCurrency.ID, decimal.NewFromInt(100))
@@ -333,7 +333,7 @@ func TestTransfer_ConcurrentTransfers_NoRaceCondition(t *testing.T) {
 	// Create service
 	walletRepo := repositories.NewWalletRepository()
 	txRepo := repositories.NewTransactionRepository()
-	service := NewTransactionService(walletRepo, txRepo, nil)
+	service := NewTransactionService(walletRepo, txRepo)
 
 	// Execute - 5 concurrent transfers of 100 each from Alice
 	var wg sync.WaitGroup
@@ -397,7 +397,7 @@ func TestTransfer_MultipleSequential(t *testing.T) {
 	// Create service
 	walletRepo := repositories.NewWalletRepository()
 	txRepo := repositories.NewTransactionRepository()
-	service := NewTransactionService(walletRepo, txRepo, nil)
+	service := NewTransactionService(walletRepo, txRepo)
 
 	// Execute multiple transfers
 	err1 := service.Transfer(alice.ID, bob.ID, currency.ID, decimal.NewFromInt(300))
@@ -428,3 +428,43 @@ func TestTransfer_MultipleSequential(t *testing.T) {
 	charlieTxs, _ := txRepo.GetTransactionsByUserID(charlie.ID)
 	assert.Len(t, charlieTxs, 2) // Charlie involved in 2 transactions
 }
+
+// TestTransfer_MultiCurrency_LocksMatchingWallet verifies only the wallets of the requested currency move
+func TestTransfer_MultiCurrency_LocksMatchingWallet(t *testing.T) {
+	// Setup
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	btc := test.CreateTestCurrency(db, "BTC")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 1000) // Alice's first wallet is USDT
+	test.CreateTestWallet(db, alice.ID, btc.ID, 5)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+	test.CreateTestWallet(db, bob.ID, btc.ID, 0)
+
+	// Create service
+	walletRepo := repositories.NewWalletRepository()
+	txRepo := repositories.NewTransactionRepository()
+	service := NewTransactionService(walletRepo, txRepo)
+
+	// Execute - transfer in the second currency
+	err := service.Transfer(alice.ID, bob.ID, btc.ID, decimal.NewFromInt(2))
+	assert.NoError(t, err)
+
+	// Assert - BTC moved, USDT untouched
+	aliceBTC, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, btc.ID)
+	bobBTC, _ := walletRepo.GetWalletByUserIDAndCurrency(bob.ID, btc.ID)
+	aliceUSDT, _ := walletRepo.GetWalletByUserIDAndCurrency(alice.ID, usdt.ID)
+	bobUSDT, _ := walletRepo.GetWalletByUserIDAndCurrency(bob.ID, usdt.ID)
+	assert.Equal(t, "3", aliceBTC.Balance.String())
+	assert.Equal(t, "2", bobBTC.Balance.String())
+	assert.Equal(t, "1000", aliceUSDT.Balance.String())
+	assert.Equal(t, "0", bobUSDT.Balance.String())
+
+	// Insufficient balance is checked against the BTC wallet, not the larger USDT one
+	err = service.Transfer(alice.ID, bob.ID, btc.ID, decimal.NewFromInt(10))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "insufficient balance")
+}
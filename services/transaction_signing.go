@@ -0,0 +1,71 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"mini-crypto-wallet-api/internal/txsign"
+	"mini-crypto-wallet-api/models"
+	"time"
+)
+
+// TransactionSigner 簽署新交易所用的金鑰，main 啟動時依設定替換
+// 預設為行程內臨時產生的金鑰，重啟後簽章無法再驗證，只適合測試與開發
+var TransactionSigner = txsign.NewEphemeralSigner()
+
+// sealTransaction 在寫入前決定交易的建立時間、nonce、hash 與簽章
+// CreatedAt 先以資料庫保存的精度（微秒）設定好，hash 與簽章涵蓋的就是實際寫入的時間
+func sealTransaction(transaction *models.Transaction) {
+	transaction.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	transaction.Nonce = newTransactionNonce()
+	transaction.HashVersion = models.HashVersionContent
+	transaction.Hash = transaction.GenerateHash()
+	transaction.SignatureKID, transaction.Signature = TransactionSigner.Sign(transaction.SigningPayload())
+}
+
+// newTransactionNonce 產生 128 bit 的隨機 nonce
+func newTransactionNonce() string {
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	return hex.EncodeToString(nonce)
+}
+
+// VerifyTransaction 以交易記錄的 kid 驗證簽章，確認交易建立後未被竄改
+func (s *TransactionService) VerifyTransaction(hash string) (*models.TransactionVerificationResponse, error) {
+	transaction, err := s.transactionRepo.FindByHash(hash)
+	if err != nil {
+		return nil, ErrTransactionNotFound
+	}
+
+	payload := transaction.SigningPayload()
+	result := &models.TransactionVerificationResponse{
+		Hash:          transaction.Hash,
+		HashVersion:   transaction.HashVersion,
+		HashValid:     transaction.HashMatches(),
+		Algorithm:     txsign.Algorithm,
+		KID:           transaction.SignatureKID,
+		SignedPayload: string(payload),
+	}
+	if transaction.SignatureKID == "" {
+		result.Reason = "legacy signature without a key id cannot be verified"
+		if transaction.LegacyHash != "" {
+			result.Reason = "hash was migrated from a legacy transaction whose signature cannot be verified"
+		}
+		return result, nil
+	}
+	result.PublicKey, _ = TransactionSigner.PublicKey(transaction.SignatureKID)
+
+	switch err := TransactionSigner.Verify(transaction.SignatureKID, payload, transaction.Signature); {
+	case errors.Is(err, txsign.ErrUnknownKey):
+		result.Reason = "signing key is not known to this server"
+	case err != nil:
+		result.Reason = "signature does not match the transaction record"
+	case transaction.HashVersion == models.HashVersionLegacy:
+		result.Reason = "legacy hash cannot be recomputed, run the hash migration"
+	case !result.HashValid:
+		result.Reason = "hash does not match the transaction record"
+	default:
+		result.Valid = true
+	}
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestVerifyTransaction_DetectsTampering verifies signed records check out until a signed field changes
+func TestVerifyTransaction_DetectsTampering(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	service := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	transaction, err := service.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("40"))
+	assert.NoError(t, err)
+	assert.Equal(t, TransactionSigner.ActiveKID(), transaction.SignatureKID)
+
+	result, err := service.VerifyTransaction(transaction.Hash)
+	assert.NoError(t, err)
+	assert.True(t, result.Valid, result.Reason)
+	assert.NotEmpty(t, result.PublicKey)
+	assert.Contains(t, result.SignedPayload, `"amount":"40"`)
+
+	// 狀態屬於生命週期欄位，不在簽章範圍內
+	assert.NoError(t, db.Model(&models.Transaction{}).Where("id = ?", transaction.ID).Update("status", models.TxStatusReversed).Error)
+	result, err = service.VerifyTransaction(transaction.Hash)
+	assert.NoError(t, err)
+	assert.True(t, result.Valid)
+
+	assert.NoError(t, db.Model(&models.Transaction{}).Where("id = ?", transaction.ID).Update("amount", dec("4000")).Error)
+	result, err = service.VerifyTransaction(transaction.Hash)
+	assert.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, "signature does not match the transaction record", result.Reason)
+
+	// 舊格式的簽章沒有 kid
+	assert.NoError(t, db.Model(&models.Transaction{}).Where("id = ?", transaction.ID).Updates(map[string]interface{}{"signature": "SIG-1-40-0", "signature_kid": ""}).Error)
+	result, err = service.VerifyTransaction(transaction.Hash)
+	assert.NoError(t, err)
+	assert.False(t, result.Valid)
+
+	_, err = service.VerifyTransaction("missing")
+	assert.ErrorIs(t, err, ErrTransactionNotFound)
+}
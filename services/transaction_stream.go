@@ -0,0 +1,80 @@
+package services
+
+import (
+	"log"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/internal/stream"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"mini-crypto-wallet-api/utils"
+)
+
+// TransactionStream 將已 commit 的交易與餘額變動推送給 GET /stream 的連線，所有 service 共用同一個 broker
+var TransactionStream = stream.NewBroker(stream.DefaultHistorySize, stream.DefaultBufferSize)
+
+// streamCommitted 在 commit 後推送交易給雙方，以及雙方在 currencyIDs（預設為交易幣種）錢包的最新餘額
+// 只能在 commit 成功後呼叫；推送失敗只記錄 log，不影響已完成的交易
+// 併發交易的餘額事件可能不依序到達，事件帶有餘額歷史的 sequence，用戶端保留 sequence 最大的值
+func streamCommitted(walletRepo repositories.IWallet, historyRepo repositories.IBalanceHistory, transaction *models.Transaction, currencyIDs ...uint) {
+	streamTransaction(transaction)
+
+	if len(currencyIDs) == 0 {
+		currencyIDs = []uint{transaction.CurrencyID}
+	}
+	for _, userID := range transactionParties(transaction) {
+		for _, currencyID := range currencyIDs {
+			balance, err := latestBalance(walletRepo, historyRepo, userID, currencyID)
+			if err != nil {
+				log.Printf("⚠️ Failed to stream balance of user %d: %v", userID, err)
+				continue
+			}
+			if _, err := TransactionStream.Publish(userID, models.StreamEventBalance, balance); err != nil {
+				log.Printf("⚠️ Failed to stream balance of user %d: %v", userID, err)
+			}
+		}
+	}
+}
+
+// latestBalance 在同一個短交易中以 FOR SHARE 讀取錢包與其餘額歷史的最後一筆，讓餘額與 sequence 一致
+func latestBalance(walletRepo repositories.IWallet, historyRepo repositories.IBalanceHistory, userID, currencyID uint) (*models.StreamBalanceData, error) {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	wallet, err := walletRepo.GetWalletByUserIDAndCurrencyForShareWithTx(userID, currencyID, tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	head, err := historyRepo.FindChainHead(wallet.ID, tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return nil, commitDB.Error
+	}
+	return models.ToStreamBalanceData(wallet, head.Sequence), nil
+}
+
+// streamTransaction 只推送交易本身，用於狀態改變但餘額不變的交易（例如被沖正的原始轉帳）
+func streamTransaction(transaction *models.Transaction) {
+	response := models.ToTransactionResponse(transaction)
+	for _, userID := range transactionParties(transaction) {
+		if _, err := TransactionStream.Publish(userID, models.StreamEventTransaction, response); err != nil {
+			log.Printf("⚠️ Failed to stream transaction %s: %v", transaction.Hash, err)
+		}
+	}
+}
+
+// transactionParties 交易涉及的用戶，入金 / 出金與兌換只有一方
+func transactionParties(transaction *models.Transaction) []uint {
+	parties := make([]uint, 0, 2)
+	for _, userID := range []uint{transaction.FromUserID, transaction.ToUserID} {
+		if userID == 0 || (len(parties) > 0 && parties[0] == userID) {
+			continue
+		}
+		parties = append(parties, userID)
+	}
+	return parties
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"encoding/json"
+	"mini-crypto-wallet-api/internal/stream"
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// useTestStream replaces TransactionStream with a fresh broker for the duration of the test
+func useTestStream(t *testing.T) *stream.Broker {
+	previous := TransactionStream
+	TransactionStream = stream.NewBroker(0, 0)
+	t.Cleanup(func() { TransactionStream = previous })
+	return TransactionStream
+}
+
+// drain returns every event already delivered to sub
+func drain(sub *stream.Subscription) []stream.Event {
+	var received []stream.Event
+	for {
+		select {
+		case event := <-sub.C:
+			received = append(received, event)
+		default:
+			return received
+		}
+	}
+}
+
+func eventTypes(received []stream.Event) []string {
+	types := make([]string, len(received))
+	for i, event := range received {
+		types[i] = event.Type
+	}
+	return types
+}
+
+// TestStream_TransferPushesTransactionAndBalances verifies both parties get the transaction and their new balance after commit
+func TestStream_TransferPushesTransactionAndBalances(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+	broker := useTestStream(t)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 1000)
+	test.CreateTestWallet(db, bob.ID, currency.ID, 0)
+
+	aliceSub, _, _ := broker.Subscribe(alice.ID, "")
+	bobSub, _, _ := broker.Subscribe(bob.ID, "")
+
+	service := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	transaction, err := service.TransferWithResult(alice.ID, bob.ID, currency.ID, dec("100"))
+	assert.NoError(t, err)
+
+	_, err = service.TransferWithResult(bob.ID, alice.ID, currency.ID, dec("5000"))
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+
+	aliceEvents, bobEvents := drain(aliceSub), drain(bobSub)
+	assert.Equal(t, []string{models.StreamEventTransaction, models.StreamEventBalance}, eventTypes(aliceEvents))
+	assert.Equal(t, []string{models.StreamEventTransaction, models.StreamEventBalance}, eventTypes(bobEvents), "rolled back transfers push nothing")
+
+	var pushed models.TransactionResponse
+	assert.NoError(t, json.Unmarshal(bobEvents[0].Data, &pushed))
+	assert.Equal(t, transaction.Hash, pushed.Hash)
+	assert.Equal(t, models.TxStatusCompleted, pushed.Status)
+
+	var aliceBalance, bobBalance models.StreamBalanceData
+	assert.NoError(t, json.Unmarshal(aliceEvents[1].Data, &aliceBalance))
+	assert.NoError(t, json.Unmarshal(bobEvents[1].Data, &bobBalance))
+	assert.Equal(t, "900", aliceBalance.Balance)
+	assert.Equal(t, "100", bobBalance.Balance)
+
+	// sequence 與錢包餘額歷史的最後一筆一致，用戶端以此丟棄較舊的餘額
+	head, err := repositories.NewBalanceHistoryRepository().FindChainHead(aliceBalance.ID)
+	assert.NoError(t, err)
+	assert.NotZero(t, aliceBalance.Sequence)
+	assert.Equal(t, head.Sequence, aliceBalance.Sequence)
+
+	// a client that saw only the transaction resumes with the balance it missed
+	_, replay, gap := broker.Subscribe(bob.ID, bobEvents[0].ID)
+	assert.False(t, gap)
+	assert.Equal(t, []string{models.StreamEventBalance}, eventTypes(replay))
+
+	_, err = service.TransferWithResult(alice.ID, bob.ID, currency.ID, dec("50"))
+	assert.NoError(t, err)
+	var later models.StreamBalanceData
+	assert.NoError(t, json.Unmarshal(drain(aliceSub)[1].Data, &later))
+	assert.Equal(t, "850", later.Balance)
+	assert.Greater(t, later.Sequence, aliceBalance.Sequence)
+}
+
+// TestStream_RefundPushesOriginalAndRefund verifies a refund pushes the updated original and the refund with both balances
+func TestStream_RefundPushesOriginalAndRefund(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 100)
+	test.CreateTestWallet(db, bob.ID, usdt.ID, 0)
+
+	service := NewTransactionService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	transfer, err := service.TransferWithResult(alice.ID, bob.ID, usdt.ID, dec("40"))
+	assert.NoError(t, err)
+
+	broker := useTestStream(t)
+	aliceSub, _, _ := broker.Subscribe(alice.ID, "")
+
+	_, refund, err := service.Refund(bob.ID, transfer.Hash, nil)
+	assert.NoError(t, err)
+
+	received := drain(aliceSub)
+	assert.Equal(t, []string{models.StreamEventTransaction, models.StreamEventTransaction, models.StreamEventBalance}, eventTypes(received))
+
+	var original, pushed models.TransactionResponse
+	assert.NoError(t, json.Unmarshal(received[0].Data, &original))
+	assert.NoError(t, json.Unmarshal(received[1].Data, &pushed))
+	assert.Equal(t, transfer.Hash, original.Hash)
+	assert.Equal(t, "40", original.RefundedAmount.String())
+	assert.Equal(t, refund.Hash, pushed.Hash)
+}
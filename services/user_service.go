@@ -3,18 +3,29 @@ package services
 import (
 	"errors"
 	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/internal/events"
+	"mini-crypto-wallet-api/kafka_client"
 	"mini-crypto-wallet-api/models"
 	"mini-crypto-wallet-api/repositories"
 	"mini-crypto-wallet-api/utils"
+	"strconv"
+	"time"
 
 	"github.com/shopspring/decimal"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAccountFrozen = errors.New("account is frozen")
+	ErrUserNotFound  = errors.New("user not found")
 )
 
 type UserService struct {
 	userRepo     repositories.IUser
 	walletRepo   repositories.IWallet
 	currencyRepo repositories.ICurrency
+	outboxRepo   repositories.IOutbox
 }
 
 func NewUserService(userRepo repositories.IUser, walletRepo repositories.IWallet, currencyRepo repositories.ICurrency) *UserService {
@@ -22,6 +33,7 @@ func NewUserService(userRepo repositories.IUser, walletRepo repositories.IWallet
 		userRepo:     userRepo,
 		walletRepo:   walletRepo,
 		currencyRepo: currencyRepo,
+		outboxRepo:   repositories.NewOutboxRepository(),
 	}
 }
 
@@ -39,13 +51,14 @@ func (s *UserService) CreateUser(req *models.UserCreateRequest) (*models.User, e
 		Username: req.Username,
 		Email:    req.Email,
 		Password: string(hashedPassword),
+		Role:     models.RoleUser,
 	}
 
 	// 使用事務確保用戶和錢包創建的原子性
 	tx := db_conn.Conn_DB.MasterDB.Begin()
 	defer utils.RollbackIfPanic(tx)
 
-	if err := s.userRepo.CreateUser(user); err != nil {
+	if err := s.userRepo.CreateUser(user, tx); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
@@ -56,6 +69,7 @@ func (s *UserService) CreateUser(req *models.UserCreateRequest) (*models.User, e
 		// 如果 USDT 不存在，嘗試獲取第一個幣種
 		currencies, err := s.currencyRepo.GetAllCurrencies()
 		if err != nil || len(currencies) == 0 {
+			tx.Rollback()
 			return nil, errors.New("no currency available")
 		}
 		defaultCurrency = &currencies[0]
@@ -72,6 +86,11 @@ func (s *UserService) CreateUser(req *models.UserCreateRequest) (*models.User, e
 		return nil, err
 	}
 
+	if err := enqueueUserEvent(s.outboxRepo, events.TypeUserCreated, user, tx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if commitDB := tx.Commit(); commitDB.Error != nil {
 		return nil, commitDB.Error
 	}
@@ -90,5 +109,65 @@ func (s *UserService) Login(username, password string) (*models.User, error) {
 		return nil, errors.New("invalid username or password")
 	}
 
+	if user.IsFrozen() {
+		return nil, ErrAccountFrozen
+	}
+
 	return user, nil
 }
+
+// EnsureRole 將指定用戶設為 role，用於啟動時指派第一位管理員
+func (s *UserService) EnsureRole(username string, role string) error {
+	user, err := s.userRepo.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+	if user.Role == role {
+		return nil
+	}
+
+	user.Role = role
+	return updateUserWithEvent(s.userRepo, s.outboxRepo, user, events.TypeUserRoleChanged)
+}
+
+// updateUserWithEvent 在同一個 DB 交易中更新用戶並寫入 user.* 事件
+func updateUserWithEvent(userRepo repositories.IUser, outboxRepo repositories.IOutbox, user *models.User, eventType string) error {
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	if err := userRepo.UpdateUser(user, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := enqueueUserEvent(outboxRepo, eventType, user, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// enqueueUserEvent 將 user.* 事件寫入 outbox，以用戶 ID 作為 key 讓同一用戶的事件保持順序
+func enqueueUserEvent(outboxRepo repositories.IOutbox, eventType string, user *models.User, tx *gorm.DB) error {
+	payload := events.UserPayload{
+		UserID:       user.ID,
+		Username:     user.Username,
+		Role:         user.Role,
+		Frozen:       user.IsFrozen(),
+		FrozenReason: user.FrozenReason,
+	}
+	key := strconv.FormatUint(uint64(user.ID), 10)
+	return enqueueEvent(outboxRepo, kafka_client.TopicUserEvents, key, eventType, payload, time.Now(), tx)
+}
+
+// ensureNotFrozen 凍結的帳戶不能轉出資金
+func ensureNotFrozen(userRepo repositories.IUser, userID uint) error {
+	user, err := userRepo.GetUserByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.IsFrozen() {
+		return ErrAccountFrozen
+	}
+	return nil
+}
@@ -1,20 +1,82 @@
 package services
 
 import (
+	"errors"
 	"mini-crypto-wallet-api/models"
 	"mini-crypto-wallet-api/repositories"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrWalletAlreadyExists = errors.New("wallet already exists for this currency")
+	ErrCurrencyUnavailable = errors.New("currency not found or inactive")
+	ErrWalletNotFound      = errors.New("wallet not found for this currency")
 )
 
 type WalletService struct {
-	walletRepo repositories.IWallet
+	walletRepo   repositories.IWallet
+	currencyRepo repositories.ICurrency
 }
 
-func NewWalletService(walletRepo repositories.IWallet) *WalletService {
+func NewWalletService(walletRepo repositories.IWallet, currencyRepo repositories.ICurrency) *WalletService {
 	return &WalletService{
-		walletRepo: walletRepo,
+		walletRepo:   walletRepo,
+		currencyRepo: currencyRepo,
 	}
 }
 
+// GetWallet 取得用戶的預設錢包（最早開立的錢包）
 func (s *WalletService) GetWallet(userID uint) (*models.Wallet, error) {
-	return s.walletRepo.GetWalletByUserID(userID)
+	return s.withCurrency(s.walletRepo.GetWalletByUserID(userID))
+}
+
+// GetWalletByCurrency 取得用戶指定幣種的錢包
+func (s *WalletService) GetWalletByCurrency(userID uint, currencyID uint) (*models.Wallet, error) {
+	return s.withCurrency(s.walletRepo.GetWalletByUserIDAndCurrency(userID, currencyID))
+}
+
+// withCurrency 附上錢包的幣種資訊，讓回應能依幣種精度顯示餘額
+func (s *WalletService) withCurrency(wallet *models.Wallet, err error) (*models.Wallet, error) {
+	if err != nil {
+		return nil, err
+	}
+	if currency, err := s.currencyRepo.FindCurrencyByID(wallet.CurrencyID); err == nil {
+		wallet.Currency = *currency
+	}
+	return wallet, nil
+}
+
+// GetWallets 取得用戶所有幣種的錢包
+func (s *WalletService) GetWallets(userID uint) ([]models.Wallet, error) {
+	return s.walletRepo.GetWalletsByUserID(userID)
+}
+
+// OpenWallet 為用戶開立新幣種的錢包，幣種必須為啟用狀態
+func (s *WalletService) OpenWallet(userID uint, currencyID uint) (*models.Wallet, error) {
+	currency, err := s.currencyRepo.GetCurrencyByID(currencyID)
+	if err != nil {
+		return nil, ErrCurrencyUnavailable
+	}
+
+	if _, err := s.walletRepo.GetWalletByUserIDAndCurrency(userID, currencyID); err == nil {
+		return nil, ErrWalletAlreadyExists
+	}
+
+	wallet := &models.Wallet{
+		UserID:     userID,
+		CurrencyID: currency.ID,
+		Balance:    decimal.Zero,
+	}
+	created, err := s.walletRepo.CreateWalletIfAbsent(wallet)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		// 查詢後到寫入前被併發的請求搶先開立
+		return nil, ErrWalletAlreadyExists
+	}
+
+	wallet.Currency = *currency
+	return wallet, nil
 }
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOpenWallet_Success verifies a user can open a wallet in another active currency
+func TestOpenWallet_Success(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	btc := test.CreateTestCurrency(db, "BTC")
+	alice := test.CreateTestUser(db, "alice")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 1000)
+
+	service := NewWalletService(repositories.NewWalletRepository(), repositories.NewCurrencyRepository())
+
+	wallet, err := service.OpenWallet(alice.ID, btc.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, btc.ID, wallet.CurrencyID)
+	assert.Equal(t, "0", wallet.Balance.String())
+	assert.Equal(t, "BTC", wallet.Currency.Code)
+
+	wallets, err := service.GetWallets(alice.ID)
+	assert.NoError(t, err)
+	assert.Len(t, wallets, 2)
+	assert.Equal(t, "USDT", wallets[0].Currency.Code)
+	assert.Equal(t, "1000", wallets[0].Balance.String())
+	assert.Equal(t, "BTC", wallets[1].Currency.Code)
+}
+
+// TestOpenWallet_Fail_AlreadyExists verifies a second wallet in the same currency is rejected
+func TestOpenWallet_Fail_AlreadyExists(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	usdt := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	test.CreateTestWallet(db, alice.ID, usdt.ID, 1000)
+
+	service := NewWalletService(repositories.NewWalletRepository(), repositories.NewCurrencyRepository())
+
+	_, err := service.OpenWallet(alice.ID, usdt.ID)
+	assert.ErrorIs(t, err, ErrWalletAlreadyExists)
+
+	// The unique (user_id, currency_id) index is the last line of defence
+	err = db.Create(&models.Wallet{UserID: alice.ID, CurrencyID: usdt.ID}).Error
+	assert.Error(t, err)
+}
+
+// TestOpenWallet_Fail_InactiveCurrency verifies wallets cannot be opened in inactive currencies
+func TestOpenWallet_Fail_InactiveCurrency(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	legacy := test.CreateTestCurrency(db, "OLD")
+	db.Model(legacy).Update("is_active", false)
+	alice := test.CreateTestUser(db, "alice")
+
+	service := NewWalletService(repositories.NewWalletRepository(), repositories.NewCurrencyRepository())
+
+	_, err := service.OpenWallet(alice.ID, legacy.ID)
+	assert.ErrorIs(t, err, ErrCurrencyUnavailable)
+
+	_, err = service.OpenWallet(alice.ID, 999)
+	assert.ErrorIs(t, err, ErrCurrencyUnavailable)
+}
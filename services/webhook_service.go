@@ -0,0 +1,518 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mini-crypto-wallet-api/db_conn"
+	"mini-crypto-wallet-api/internal/events"
+	"mini-crypto-wallet-api/internal/webhook"
+	"mini-crypto-wallet-api/kafka_client"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"mini-crypto-wallet-api/utils"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxWebhookEndpointsPerUser = 10
+
+	defaultWebhookBatchSize   = 20
+	defaultWebhookTimeout     = 10 * time.Second
+	defaultWebhookMaxAttempts = 8
+	defaultWebhookBaseBackoff = 30 * time.Second
+	defaultWebhookMaxBackoff  = 6 * time.Hour
+	maxWebhookErrorLength     = 500
+)
+
+var (
+	ErrWebhookNotFound           = errors.New("webhook endpoint not found")
+	ErrWebhookDeliveryNotFound   = errors.New("webhook delivery not found")
+	ErrInvalidWebhookURL         = errors.New("webhook url must be an https url on a public host")
+	ErrWebhookLimitReached       = errors.New("too many webhook endpoints")
+	ErrWebhookDeliveryInProgress = errors.New("webhook delivery is being sent, try again later")
+)
+
+// WebhookOptions 投遞設定，零值使用預設值
+type WebhookOptions struct {
+	AllowInsecureURLs bool          // 允許 http:// 與 localhost / 內網位址，只應在 development 與測試使用
+	Timeout           time.Duration // 單次投遞的 HTTP timeout
+	MaxAttempts       int           // 超過後投遞標記為 failed，用戶可手動重新投遞
+}
+
+// WebhookService 管理用戶的 webhook endpoint，將錢包事件轉成投遞紀錄並以 HMAC 簽章 POST 出去
+// 投遞失敗以指數退避重試，每筆投遞都保留結果供用戶查詢與重新投遞
+type WebhookService struct {
+	webhookRepo repositories.IWebhook
+	client      *http.Client
+	opts        WebhookOptions
+	batchSize   int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	now         func() time.Time
+}
+
+func NewWebhookService(webhookRepo repositories.IWebhook, opts WebhookOptions) *WebhookService {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultWebhookTimeout
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultWebhookMaxAttempts
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !opts.AllowInsecureURLs {
+		// 註冊時只能檢查字面上的 IP，網域實際解析到哪裡要到連線時才知道
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: webhook.DialControl}
+		transport.DialContext = dialer.DialContext
+		// 經過 proxy 時實際連線的是 proxy，無法檢查目的地
+		transport.Proxy = nil
+	}
+
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   opts.Timeout,
+			// 不跟隨轉址，避免 endpoint 把請求導向內網
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		opts:        opts,
+		batchSize:   defaultWebhookBatchSize,
+		baseBackoff: defaultWebhookBaseBackoff,
+		maxBackoff:  defaultWebhookMaxBackoff,
+		now:         time.Now,
+	}
+}
+
+// CreateEndpoint 註冊 endpoint 並產生 secret，secret 只會在建立時回傳給用戶
+func (s *WebhookService) CreateEndpoint(userID uint, req *models.CreateWebhookRequest) (*models.WebhookEndpoint, error) {
+	if err := s.validateURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	count, err := s.webhookRepo.CountEndpoints(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxWebhookEndpointsPerUser {
+		return nil, ErrWebhookLimitReached
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		UserID:     userID,
+		URL:        req.URL,
+		Secret:     secret,
+		EventTypes: joinWebhookEventTypes(req.EventTypes),
+		Active:     true,
+	}
+	if err := s.webhookRepo.CreateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func (s *WebhookService) ListEndpoints(userID uint) ([]models.WebhookEndpoint, error) {
+	return s.webhookRepo.FindEndpointsByUserID(userID)
+}
+
+func (s *WebhookService) GetEndpoint(userID, id uint) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.webhookRepo.FindEndpointByIDAndUserID(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// UpdateEndpoint 修改 URL、訂閱的事件或暫停 / 恢復投遞
+func (s *WebhookService) UpdateEndpoint(userID, id uint, req *models.UpdateWebhookRequest) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.GetEndpoint(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := s.validateURL(*req.URL); err != nil {
+			return nil, err
+		}
+		endpoint.URL = *req.URL
+	}
+	if len(req.EventTypes) > 0 {
+		endpoint.EventTypes = joinWebhookEventTypes(req.EventTypes)
+	}
+	if req.Active != nil {
+		endpoint.Active = *req.Active
+	}
+
+	if err := s.webhookRepo.UpdateEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// DeleteEndpoint 刪除 endpoint，尚未投遞的紀錄一併刪除
+func (s *WebhookService) DeleteEndpoint(userID, id uint) error {
+	if err := s.webhookRepo.DeleteEndpoint(id, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWebhookNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ListDeliveries 分頁取得 endpoint 的投遞紀錄
+func (s *WebhookService) ListDeliveries(userID, endpointID uint, offset, limit int) ([]models.WebhookDelivery, int64, error) {
+	if _, err := s.GetEndpoint(userID, endpointID); err != nil {
+		return nil, 0, err
+	}
+	return s.webhookRepo.FindDeliveriesByEndpointID(endpointID, offset, limit)
+}
+
+// Redeliver 將投遞重新排入佇列，由 scheduler 下一輪送出，重試次數從頭計算
+// 已成功的投遞也可以重新投遞，body 與事件 id 不變，接收端可依 id 去重；正在送出的投遞要等租約結束
+func (s *WebhookService) Redeliver(userID, endpointID, deliveryID uint) (*models.WebhookDelivery, error) {
+	if _, err := s.GetEndpoint(userID, endpointID); err != nil {
+		return nil, err
+	}
+	delivery, err := s.webhookRepo.FindDeliveryByIDAndEndpointID(deliveryID, endpointID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	requeued, err := s.webhookRepo.RequeueDelivery(delivery.ID, endpointID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if !requeued {
+		return nil, ErrWebhookDeliveryInProgress
+	}
+	return s.webhookRepo.FindDeliveryByIDAndEndpointID(deliveryID, endpointID)
+}
+
+// HandleEvent 是 tx.created 與 tx.status_changed 的 consumer handler，為訂閱的 endpoint 建立投遞紀錄
+// 訊息可能重複投遞，同一事件對同一 endpoint 只會建立一筆；導入 envelope 前的舊格式不投遞
+func (s *WebhookService) HandleEvent(ctx context.Context, msg kafka.Message) error {
+	envelope, err := events.Parse(msg.Value)
+	if errors.Is(err, events.ErrNotEnvelope) {
+		return nil
+	}
+	if err != nil {
+		return kafka_client.Poison(err)
+	}
+
+	targets, err := webhookTargets(envelope)
+	if err != nil {
+		return kafka_client.Poison(err)
+	}
+
+	for _, target := range targets {
+		endpoints, err := s.webhookRepo.FindEndpointsByUserID(target.userID)
+		if err != nil {
+			return err
+		}
+
+		eventID := target.eventType + ":" + envelope.EventID
+		body, err := json.Marshal(webhook.Event{
+			ID:        eventID,
+			Type:      target.eventType,
+			CreatedAt: envelope.OccurredAt,
+			Data:      envelope.Data,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, endpoint := range endpoints {
+			if !endpoint.Subscribes(target.eventType) {
+				continue
+			}
+			_, err := s.webhookRepo.CreateDeliveryIfAbsent(&models.WebhookDelivery{
+				EndpointID:    endpoint.ID,
+				EventID:       eventID,
+				EventType:     target.eventType,
+				Payload:       string(body),
+				Status:        models.WebhookDeliveryPending,
+				NextAttemptAt: s.now(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type webhookTarget struct {
+	userID    uint
+	eventType string
+}
+
+// webhookTargets 決定一個錢包事件要通知哪些用戶的哪種 webhook 事件
+func webhookTargets(envelope *events.Envelope) ([]webhookTarget, error) {
+	switch envelope.Type {
+	case events.TypeTransferCompleted:
+		var p events.TransferPayload
+		if err := envelope.DecodeInto(&p); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", envelope.Type, err)
+		}
+		if p.TransactionType != models.TxTypeTransfer || p.Status != models.TxStatusCompleted || p.FromUserID == p.ToUserID {
+			return nil, nil
+		}
+		return []webhookTarget{
+			{userID: p.ToUserID, eventType: models.WebhookEventTransferReceived},
+			{userID: p.FromUserID, eventType: models.WebhookEventTransferSent},
+		}, nil
+	case events.TypeDepositStatusChanged:
+		var p events.FundingPayload
+		if err := envelope.DecodeInto(&p); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", envelope.Type, err)
+		}
+		if p.Status != models.TxStatusCompleted {
+			return nil, nil
+		}
+		return []webhookTarget{{userID: p.UserID, eventType: models.WebhookEventDepositConfirmed}}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// RunScheduler 每隔 interval 投遞一批到期的紀錄，直到 ctx 被取消
+func (s *WebhookService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := s.DeliverDue(ctx); err != nil {
+			log.Println("⚠️ Webhook delivery error:", err)
+		}
+	}
+}
+
+// DeliverDue 投遞一批到期的紀錄，回傳成功的數量
+// 紀錄先在短交易中取得租約，HTTP 請求在交易外送出，每筆結果各自寫回，一筆寫入失敗不影響其他筆
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, token, lease, err := s.claimDue()
+	if err != nil || len(deliveries) == 0 {
+		return 0, err
+	}
+
+	// 租約到期前停止送出，剩下的紀錄租約到期後會再被取出
+	ctx, cancel := context.WithTimeout(ctx, lease)
+	defer cancel()
+
+	ids := make([]uint, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.EndpointID)
+	}
+	endpoints, err := s.webhookRepo.FindEndpointsByIDs(ids)
+	if err != nil {
+		// 租約到期後會再被取出
+		return 0, err
+	}
+	endpointByID := make(map[uint]*models.WebhookEndpoint, len(endpoints))
+	for i := range endpoints {
+		endpointByID[endpoints[i].ID] = &endpoints[i]
+	}
+
+	succeeded := 0
+	var firstErr error
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		delivery := &deliveries[i]
+		endpoint, ok := endpointByID[delivery.EndpointID]
+		if !ok || !endpoint.Active {
+			// endpoint 已暫停，不再重試；恢復後可手動重新投遞
+			delivery.Status = models.WebhookDeliveryFailed
+			delivery.LastError = "endpoint is inactive"
+		} else {
+			s.attempt(ctx, endpoint, delivery)
+		}
+
+		recorded, err := s.webhookRepo.UpdateLeasedDelivery(delivery, token)
+		if err != nil {
+			log.Printf("⚠️ Failed to record webhook delivery %d: %v", delivery.ID, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if !recorded {
+			// 租約已被其他 dispatcher 或重新投遞取走，結果以對方為準
+			log.Printf("⚠️ Lost the lease on webhook delivery %d, result discarded", delivery.ID)
+			continue
+		}
+		if delivery.Status == models.WebhookDeliverySucceeded {
+			succeeded++
+		}
+	}
+
+	return succeeded, firstErr
+}
+
+// claimDue 鎖定到期的紀錄，以新的 token 取得租約並把 next_attempt_at 推到租約到期時間後立即 commit
+// 整批依序送出，租約長度涵蓋每筆一次 timeout 再多留一次；行程在投遞途中結束時，租約到期後紀錄會再被取出重試
+func (s *WebhookService) claimDue() ([]models.WebhookDelivery, string, time.Duration, error) {
+	now := s.now()
+	token, err := newLeaseToken()
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	tx := db_conn.Conn_DB.MasterDB.Begin()
+	defer utils.RollbackIfPanic(tx)
+
+	deliveries, err := s.webhookRepo.FetchDueDeliveries(now, s.batchSize, tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, "", 0, err
+	}
+
+	ids := make([]uint, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.ID)
+	}
+	lease := time.Duration(len(deliveries)+1) * s.opts.Timeout
+	if err := s.webhookRepo.LeaseDeliveries(ids, now.Add(lease), token, tx); err != nil {
+		tx.Rollback()
+		return nil, "", 0, err
+	}
+
+	if commitDB := tx.Commit(); commitDB.Error != nil {
+		return nil, "", 0, commitDB.Error
+	}
+	return deliveries, token, lease, nil
+}
+
+// newLeaseToken 產生 128-bit 隨機租約 token
+func newLeaseToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// attempt 投遞一次並依結果更新紀錄：成功、排定下次重試，或超過次數後標記 failed
+func (s *WebhookService) attempt(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) {
+	now := s.now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+
+	status, err := s.send(ctx, endpoint, delivery, now)
+	delivery.ResponseStatus = status
+	if err == nil {
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = utils.TruncateString(err.Error(), maxWebhookErrorLength)
+	if delivery.Attempts >= s.opts.MaxAttempts {
+		delivery.Status = models.WebhookDeliveryFailed
+		return
+	}
+	delivery.NextAttemptAt = now.Add(exponentialBackoff(s.baseBackoff, s.maxBackoff, delivery.Attempts))
+}
+
+// send POST 投遞內容，2xx 視為成功，回傳 HTTP 狀態碼（沒有回應時為 0）
+func (s *WebhookService) send(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery, now time.Time) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "mini-crypto-wallet-webhooks/1")
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(endpoint.Secret, now, body))
+	req.Header.Set(webhook.HeaderEventID, delivery.EventID)
+	req.Header.Set(webhook.HeaderEventType, delivery.EventType)
+	req.Header.Set(webhook.HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// validateURL 只接受 https 的公開主機，AllowInsecureURLs 時也接受 http 與本機 / 內網位址
+// 這裡只擋字面上的 IP 與 localhost，網域解析到內網的情況由 client 在連線時以 webhook.DialControl 擋下
+func (s *WebhookService) validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	if s.opts.AllowInsecureURLs {
+		if u.Scheme != "https" && u.Scheme != "http" {
+			return ErrInvalidWebhookURL
+		}
+		return nil
+	}
+	if u.Scheme != "https" {
+		return ErrInvalidWebhookURL
+	}
+
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return ErrInvalidWebhookURL
+	}
+	if ip := net.ParseIP(host); ip != nil && webhook.ForbiddenIP(ip) {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+// joinWebhookEventTypes 去除重複後以逗號串接
+func joinWebhookEventTypes(eventTypes []string) string {
+	seen := make(map[string]bool, len(eventTypes))
+	unique := make([]string, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		if !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+	return strings.Join(unique, ",")
+}
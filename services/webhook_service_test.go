@@ -0,0 +1,467 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mini-crypto-wallet-api/internal/events"
+	"mini-crypto-wallet-api/internal/test"
+	"mini-crypto-wallet-api/internal/webhook"
+	"mini-crypto-wallet-api/kafka_client"
+	"mini-crypto-wallet-api/models"
+	"mini-crypto-wallet-api/repositories"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// webhookReceiver is an httptest server that records every callback and answers with a configurable status
+type webhookReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	status   int
+	requests []receivedWebhook
+}
+
+type receivedWebhook struct {
+	Header http.Header
+	Body   []byte
+}
+
+func newWebhookReceiver(t *testing.T) *webhookReceiver {
+	r := &webhookReceiver{status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, receivedWebhook{Header: req.Header.Clone(), Body: body})
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *webhookReceiver) respondWith(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func (r *webhookReceiver) received() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedWebhook(nil), r.requests...)
+}
+
+// newTestWebhookService returns a service that accepts httptest URLs and whose clock the test controls
+func newTestWebhookService(now *time.Time) *WebhookService {
+	s := NewWebhookService(repositories.NewWebhookRepository(), WebhookOptions{AllowInsecureURLs: true, MaxAttempts: 3})
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+// feedWebhooks publishes the outbox and hands every tx event to the webhook consumer handler
+func feedWebhooks(t *testing.T, s *WebhookService) {
+	t.Helper()
+	recorder := publishOutbox(t)
+	for _, topic := range []string{kafka_client.TopicTxCreated, kafka_client.TopicTxStatusChanged} {
+		for _, msg := range recorder.MessagesForTopic(topic) {
+			assert.NoError(t, s.HandleEvent(context.Background(), kafka.Message{Topic: topic, Key: []byte(msg.Key), Value: msg.Value}))
+		}
+	}
+}
+
+func createWebhook(t *testing.T, s *WebhookService, userID uint, url string, eventTypes ...string) *models.WebhookEndpoint {
+	t.Helper()
+	endpoint, err := s.CreateEndpoint(userID, &models.CreateWebhookRequest{URL: url, EventTypes: eventTypes})
+	assert.NoError(t, err)
+	return endpoint
+}
+
+// TestWebhook_DeliversSignedTransferEvents verifies both sides of a transfer are delivered to subscribed endpoints with a valid signature
+func TestWebhook_DeliversSignedTransferEvents(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	now := time.Now()
+	service := newTestWebhookService(&now)
+	receiver := newWebhookReceiver(t)
+	unsubscribed := newWebhookReceiver(t)
+
+	transaction := setupTransfer(t, db)
+	bobHook := createWebhook(t, service, transaction.ToUserID, receiver.URL+"/bob", models.WebhookEventTransferReceived)
+	aliceHook := createWebhook(t, service, transaction.FromUserID, receiver.URL+"/alice", models.WebhookEventTransferSent, models.WebhookEventDepositConfirmed)
+	createWebhook(t, service, transaction.FromUserID, unsubscribed.URL, models.WebhookEventTransferReceived)
+
+	feedWebhooks(t, service)
+	feedWebhooks(t, service) // redelivered Kafka messages create no extra deliveries
+
+	delivered, err := service.DeliverDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	assert.Empty(t, unsubscribed.received())
+
+	requests := receiver.received()
+	assert.Len(t, requests, 2)
+	secrets := map[string]string{models.WebhookEventTransferReceived: bobHook.Secret, models.WebhookEventTransferSent: aliceHook.Secret}
+	for _, req := range requests {
+		eventType := req.Header.Get(webhook.HeaderEventType)
+		assert.NoError(t, webhook.Verify(secrets[eventType], req.Header.Get(webhook.HeaderSignature), req.Body, now, webhook.DefaultTolerance))
+		assert.Error(t, webhook.Verify("whsec_wrong", req.Header.Get(webhook.HeaderSignature), req.Body, now, webhook.DefaultTolerance))
+
+		var event webhook.Event
+		assert.NoError(t, json.Unmarshal(req.Body, &event))
+		assert.Equal(t, eventType, event.Type)
+		assert.Equal(t, req.Header.Get(webhook.HeaderEventID), event.ID)
+
+		var data events.TransferPayload
+		assert.NoError(t, json.Unmarshal(event.Data, &data))
+		assert.Equal(t, transaction.Hash, data.Hash)
+		assert.Equal(t, "100", data.Amount.String())
+	}
+
+	deliveries, total, err := service.ListDeliveries(transaction.ToUserID, bobHook.ID, 0, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, models.WebhookDeliverySucceeded, deliveries[0].Status)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusOK, deliveries[0].ResponseStatus)
+	assert.NotNil(t, deliveries[0].DeliveredAt)
+}
+
+// TestWebhook_RetriesWithBackoffThenRedelivers verifies failed deliveries back off exponentially, give up
+// after the maximum attempts and can be redelivered by the user
+func TestWebhook_RetriesWithBackoffThenRedelivers(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	now := time.Now()
+	service := newTestWebhookService(&now)
+	receiver := newWebhookReceiver(t)
+	receiver.respondWith(http.StatusInternalServerError)
+
+	transaction := setupTransfer(t, db)
+	hook := createWebhook(t, service, transaction.ToUserID, receiver.URL, models.WebhookEventTransferReceived)
+	feedWebhooks(t, service)
+
+	deliver := func() *models.WebhookDelivery {
+		_, err := service.DeliverDue(context.Background())
+		assert.NoError(t, err)
+		deliveries, _, err := service.ListDeliveries(transaction.ToUserID, hook.ID, 0, 20)
+		assert.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+		return &deliveries[0]
+	}
+
+	delivery := deliver()
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, delivery.ResponseStatus)
+	assert.Contains(t, delivery.LastError, "HTTP 500")
+	assert.WithinDuration(t, now.Add(30*time.Second), delivery.NextAttemptAt, time.Second)
+
+	deliver()
+	assert.Len(t, receiver.received(), 1, "not retried before the backoff elapses")
+
+	now = now.Add(30 * time.Second)
+	delivery = deliver()
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.WithinDuration(t, now.Add(time.Minute), delivery.NextAttemptAt, time.Second, "backoff doubles")
+
+	now = now.Add(time.Minute)
+	delivery = deliver()
+	assert.Equal(t, models.WebhookDeliveryFailed, delivery.Status, "gives up after MaxAttempts")
+	assert.Equal(t, 3, delivery.Attempts)
+
+	now = now.Add(24 * time.Hour)
+	deliver()
+	assert.Len(t, receiver.received(), 3, "failed deliveries are not retried automatically")
+
+	receiver.respondWith(http.StatusNoContent)
+	redelivered, err := service.Redeliver(transaction.ToUserID, hook.ID, delivery.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, redelivered.Status)
+	assert.Equal(t, 0, redelivered.Attempts)
+	assert.WithinDuration(t, now, redelivered.NextAttemptAt, time.Second)
+	assert.Len(t, receiver.received(), 3, "redelivery only queues, the scheduler sends it")
+
+	delivery = deliver()
+	assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, delivery.ResponseStatus)
+	assert.Empty(t, delivery.LastError)
+
+	requests := receiver.received()
+	assert.Len(t, requests, 4)
+	assert.Equal(t, requests[0].Body, requests[3].Body, "a redelivery sends the same event")
+
+	_, err = service.Redeliver(transaction.FromUserID, hook.ID, delivery.ID)
+	assert.ErrorIs(t, err, ErrWebhookNotFound, "users cannot redeliver another user's webhooks")
+}
+
+// TestWebhook_ClaimedDeliveriesAreLeased verifies a claimed delivery is not picked up again until its lease
+// runs out, so a dispatcher that dies mid-send does not lose it
+func TestWebhook_ClaimedDeliveriesAreLeased(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	now := time.Now()
+	service := newTestWebhookService(&now)
+	receiver := newWebhookReceiver(t)
+
+	transaction := setupTransfer(t, db)
+	createWebhook(t, service, transaction.ToUserID, receiver.URL, models.WebhookEventTransferReceived)
+	feedWebhooks(t, service)
+
+	claimed, _, _, err := service.claimDue()
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+
+	delivered, err := service.DeliverDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered, "another dispatcher skips leased deliveries")
+	assert.Empty(t, receiver.received())
+
+	now = now.Add(2*defaultWebhookTimeout + time.Second)
+	delivered, err = service.DeliverDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered, "an expired lease is retried")
+	assert.Len(t, receiver.received(), 1)
+}
+
+// TestWebhook_LeaseCoversBatchAndGuardsResults verifies the lease lasts long enough to send the whole batch
+// and a dispatcher whose lease was taken over cannot overwrite the newer result
+func TestWebhook_LeaseCoversBatchAndGuardsResults(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	now := time.Now()
+	service := newTestWebhookService(&now)
+	receiver := newWebhookReceiver(t)
+
+	transaction := setupTransfer(t, db)
+	createWebhook(t, service, transaction.ToUserID, receiver.URL+"/a", models.WebhookEventTransferReceived)
+	createWebhook(t, service, transaction.ToUserID, receiver.URL+"/b", models.WebhookEventTransferReceived)
+	feedWebhooks(t, service)
+
+	claimed, staleToken, lease, err := service.claimDue()
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 2)
+	assert.Equal(t, 3*defaultWebhookTimeout, lease, "one timeout per delivery plus one to spare")
+
+	// 第一個 dispatcher 卡住，租約到期後由下一輪送出
+	now = now.Add(lease + time.Second)
+	delivered, err := service.DeliverDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+
+	stale := claimed[0]
+	stale.Attempts = 1
+	stale.Status = models.WebhookDeliveryPending
+	stale.LastError = "context deadline exceeded"
+	recorded, err := repositories.NewWebhookRepository().UpdateLeasedDelivery(&stale, staleToken)
+	assert.NoError(t, err)
+	assert.False(t, recorded, "an expired lease cannot record its result")
+
+	current, err := repositories.NewWebhookRepository().FindDeliveryByIDAndEndpointID(stale.ID, stale.EndpointID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliverySucceeded, current.Status)
+	assert.Empty(t, current.LastError)
+}
+
+// TestWebhook_RedeliverWaitsForLease verifies a delivery that is being sent cannot be redelivered until its lease ends
+func TestWebhook_RedeliverWaitsForLease(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	now := time.Now()
+	service := newTestWebhookService(&now)
+	receiver := newWebhookReceiver(t)
+
+	transaction := setupTransfer(t, db)
+	hook := createWebhook(t, service, transaction.ToUserID, receiver.URL, models.WebhookEventTransferReceived)
+	feedWebhooks(t, service)
+
+	claimed, _, lease, err := service.claimDue()
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+
+	_, err = service.Redeliver(transaction.ToUserID, hook.ID, claimed[0].ID)
+	assert.ErrorIs(t, err, ErrWebhookDeliveryInProgress)
+
+	_, err = service.Redeliver(transaction.ToUserID, hook.ID, claimed[0].ID+100)
+	assert.ErrorIs(t, err, ErrWebhookDeliveryNotFound)
+
+	now = now.Add(lease + time.Second)
+	redelivered, err := service.Redeliver(transaction.ToUserID, hook.ID, claimed[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, redelivered.Status)
+	assert.Empty(t, redelivered.LeaseToken)
+}
+
+// TestWebhook_TruncatesLongErrors verifies the recorded error fits the last_error column
+func TestWebhook_TruncatesLongErrors(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	now := time.Now()
+	service := newTestWebhookService(&now)
+	receiver := newWebhookReceiver(t)
+	receiver.Close()
+
+	transaction := setupTransfer(t, db)
+	hook := createWebhook(t, service, transaction.ToUserID, receiver.URL+"/"+strings.Repeat("a", 450), models.WebhookEventTransferReceived)
+	feedWebhooks(t, service)
+
+	_, err := service.DeliverDue(context.Background())
+	assert.NoError(t, err)
+
+	deliveries, _, err := service.ListDeliveries(transaction.ToUserID, hook.ID, 0, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, deliveries[0].Status)
+	assert.Len(t, deliveries[0].LastError, maxWebhookErrorLength)
+}
+
+// TestWebhook_DepositConfirmed verifies only the completed status of a deposit is delivered
+func TestWebhook_DepositConfirmed(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	now := time.Now()
+	service := newTestWebhookService(&now)
+	receiver := newWebhookReceiver(t)
+
+	currency := test.CreateTestCurrency(db, "USDT")
+	alice := test.CreateTestUser(db, "alice")
+	test.CreateTestWallet(db, alice.ID, currency.ID, 0)
+	createWebhook(t, service, alice.ID, receiver.URL, models.WebhookEventDepositConfirmed)
+
+	funding := NewFundingService(repositories.NewWalletRepository(), repositories.NewTransactionRepository())
+	deposit, err := funding.CreateDeposit(alice.ID, currency.ID, dec("250"), "bank-ref-001")
+	assert.NoError(t, err)
+	_, err = funding.MarkProcessing(deposit.Hash)
+	assert.NoError(t, err)
+	_, err = funding.Complete(deposit.Hash)
+	assert.NoError(t, err)
+
+	feedWebhooks(t, service)
+	delivered, err := service.DeliverDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	requests := receiver.received()
+	assert.Len(t, requests, 1)
+	var event webhook.Event
+	assert.NoError(t, json.Unmarshal(requests[0].Body, &event))
+	assert.Equal(t, models.WebhookEventDepositConfirmed, event.Type)
+	var data events.FundingPayload
+	assert.NoError(t, json.Unmarshal(event.Data, &data))
+	assert.Equal(t, deposit.Hash, data.Hash)
+	assert.Equal(t, models.TxStatusCompleted, data.Status)
+}
+
+// TestWebhook_PausedEndpointStopsDeliveries verifies queued deliveries to a paused endpoint are failed instead of sent
+func TestWebhook_PausedEndpointStopsDeliveries(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	now := time.Now()
+	service := newTestWebhookService(&now)
+	receiver := newWebhookReceiver(t)
+
+	transaction := setupTransfer(t, db)
+	hook := createWebhook(t, service, transaction.ToUserID, receiver.URL, models.WebhookEventTransferReceived)
+	feedWebhooks(t, service)
+
+	active := false
+	_, err := service.UpdateEndpoint(transaction.ToUserID, hook.ID, &models.UpdateWebhookRequest{Active: &active})
+	assert.NoError(t, err)
+
+	delivered, err := service.DeliverDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Empty(t, receiver.received())
+
+	deliveries, _, err := service.ListDeliveries(transaction.ToUserID, hook.ID, 0, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryFailed, deliveries[0].Status)
+	assert.Equal(t, "endpoint is inactive", deliveries[0].LastError)
+}
+
+// TestWebhook_RejectsPrivateAddressesAtDialTime verifies a hostname that resolves to loopback is never connected to,
+// even when the endpoint got past registration
+func TestWebhook_RejectsPrivateAddressesAtDialTime(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	now := time.Now()
+	service := NewWebhookService(repositories.NewWebhookRepository(), WebhookOptions{MaxAttempts: 3})
+	service.now = func() time.Time { return now }
+	receiver := newWebhookReceiver(t)
+
+	transaction := setupTransfer(t, db)
+	hook := &models.WebhookEndpoint{
+		UserID:     transaction.ToUserID,
+		URL:        strings.Replace(receiver.URL, "127.0.0.1", "localhost", 1),
+		Secret:     "whsec_test",
+		EventTypes: models.WebhookEventTransferReceived,
+		Active:     true,
+	}
+	assert.NoError(t, repositories.NewWebhookRepository().CreateEndpoint(hook))
+	feedWebhooks(t, service)
+
+	delivered, err := service.DeliverDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Empty(t, receiver.received())
+
+	deliveries, _, err := service.ListDeliveries(transaction.ToUserID, hook.ID, 0, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, deliveries[0].Status)
+	assert.Equal(t, 0, deliveries[0].ResponseStatus)
+	assert.Contains(t, deliveries[0].LastError, webhook.ErrForbiddenAddress.Error())
+}
+
+// TestWebhook_EndpointValidation verifies URL checks, the per-user limit and ownership
+func TestWebhook_EndpointValidation(t *testing.T) {
+	db := test.SetupTestDB()
+	defer test.CleanupTestDB(db)
+
+	alice := test.CreateTestUser(db, "alice")
+	bob := test.CreateTestUser(db, "bob")
+	service := NewWebhookService(repositories.NewWebhookRepository(), WebhookOptions{})
+
+	for _, url := range []string{"http://merchant.example.com/hook", "https://localhost/hook", "https://127.0.0.1/hook", "https://10.0.0.8/hook", "https://169.254.169.254/latest", "ftp://merchant.example.com"} {
+		_, err := service.CreateEndpoint(alice.ID, &models.CreateWebhookRequest{URL: url, EventTypes: []string{models.WebhookEventTransferSent}})
+		assert.ErrorIs(t, err, ErrInvalidWebhookURL, url)
+	}
+
+	endpoint, err := service.CreateEndpoint(alice.ID, &models.CreateWebhookRequest{
+		URL:        "https://merchant.example.com/hook",
+		EventTypes: []string{models.WebhookEventTransferSent, models.WebhookEventTransferSent, models.WebhookEventDepositConfirmed},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{models.WebhookEventTransferSent, models.WebhookEventDepositConfirmed}, endpoint.Events())
+	assert.Contains(t, endpoint.Secret, webhook.SecretPrefix)
+
+	_, err = service.GetEndpoint(bob.ID, endpoint.ID)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+	assert.ErrorIs(t, service.DeleteEndpoint(bob.ID, endpoint.ID), ErrWebhookNotFound)
+
+	for i := 1; i < MaxWebhookEndpointsPerUser; i++ {
+		_, err := service.CreateEndpoint(alice.ID, &models.CreateWebhookRequest{URL: "https://merchant.example.com/hook", EventTypes: []string{models.WebhookEventTransferSent}})
+		assert.NoError(t, err)
+	}
+	_, err = service.CreateEndpoint(alice.ID, &models.CreateWebhookRequest{URL: "https://merchant.example.com/hook", EventTypes: []string{models.WebhookEventTransferSent}})
+	assert.ErrorIs(t, err, ErrWebhookLimitReached)
+
+	assert.NoError(t, service.DeleteEndpoint(alice.ID, endpoint.ID))
+	_, err = service.GetEndpoint(alice.ID, endpoint.ID)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+}
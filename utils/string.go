@@ -0,0 +1,14 @@
+package utils
+
+import "unicode/utf8"
+
+// TruncateString 截斷字串到最多 maxBytes 個位元組，不會切斷多位元組的 UTF-8 字元
+func TruncateString(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	for maxBytes > 0 && !utf8.RuneStart(s[maxBytes]) {
+		maxBytes--
+	}
+	return s[:maxBytes]
+}